@@ -29,7 +29,7 @@ func main() {
 	// 如果提供了URL，先使用MikanParser获取原始标题
 	if url != "" {
 		fmt.Printf("开始解析URL: %s\n", url)
-		_, _, _, _, _, _, _, _, _, originalTitle, err := parser.GetMikanBasicInfo(url)
+		_, _, originalTitle, _, _, _, _, _, _, _, err := parser.GetMikanBasicInfo(url)
 		if err != nil {
 			fmt.Printf("解析URL失败: %v\n", err)
 			os.Exit(1)
@@ -46,7 +46,7 @@ func main() {
 
 	// 使用RawParser解析原始标题
 	fmt.Printf("开始解析标题: %s\n", rawTitle)
-	episode := parser.RawParser(rawTitle)
+	episode := parser.RawParser(rawTitle, "")
 
 	// 输出解析结果
 	if episode == nil {
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"backend/config"
+	"backend/services/rss"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// 定义命令行参数
+	var opts rss.ReparseOptions
+	var jsonOutput bool
+	flag.UintVar(&opts.FeedID, "feed", 0, "只处理指定RSS源ID的条目，默认全部")
+	flag.UintVar(&opts.BangumiID, "bangumi", 0, "只处理指定番剧ID的条目，默认全部")
+	flag.BoolVar(&opts.Apply, "apply", false, "写入数据库，默认只输出差异摘要")
+	flag.IntVar(&opts.BatchSize, "batch", 200, "每批处理的条目数")
+	flag.BoolVar(&jsonOutput, "json", false, "以JSON格式输出差异摘要")
+
+	// 解析命令行参数
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Println("未找到.env文件，使用环境变量中的数据库配置")
+	}
+
+	db, err := config.InitDB()
+	if err != nil {
+		fmt.Printf("连接数据库失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	summary, err := rss.ReparseItems(context.Background(), db, opts, func(progress rss.ReparseSummary) {
+		fmt.Fprintf(os.Stderr, "已处理 %d/%d，变化 %d\n", progress.Processed, progress.Total, progress.Changed)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "重新解析失败: %v\n", err)
+		if summary == nil {
+			os.Exit(1)
+		}
+	}
+
+	if jsonOutput {
+		jsonData, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			fmt.Printf("转换为JSON失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonData))
+	} else {
+		for _, change := range summary.Samples {
+			fmt.Printf("[%d] %s\n", change.ItemID, change.RawTitle)
+			for _, field := range change.Fields {
+				fmt.Printf("    %s: %v -> %v\n", field.Field, field.Old, field.New)
+			}
+			if change.NewBangumiID != 0 {
+				fmt.Printf("    番剧: %d -> %d (%s)\n", change.OldBangumiID, change.NewBangumiID, change.NewTitle)
+			}
+		}
+		fmt.Printf("共 %d 个条目，处理 %d 个\n", summary.Total, summary.Processed)
+		fmt.Printf("变化: %d (集数 %d，番剧 %d)，未变化: %d\n", summary.Changed, summary.EpisodeChanges, summary.BangumiReassignments, summary.Unchanged)
+		fmt.Printf("缺少原始标题: %d，解析失败: %d，人工指定: %d\n", summary.MissingRawTitle, summary.ParseFailed, summary.Overridden)
+		if summary.Applied {
+			fmt.Println("已写入数据库")
+		} else {
+			fmt.Println("未写入数据库，使用 -apply 写入")
+		}
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -28,7 +28,17 @@ func InitDB() (*gorm.DB, error) {
 		&models.User{},
 		&models.RSSFeed{},
 		&models.RSSItem{},
+		&models.RSSFeedPageCache{},
+		&models.FeedUpdateRun{},
+		&models.SchedulerLock{},
+		&models.FilterRule{},
+		&models.ReviewItem{},
+		&models.TitleOverride{},
 		&models.Bangumi{},
+		&models.BangumiAlias{},
+		&models.BangumiMetadata{},
+		&models.BangumiMetadataTag{},
+		&models.BangumiMetadataStaff{},
 		&models.Activity{},
 		&models.BangumiFavorite{},
 		&models.BangumiRating{},
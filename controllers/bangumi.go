@@ -3,11 +3,13 @@ package controllers
 import (
 	"backend/models"
 	"backend/utils"
+	"backend/utils/parser"
 	"database/sql"
 	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -41,6 +43,18 @@ type RSSItemSearchParams struct {
 	Episode  *float64 `form:"episode"`   // 特定集数
 	Page     int      `form:"page"`      // 页码
 	PageSize int      `form:"page_size"` // 每页数量
+
+	Language   string `form:"language"`    // 字幕语言筛选，条目字幕包含该语言即可
+	SubForm    string `form:"sub_form"`    // 字幕形式筛选
+	VideoCodec string `form:"video_codec"` // 视频编码筛选
+	BitDepth   int    `form:"bit_depth"`   // 色深筛选
+	AudioCodec string `form:"audio_codec"` // 音频编码筛选
+	Container  string `form:"container"`   // 封装格式筛选
+	MinHeight  int    `form:"min_height"`  // 最小视频高度
+	MaxHeight  int    `form:"max_height"`  // 最大视频高度
+
+	ReleaseKind       string `form:"release_kind"`       // 发布类型筛选（episode/batch/special/movie）
+	IncludeSuperseded bool   `form:"include_superseded"` // 是否包含已被新版本取代的条目
 }
 
 // GroupedRSSItems 字幕组分类的RSS条目
@@ -59,15 +73,27 @@ type RSSDetail struct {
 
 // EpisodeInfo represents the innermost details of an episode.
 type EpisodeInfo struct {
-	Episode     *float64 `json:"episode"`
-	URL         string   `json:"url"`
-	ReleaseDate string   `json:"release_date"`
+	Episode        *float64 `json:"episode"`
+	EpisodeEnd     *float64 `json:"episode_end"`
+	ReleaseKind    string   `json:"release_kind"`
+	Version        int      `json:"version"`
+	SupersededByID *uint    `json:"superseded_by_id,omitempty"`
+	URL            string   `json:"url"`
+	ReleaseDate    string   `json:"release_date"`
+
+	// 种子元数据
+	InfoHash string              `json:"info_hash,omitempty"`
+	Size     int64               `json:"size,omitempty"`
+	Files    models.TorrentFiles `json:"files,omitempty"`
+	Magnet   string              `json:"magnet,omitempty"`
 }
 
 // SubGroupedEpisodes represents episodes grouped by subtitle type.
+// Batch releases are listed separately from single episodes, specials and movies.
 type SubGroupedEpisodes struct {
 	SubType  string        `json:"sub_type"`
 	Episodes []EpisodeInfo `json:"episodes"`
+	Batches  []EpisodeInfo `json:"batches,omitempty"`
 }
 
 // ResolutionGroupedSubs represents subtitle groups classified by resolution.
@@ -303,6 +329,16 @@ func GetBangumiByID(c *gin.Context) {
 // @Param min_ep query number false "最小集数"
 // @Param max_ep query number false "最大集数"
 // @Param episode query number false "特定集数"
+// @Param language query string false "字幕语言（zh-Hans/zh-Hant/zh/ja/en）"
+// @Param sub_form query string false "字幕形式（hardsub/softsub）"
+// @Param video_codec query string false "视频编码（hevc/avc/av1）"
+// @Param bit_depth query int false "色深"
+// @Param audio_codec query string false "音频编码（aac/flac等）"
+// @Param container query string false "封装格式（mkv/mp4）"
+// @Param min_height query int false "最小视频高度"
+// @Param max_height query int false "最大视频高度"
+// @Param release_kind query string false "发布类型（episode/batch/special/movie）"
+// @Param include_superseded query bool false "是否包含已被新版本（如v2）取代的条目，默认不包含"
 // @Param page query int false "页码"
 // @Param page_size query int false "每页数量"
 // @Success 200 {object} BangumiResponse
@@ -362,6 +398,39 @@ func GetBangumiRSSItems(c *gin.Context) {
 	if params.Source != "" {
 		query = query.Where("source = ?", params.Source)
 	}
+	if params.Language != "" {
+		// sub_lang为"+"连接的语言组合，按完整的语言代码匹配，避免"zh"命中"zh-Hans"
+		lang := params.Language
+		query = query.Where("sub_lang = ? OR sub_lang LIKE ? OR sub_lang LIKE ? OR sub_lang LIKE ?",
+			lang, lang+"+%", "%+"+lang, "%+"+lang+"+%")
+	}
+	if params.SubForm != "" {
+		query = query.Where("sub_form = ?", params.SubForm)
+	}
+	if params.VideoCodec != "" {
+		query = query.Where("video_codec = ?", strings.ToLower(params.VideoCodec))
+	}
+	if params.BitDepth > 0 {
+		query = query.Where("bit_depth = ?", params.BitDepth)
+	}
+	if params.AudioCodec != "" {
+		query = query.Where("audio_codec = ?", strings.ToLower(params.AudioCodec))
+	}
+	if params.Container != "" {
+		query = query.Where("container = ?", strings.ToLower(params.Container))
+	}
+	if params.MinHeight > 0 {
+		query = query.Where("height >= ?", params.MinHeight)
+	}
+	if params.MaxHeight > 0 {
+		query = query.Where("height <= ?", params.MaxHeight)
+	}
+	if params.ReleaseKind != "" {
+		query = query.Where("release_kind = ?", params.ReleaseKind)
+	}
+	if !params.IncludeSuperseded {
+		query = query.Where("superseded_by_id IS NULL")
+	}
 
 	// 优化集数筛选逻辑
 	if params.Episode != nil && *params.Episode > 0 {
@@ -418,10 +487,11 @@ func GetBangumiRSSItems(c *gin.Context) {
 }
 
 // @Summary 获取按字幕组、分辨率、字幕类型分类的番剧RSS条目
-// @Description 获取指定番剧ID的所有RSS条目，并按字幕组、分辨率、字幕类型分类。
+// @Description 获取指定番剧ID的所有RSS条目，并按字幕组、分辨率、字幕类型分类，合集条目在batches中单独列出。
 // @Tags 番剧管理
 // @Produce json
 // @Param id path int true "番剧ID"
+// @Param include_superseded query bool false "是否包含已被新版本（如v2）取代的条目，默认不包含"
 // @Success 200 {object} BangumiResponse{data=[]GroupedByResolutionAndSub} "成功获取分组RSS条目"
 // @Failure 404 {object} BangumiResponse "番剧未找到"
 // @Failure 500 {object} BangumiResponse "服务器内部错误"
@@ -447,9 +517,13 @@ func GetGroupedBangumiRSSItems(c *gin.Context) {
 		return
 	}
 
-	// 获取所有相关RSS条目
+	// 获取所有相关RSS条目，默认隐藏已被新版本取代的条目
+	query := models.DB.Where("bangumi_id = ?", id)
+	if includeSuperseded, _ := strconv.ParseBool(c.Query("include_superseded")); !includeSuperseded {
+		query = query.Where("superseded_by_id IS NULL")
+	}
 	var rssItems []models.RSSItem
-	if err := models.DB.Where("bangumi_id = ?", id).
+	if err := query.
 		Order("`group` ASC, resolution ASC, sub ASC, episode ASC").
 		Find(&rssItems).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, BangumiResponse{
@@ -460,8 +534,9 @@ func GetGroupedBangumiRSSItems(c *gin.Context) {
 		return
 	}
 
-	// 按字幕组、分辨率、字幕类型分类
+	// 按字幕组、分辨率、字幕类型分类，合集单独列出
 	groupedData := make(map[string]map[string]map[string][]EpisodeInfo)
+	groupedBatches := make(map[string]map[string]map[string][]EpisodeInfo)
 
 	for _, item := range rssItems {
 		group := item.Group
@@ -479,15 +554,32 @@ func GetGroupedBangumiRSSItems(c *gin.Context) {
 
 		if _, ok := groupedData[group]; !ok {
 			groupedData[group] = make(map[string]map[string][]EpisodeInfo)
+			groupedBatches[group] = make(map[string]map[string][]EpisodeInfo)
 		}
 		if _, ok := groupedData[group][resolution]; !ok {
 			groupedData[group][resolution] = make(map[string][]EpisodeInfo)
+			groupedBatches[group][resolution] = make(map[string][]EpisodeInfo)
 		}
 
 		episodeDetail := EpisodeInfo{
-			Episode:     item.Episode,
-			URL:         item.URL,
-			ReleaseDate: item.ReleaseDate,
+			Episode:        item.Episode,
+			EpisodeEnd:     item.EpisodeEnd,
+			ReleaseKind:    item.ReleaseKind,
+			Version:        item.Version,
+			SupersededByID: item.SupersededByID,
+			URL:            item.URL,
+			ReleaseDate:    item.ReleaseDate,
+			InfoHash:       item.InfoHash,
+			Size:           item.Size,
+			Files:          item.Files,
+			Magnet:         item.Magnet,
+		}
+		if item.ReleaseKind == parser.ReleaseKindBatch {
+			groupedBatches[group][resolution][sub] = append(groupedBatches[group][resolution][sub], episodeDetail)
+			if _, ok := groupedData[group][resolution][sub]; !ok {
+				groupedData[group][resolution][sub] = []EpisodeInfo{}
+			}
+			continue
 		}
 		groupedData[group][resolution][sub] = append(groupedData[group][resolution][sub], episodeDetail)
 	}
@@ -522,6 +614,7 @@ func GetGroupedBangumiRSSItems(c *gin.Context) {
 				subGroupedEpisodesList = append(subGroupedEpisodesList, SubGroupedEpisodes{
 					SubType:  subType,
 					Episodes: episodes,
+					Batches:  groupedBatches[groupName][resolutionName][subType],
 				})
 			}
 			resolutionGroupedSubsList = append(resolutionGroupedSubsList, ResolutionGroupedSubs{
@@ -625,7 +718,9 @@ func GetGroupEpisodeInfo(c *gin.Context) {
 
 	// 查询特定条目
 	var rssItem models.RSSItem
+	// 同一集有多个版本时优先返回未被取代的最新版本
 	err = models.DB.Where("bangumi_id = ? AND `group` = ? AND episode = ?", id, group, episode).
+		Order("superseded_by_id IS NOT NULL, version DESC, id DESC").
 		First(&rssItem).Error
 
 	if err != nil {
@@ -654,6 +749,10 @@ func GetGroupEpisodeInfo(c *gin.Context) {
 			"url":          rssItem.URL,
 			"resolution":   rssItem.Resolution,
 			"release_date": rssItem.ReleaseDate,
+			"info_hash":    rssItem.InfoHash,
+			"size":         rssItem.Size,
+			"files":        rssItem.Files,
+			"magnet":       rssItem.Magnet,
 		},
 	})
 }
@@ -1927,6 +2026,30 @@ func DeleteBangumi(c *gin.Context) {
 		return
 	}
 
+	// 删除相关的元数据
+	if err := models.DeleteBangumiMetadata(tx, uint(bangumiID)); err != nil {
+		tx.Rollback()
+		utils.LogError(fmt.Sprintf("删除番剧[%d]元数据失败", bangumiID), err)
+		c.JSON(http.StatusInternalServerError, BangumiResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "删除番剧元数据失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// 删除相关的别名
+	if err := tx.Where("bangumi_id = ?", uint(bangumiID)).Delete(&models.BangumiAlias{}).Error; err != nil {
+		tx.Rollback()
+		utils.LogError(fmt.Sprintf("删除番剧[%d]别名失败", bangumiID), err)
+		c.JSON(http.StatusInternalServerError, BangumiResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "删除番剧别名失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
 	// 硬删除番剧
 	if err := tx.Unscoped().Delete(&bangumi).Error; err != nil {
 		tx.Rollback()
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"backend/models"
+	"backend/services/bangumi"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// validAliasLanguages 可选的别名语言
+var validAliasLanguages = map[string]bool{
+	models.AliasLanguageZh: true, models.AliasLanguageJa: true, models.AliasLanguageEn: true,
+	models.AliasLanguageRomaji: true, models.AliasLanguageOther: true,
+}
+
+// @Summary 获取番剧别名
+// @Description 获取指定番剧的所有别名，入库时按归一化后的别名匹配已有番剧
+// @Tags 番剧管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "番剧ID"
+// @Success 200 {object} RSSResponse{data=[]models.BangumiAlias}
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/bangumi/{id}/aliases [get]
+func GetBangumiAliases(c *gin.Context) {
+	id := c.Param("id")
+	var target models.Bangumi
+	if err := models.DB.Select("id").First(&target, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的番剧不存在", id)})
+		} else {
+			utils.LogError(fmt.Sprintf("获取ID为%s的番剧失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取番剧别名失败", "error": err.Error()})
+		}
+		return
+	}
+
+	aliases := make([]models.BangumiAlias, 0)
+	if err := models.DB.Where("bangumi_id = ?", target.ID).Order("id").Find(&aliases).Error; err != nil {
+		utils.LogError(fmt.Sprintf("获取番剧[ID:%s]别名失败", id), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取番剧别名失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取番剧别名成功", "data": aliases})
+}
+
+// @Summary 添加番剧别名
+// @Description 为番剧添加别名（中文、日文、英文、罗马音或其他写法），之后标题中出现该名称的条目会归入该番剧
+// @Tags 番剧管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "番剧ID"
+// @Param alias body models.BangumiAliasRequest true "别名"
+// @Success 201 {object} RSSResponse{data=models.BangumiAlias}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 409 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/bangumi/{id}/aliases [post]
+func CreateBangumiAlias(c *gin.Context) {
+	id := c.Param("id")
+	var target models.Bangumi
+	if err := models.DB.Select("id").First(&target, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的番剧不存在", id)})
+		} else {
+			utils.LogError(fmt.Sprintf("获取ID为%s的番剧失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "添加番剧别名失败", "error": err.Error()})
+		}
+		return
+	}
+
+	var req models.BangumiAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+	if req.Language == "" {
+		req.Language = models.AliasLanguageOther
+	}
+	if !validAliasLanguages[req.Language] {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": fmt.Sprintf("不支持的别名语言: %s", req.Language)})
+		return
+	}
+
+	alias := models.BangumiAlias{
+		BangumiID:  target.ID,
+		Alias:      strings.TrimSpace(req.Alias),
+		Normalized: bangumi.NormalizeTitle(req.Alias),
+		Language:   req.Language,
+		Source:     models.AliasSourceManual,
+	}
+	if alias.Normalized == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "别名不能只包含空白和标点"})
+		return
+	}
+
+	var count int64
+	if err := models.DB.Model(&models.BangumiAlias{}).Where("bangumi_id = ? AND normalized = ?", alias.BangumiID, alias.Normalized).Count(&count).Error; err != nil {
+		utils.LogError("检查番剧别名失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "添加番剧别名失败", "error": err.Error()})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"code": http.StatusConflict, "message": "该番剧已有相同的别名"})
+		return
+	}
+
+	if err := models.DB.Create(&alias).Error; err != nil {
+		utils.LogError("添加番剧别名失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "添加番剧别名失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"code": http.StatusCreated, "message": "添加番剧别名成功", "data": alias})
+}
+
+// @Summary 删除番剧别名
+// @Description 删除指定ID的番剧别名
+// @Tags 番剧管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "别名ID"
+// @Success 200 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/bangumi_aliases/{id} [delete]
+func DeleteBangumiAlias(c *gin.Context) {
+	id := c.Param("id")
+	result := models.DB.Delete(&models.BangumiAlias{}, id)
+	if result.Error != nil {
+		utils.LogError(fmt.Sprintf("删除ID为%s的番剧别名失败", id), result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "删除番剧别名失败", "error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的番剧别名不存在", id)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "删除番剧别名成功"})
+}
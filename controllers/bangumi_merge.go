@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"backend/models"
+	"backend/services/bangumi"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bangumiOperationStatus 合并、拆分番剧的错误对应的状态码
+func bangumiOperationStatus(err error) int {
+	switch {
+	case errors.Is(err, bangumi.ErrBangumiNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, bangumi.ErrSameBangumi), errors.Is(err, bangumi.ErrItemsNotInSource):
+		return http.StatusBadRequest
+	case errors.Is(err, bangumi.ErrBangumiExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// @Summary 合并番剧
+// @Description 将source_id番剧合并到路径中的番剧：RSS条目、收藏、评分、播放记录和别名移到目标番剧，重新计算点击量、收藏量和评分后删除被合并的番剧
+// @Tags 番剧管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "目标番剧ID"
+// @Param merge body models.BangumiMergeRequest true "被合并的番剧"
+// @Success 200 {object} RSSResponse{data=bangumi.MergeResult}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/bangumi/{id}/merge [post]
+func MergeBangumi(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "无效的番剧ID", "error": err.Error()})
+		return
+	}
+
+	var req models.BangumiMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+
+	result, err := bangumi.Merge(models.DB, uint(targetID), req.SourceID)
+	if err != nil {
+		status := bangumiOperationStatus(err)
+		if status == http.StatusInternalServerError {
+			utils.LogError(fmt.Sprintf("合并番剧[%d]到[%d]失败", req.SourceID, targetID), err)
+		}
+		c.JSON(status, gin.H{"code": status, "message": "合并番剧失败", "error": err.Error()})
+		return
+	}
+
+	utils.LogInfo(fmt.Sprintf("番剧[%d]已合并到[%d]，移动%d个RSS条目，删除%d个重复条目", req.SourceID, targetID, result.MovedItems, result.DuplicateItems))
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "合并番剧成功", "data": result})
+}
+
+// @Summary 拆分番剧
+// @Description 将番剧中选中的RSS条目移到新建的番剧，收藏和评分保留在原番剧
+// @Tags 番剧管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "原番剧ID"
+// @Param split body models.BangumiSplitRequest true "要移动的RSS条目和新番剧信息"
+// @Success 201 {object} RSSResponse{data=models.Bangumi}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 409 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/bangumi/{id}/split [post]
+func SplitBangumi(c *gin.Context) {
+	sourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "无效的番剧ID", "error": err.Error()})
+		return
+	}
+
+	var req models.BangumiSplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+
+	created, err := bangumi.Split(models.DB, uint(sourceID), req)
+	if err != nil {
+		status := bangumiOperationStatus(err)
+		if status == http.StatusInternalServerError {
+			utils.LogError(fmt.Sprintf("拆分番剧[%d]失败", sourceID), err)
+		}
+		c.JSON(status, gin.H{"code": status, "message": "拆分番剧失败", "error": err.Error()})
+		return
+	}
+
+	utils.LogInfo(fmt.Sprintf("从番剧[%d]拆分出番剧[%d]，移动%d个RSS条目", sourceID, created.ID, len(req.RSSItemIDs)))
+	c.JSON(http.StatusCreated, gin.H{"code": http.StatusCreated, "message": "拆分番剧成功", "data": created})
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/models"
+	"backend/services/bangumi"
+	"backend/services/metadata"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// metadataOperationStatus 关联、刷新元数据的错误对应的状态码，来源请求失败时返回502
+func metadataOperationStatus(err error) int {
+	switch {
+	case errors.Is(err, bangumi.ErrBangumiNotFound), errors.Is(err, metadata.ErrSubjectNotFound), errors.Is(err, metadata.ErrNoMatch):
+		return http.StatusNotFound
+	case errors.Is(err, metadata.ErrProviderDisabled):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, metadata.ErrProviderRequest):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+// @Summary 获取番剧元数据
+// @Description 获取番剧在元数据来源（bgm.tv）中的条目信息：原名、简介、首播日期、放送星期、集数、评分、标签和制作人员
+// @Tags 番剧管理
+// @Produce json
+// @Param id path int true "番剧ID"
+// @Success 200 {object} RSSResponse{data=models.BangumiMetadata}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /bangumi/{id}/metadata [get]
+func GetBangumiMetadata(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "无效的番剧ID", "error": err.Error()})
+		return
+	}
+
+	result, err := metadata.Get(models.DB, uint(id))
+	if err != nil {
+		utils.LogError(fmt.Sprintf("获取番剧[%d]元数据失败", id), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取番剧元数据失败", "error": err.Error()})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%d的番剧尚未关联元数据", id)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取番剧元数据成功", "data": result})
+}
+
+// @Summary 关联番剧元数据条目
+// @Description 将番剧关联到元数据来源中指定ID的条目并立即同步，用于自动匹配失败或匹配错误的番剧；手动关联的番剧刷新时不再重新匹配
+// @Tags 番剧管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "番剧ID"
+// @Param link body models.BangumiMetadataLinkRequest true "条目ID"
+// @Success 200 {object} RSSResponse{data=models.BangumiMetadata}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 502 {object} RSSResponse
+// @Failure 503 {object} RSSResponse
+// @Router /admin/bangumi/{id}/metadata/link [post]
+func LinkBangumiMetadata(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "无效的番剧ID", "error": err.Error()})
+		return
+	}
+
+	var req models.BangumiMetadataLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+
+	result, err := metadata.Link(c.Request.Context(), models.DB, metadata.Default(), uint(id), strings.TrimSpace(req.SubjectID), models.MetadataMatchManual)
+	if err != nil {
+		status := metadataOperationStatus(err)
+		if status >= http.StatusInternalServerError {
+			utils.LogError(fmt.Sprintf("番剧[%d]关联元数据条目[%s]失败", id, req.SubjectID), err)
+		}
+		c.JSON(status, gin.H{"code": status, "message": "关联番剧元数据失败", "error": err.Error()})
+		return
+	}
+
+	utils.LogInfo(fmt.Sprintf("番剧[%d]已关联%s条目[%s]", id, result.Provider, result.SubjectID))
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "关联番剧元数据成功", "data": result})
+}
+
+// @Summary 刷新番剧元数据
+// @Description 立即从元数据来源同步番剧信息，尚未关联条目的番剧按番剧名、别名和年份自动匹配
+// @Tags 番剧管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "番剧ID"
+// @Success 200 {object} RSSResponse{data=models.BangumiMetadata}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 502 {object} RSSResponse
+// @Failure 503 {object} RSSResponse
+// @Router /admin/bangumi/{id}/metadata/refresh [post]
+func RefreshBangumiMetadata(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "无效的番剧ID", "error": err.Error()})
+		return
+	}
+
+	result, err := metadata.Refresh(c.Request.Context(), models.DB, metadata.Default(), uint(id))
+	if err != nil {
+		status := metadataOperationStatus(err)
+		if status >= http.StatusInternalServerError {
+			utils.LogError(fmt.Sprintf("刷新番剧[%d]元数据失败", id), err)
+		}
+		message := "刷新番剧元数据失败"
+		if errors.Is(err, metadata.ErrNoMatch) {
+			message = "未找到与番剧匹配的条目，可手动关联条目ID"
+		}
+		c.JSON(status, gin.H{"code": status, "message": message, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "刷新番剧元数据成功", "data": result})
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @Summary 获取RSS更新运行记录
+// @Description 分页获取RSS源更新的运行记录，按开始时间倒序，可按RSS源、触发方式和状态筛选
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Param feed_id query int false "RSS源ID"
+// @Param trigger query string false "触发方式(scheduler/manual/single)"
+// @Param status query string false "运行状态(running/success/failed/cancelled)"
+// @Param page query int false "页码，默认1"
+// @Param page_size query int false "每页数量，默认10"
+// @Success 200 {object} RSSResponse{data=[]models.FeedUpdateRun}
+// @Failure 400 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/rss_update_runs [get]
+func GetFeedUpdateRuns(c *gin.Context) {
+	page := utils.GetPage(c)
+	pageSize := utils.GetPageSize(c)
+
+	query := models.DB.Model(&models.FeedUpdateRun{})
+	if feedID := c.Query("feed_id"); feedID != "" {
+		id, err := strconv.ParseUint(feedID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "feed_id参数无效"})
+			return
+		}
+		query = query.Where("rss_id = ?", id)
+	}
+	if trigger := c.Query("trigger"); trigger != "" {
+		query = query.Where("`trigger` = ?", trigger)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.LogError("获取RSS更新运行记录数量失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取RSS更新运行记录失败", "error": err.Error()})
+		return
+	}
+
+	runs := make([]models.FeedUpdateRun, 0)
+	if err := query.Order("started_at DESC, id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&runs).Error; err != nil {
+		utils.LogError("获取RSS更新运行记录失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取RSS更新运行记录失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取RSS更新运行记录成功",
+		"data": gin.H{
+			"total":       total,
+			"page":        page,
+			"page_size":   pageSize,
+			"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+			"list":        runs,
+		},
+	})
+}
+
+// @Summary 获取单条RSS更新运行记录
+// @Description 根据ID获取RSS源更新运行记录的详细统计
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "运行记录ID"
+// @Success 200 {object} RSSResponse{data=models.FeedUpdateRun}
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/rss_update_runs/{id} [get]
+func GetFeedUpdateRunByID(c *gin.Context) {
+	id := c.Param("id")
+	var run models.FeedUpdateRun
+	if err := models.DB.First(&run, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的运行记录不存在", id)})
+		} else {
+			utils.LogError(fmt.Sprintf("获取ID为%s的运行记录失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取运行记录失败", "error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取运行记录成功", "data": run})
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"backend/models"
+	"backend/services/filter"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// bindFilterRule 校验筛选规则请求并写入模型，校验失败时返回错误信息
+func bindFilterRule(req models.FilterRuleRequest, rule *models.FilterRule) error {
+	if req.Mode == "" {
+		req.Mode = filter.ModeSubstring
+	}
+	if err := filter.Validate(filter.Rule{Type: req.Type, Field: req.Field, Mode: req.Mode, Pattern: req.Pattern}); err != nil {
+		return err
+	}
+
+	if req.RssID != nil {
+		var count int64
+		if err := models.DB.Model(&models.RSSFeed{}).Where("id = ?", *req.RssID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("ID为%d的RSS订阅源不存在", *req.RssID)
+		}
+	}
+
+	rule.RssID = req.RssID
+	rule.Type = req.Type
+	rule.Field = req.Field
+	rule.Mode = req.Mode
+	rule.Pattern = req.Pattern
+	rule.Description = req.Description
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+// clearRulePageCache 规则变更后清除相关分页缓存，使未变化的分页也按新规则重新筛选
+func clearRulePageCache(rssID *uint) {
+	var err error
+	if rssID == nil {
+		err = models.ClearAllRSSFeedPageCache(models.DB)
+	} else {
+		err = models.ClearRSSFeedPageCache(models.DB, *rssID)
+	}
+	if err != nil {
+		utils.LogError("清除RSS订阅源分页缓存失败", err)
+	}
+}
+
+// @Summary 获取筛选规则
+// @Description 获取筛选规则列表，可按RSS源筛选；scope=global只返回全局规则
+// @Tags 筛选规则管理
+// @Produce json
+// @Security Bearer
+// @Param feed_id query int false "RSS源ID，返回该订阅源的规则"
+// @Param scope query string false "global：只返回全局规则"
+// @Success 200 {object} RSSResponse{data=[]models.FilterRule}
+// @Failure 500 {object} RSSResponse
+// @Router /admin/filter_rules [get]
+func GetFilterRules(c *gin.Context) {
+	query := models.DB.Model(&models.FilterRule{})
+	if c.Query("scope") == "global" {
+		query = query.Where("rss_id IS NULL")
+	} else if feedID := c.Query("feed_id"); feedID != "" {
+		query = query.Where("rss_id = ?", feedID)
+	}
+
+	rules := make([]models.FilterRule, 0)
+	if err := query.Order("id").Find(&rules).Error; err != nil {
+		utils.LogError("获取筛选规则失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取筛选规则失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取筛选规则成功", "data": rules})
+}
+
+// @Summary 创建筛选规则
+// @Description 创建全局或订阅源筛选规则，规则会在保存前校验（如正则表达式是否有效）
+// @Tags 筛选规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param rule body models.FilterRuleRequest true "筛选规则"
+// @Success 201 {object} RSSResponse{data=models.FilterRule}
+// @Failure 400 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/filter_rules [post]
+func CreateFilterRule(c *gin.Context) {
+	var req models.FilterRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+
+	rule := models.FilterRule{Enabled: true}
+	if err := bindFilterRule(req, &rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "筛选规则无效", "error": err.Error()})
+		return
+	}
+
+	if err := models.DB.Create(&rule).Error; err != nil {
+		utils.LogError("创建筛选规则失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "创建筛选规则失败", "error": err.Error()})
+		return
+	}
+	// enabled字段带有数据库默认值，创建时的false会被忽略，需要单独更新
+	if !rule.Enabled {
+		if err := models.DB.Model(&rule).Update("enabled", false).Error; err != nil {
+			utils.LogError("停用筛选规则失败", err)
+		}
+	}
+
+	clearRulePageCache(rule.RssID)
+	c.JSON(http.StatusCreated, gin.H{"code": http.StatusCreated, "message": "创建筛选规则成功", "data": rule})
+}
+
+// @Summary 更新筛选规则
+// @Description 更新指定ID的筛选规则
+// @Tags 筛选规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "筛选规则ID"
+// @Param rule body models.FilterRuleRequest true "筛选规则"
+// @Success 200 {object} RSSResponse{data=models.FilterRule}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/filter_rules/{id} [put]
+func UpdateFilterRule(c *gin.Context) {
+	id := c.Param("id")
+	var rule models.FilterRule
+	if err := models.DB.First(&rule, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的筛选规则不存在", id)})
+		} else {
+			utils.LogError(fmt.Sprintf("获取ID为%s的筛选规则失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "更新筛选规则失败", "error": err.Error()})
+		}
+		return
+	}
+
+	var req models.FilterRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+	previousRssID := rule.RssID
+	if err := bindFilterRule(req, &rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "筛选规则无效", "error": err.Error()})
+		return
+	}
+
+	if err := models.DB.Save(&rule).Error; err != nil {
+		utils.LogError(fmt.Sprintf("更新ID为%s的筛选规则失败", id), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "更新筛选规则失败", "error": err.Error()})
+		return
+	}
+
+	clearRulePageCache(previousRssID)
+	if rule.RssID != nil && (previousRssID == nil || *previousRssID != *rule.RssID) {
+		clearRulePageCache(rule.RssID)
+	}
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "更新筛选规则成功", "data": rule})
+}
+
+// @Summary 删除筛选规则
+// @Description 删除指定ID的筛选规则
+// @Tags 筛选规则管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "筛选规则ID"
+// @Success 200 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/filter_rules/{id} [delete]
+func DeleteFilterRule(c *gin.Context) {
+	id := c.Param("id")
+	var rule models.FilterRule
+	if err := models.DB.First(&rule, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的筛选规则不存在", id)})
+		} else {
+			utils.LogError(fmt.Sprintf("获取ID为%s的筛选规则失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "删除筛选规则失败", "error": err.Error()})
+		}
+		return
+	}
+
+	if err := models.DB.Delete(&rule).Error; err != nil {
+		utils.LogError(fmt.Sprintf("删除ID为%s的筛选规则失败", id), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "删除筛选规则失败", "error": err.Error()})
+		return
+	}
+
+	clearRulePageCache(rule.RssID)
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "删除筛选规则成功"})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"backend/models"
 	"backend/utils"
+	"backend/utils/parser"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,7 @@ type GlobalSettingsUpdateRequest struct {
 	GlobalKeywords    string `json:"global_keywords" example:"动画,动漫" description:"全局关键词"`
 	ExcludeKeywords   string `json:"exclude_keywords" example:"预告,PV" description:"全局排除关键词"`
 	SubGroupBlacklist string `json:"sub_group_blacklist" example:"字幕组1,字幕组2" description:"字幕组黑名单"`
+	SubtitleLanguages string `json:"subtitle_languages" example:"zh-Hans,zh-Hant,ja" description:"可接受的字幕语言（zh-Hans/zh-Hant/zh/ja/en，用+表示需同时包含，any表示不限），为空时保持不变"`
 }
 
 // GlobalSettingsResponse 用于Swagger文档的全局设置响应模型
@@ -21,12 +23,13 @@ type GlobalSettingsResponse struct {
 	GlobalKeywords    string `json:"global_keywords" example:"动画,动漫" description:"全局关键词"`
 	ExcludeKeywords   string `json:"exclude_keywords" example:"预告,PV" description:"全局排除关键词"`
 	SubGroupBlacklist string `json:"sub_group_blacklist" example:"字幕组1,字幕组2" description:"字幕组黑名单"`
+	SubtitleLanguages string `json:"subtitle_languages" example:"zh-Hans,zh-Hant,ja" description:"可接受的字幕语言"`
 	CreatedAt         string `json:"created_at" example:"2024-05-20T12:00:00Z" description:"创建时间"`
 	UpdatedAt         string `json:"updated_at" example:"2024-05-20T12:00:00Z" description:"更新时间"`
 }
 
 // @Summary 获取全局设置
-// @Description 获取全局关键词、排除关键词和字幕组黑名单设置
+// @Description 获取全局关键词、排除关键词、字幕组黑名单和字幕语言设置
 // @Tags 全局设置
 // @Produce json
 // @Success 200 {object} GlobalSettingsResponse
@@ -49,6 +52,7 @@ func GetGlobalSettings(c *gin.Context) {
 		GlobalKeywords:    settings.GlobalKeywords,
 		ExcludeKeywords:   settings.ExcludeKeywords,
 		SubGroupBlacklist: settings.SubGroupBlacklist,
+		SubtitleLanguages: settings.SubtitleLanguages,
 		CreatedAt:         settings.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:         settings.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
@@ -61,7 +65,7 @@ func GetGlobalSettings(c *gin.Context) {
 }
 
 // @Summary 更新全局设置
-// @Description 更新全局关键词、排除关键词和字幕组黑名单设置
+// @Description 更新全局关键词、排除关键词、字幕组黑名单和字幕语言设置
 // @Tags 全局设置
 // @Accept json
 // @Produce json
@@ -81,6 +85,17 @@ func UpdateGlobalSettings(c *gin.Context) {
 		return
 	}
 
+	if req.SubtitleLanguages != "" {
+		if _, err := parser.ParseSubtitlePreference(req.SubtitleLanguages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    http.StatusBadRequest,
+				"message": "字幕语言设置无效",
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
 	settings, err := models.GetGlobalSettings()
 	if err != nil {
 		utils.LogError("获取全局设置失败", err)
@@ -95,6 +110,9 @@ func UpdateGlobalSettings(c *gin.Context) {
 	settings.GlobalKeywords = req.GlobalKeywords
 	settings.ExcludeKeywords = req.ExcludeKeywords
 	settings.SubGroupBlacklist = req.SubGroupBlacklist
+	if req.SubtitleLanguages != "" {
+		settings.SubtitleLanguages = req.SubtitleLanguages
+	}
 
 	if err := models.UpdateGlobalSettings(settings); err != nil {
 		utils.LogError("更新全局设置失败", err)
@@ -106,11 +124,15 @@ func UpdateGlobalSettings(c *gin.Context) {
 		return
 	}
 
+	// 全局筛选条件变更后清除分页缓存，使未变化的分页也按新设置重新筛选
+	clearRulePageCache(nil)
+
 	response := GlobalSettingsResponse{
 		ID:                settings.ID,
 		GlobalKeywords:    settings.GlobalKeywords,
 		ExcludeKeywords:   settings.ExcludeKeywords,
 		SubGroupBlacklist: settings.SubGroupBlacklist,
+		SubtitleLanguages: settings.SubtitleLanguages,
 		CreatedAt:         settings.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:         settings.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
@@ -219,7 +219,7 @@ func (icc *InvitationCodeController) SendInvitationCode(c *gin.Context) {
 
 	// 发送邮件
 	mailService := mail.NewMailService()
-	if err := mailService.SendInvitationCode(req.Email, req.Code, invCode.ExpiresAt); err != nil {
+	if err := mailService.SendInvitationCode(c.Request.Context(), req.Email, req.Code, invCode.ExpiresAt); err != nil {
 		utils.LogError("发送邀请码邮件失败", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": fmt.Sprintf("发送邀请码邮件失败: %v", err),
@@ -121,7 +121,7 @@ func GetLogs(c *gin.Context) {
 
 // WatchLogs godoc
 // @Summary      实时监控系统日志
-// @Description  通过WebSocket实时接收系统日志
+// @Description  通过WebSocket实时接收系统日志，以及RSS手动更新任务的进度事件（JSON格式，type为rss_update_job）
 // @Tags         系统管理
 // @Accept       json
 // @Produce      json
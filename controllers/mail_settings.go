@@ -165,7 +165,7 @@ func (mc *MailSettingsController) TestMailSettings(c *gin.Context) {
 	}
 
 	mailService := mail.NewMailService()
-	err := mailService.SendMail(req.To, "测试邮件", `
+	err := mailService.SendMail(c.Request.Context(), req.To, "测试邮件", `
 		<h2>邮件发送测试</h2>
 		<p>这是一封测试邮件，用于验证邮件服务配置是否正确。</p>
 		<p>如果您收到这封邮件，说明邮件服务配置成功！</p>
@@ -211,7 +211,7 @@ func (mc *MailSettingsController) SendCustomMail(c *gin.Context) {
 	}
 
 	mailService := mail.NewMailService()
-	err := mailService.SendCustomMail(req.To, req.Subject, req.Content, req.IsHTML)
+	err := mailService.SendCustomMail(c.Request.Context(), req.To, req.Subject, req.Content, req.IsHTML)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -3,17 +3,21 @@ package controllers
 import (
 	"backend/models"
 	"backend/utils"
+	"backend/utils/parser"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type HistoryRequest struct {
+	// 种子URL或磁力链接
 	Url string `json:"url" binding:"required" example:"https://mikanime.tv/Download/20120812/6cfa68ddda6972015edbc4a505357ed4d2275f77.torrent"`
 }
 
@@ -44,7 +48,7 @@ type HistoryArray struct {
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
-// @Param        login body HistoryRequest true "历史记录请求信息"
+// @Param        login body HistoryRequest true "历史记录请求信息，url可以是种子URL或磁力链接"
 // @Success 200 {object} BangumiResponse
 // @Failure 500 {object} BangumiResponse
 // @Router /history/play_history [post]
@@ -61,11 +65,25 @@ func AddOrUpdatePlayHistroy(c *gin.Context) {
 	}
 
 	var check_result models.RSSItem
-	// 构建查询
-	query := models.DB.Model(&models.RSSItem{}).Where("url = ?", body.Url).Order("id asc")
-
-	// 根据url种子查询其id
-	if err := query.First(&check_result).Error; err != nil {
+	// 磁力链接按infohash匹配，兼容链接中Tracker等参数不同的情况
+	err := gorm.ErrRecordNotFound
+	if strings.HasPrefix(strings.ToLower(body.Url), "magnet:") {
+		magnet, parseErr := parser.ParseMagnet(body.Url)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, HistoryResponse{
+				Code:    http.StatusBadRequest,
+				Message: "无效的磁力链接",
+				Error:   parseErr.Error(),
+			})
+			return
+		}
+		err = models.DB.Where("info_hash = ?", magnet.InfoHash).Order("id asc").First(&check_result).Error
+	}
+	// 根据url种子查询其id，未记录infohash的条目同样按url匹配
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = models.DB.Where("url = ?", body.Url).Order("id asc").First(&check_result).Error
+	}
+	if err != nil {
 		DatabaseErrorHandlerD(c, "查询rss_items是否存在失败", info+"失败", err)
 		return
 	}
@@ -80,7 +98,7 @@ func AddOrUpdatePlayHistroy(c *gin.Context) {
 		RssItemsId: check_result.ID,
 		UserId:     uid,
 	}
-	err := models.DB.Clauses(clause.OnConflict{
+	err = models.DB.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
 		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "deleted_at"}),
 	}).Create(&newItem).Error
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"backend/models"
+	"backend/services/rss"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ReviewAssignRequest 人工指定待审核条目的番剧和集数
+type ReviewAssignRequest struct {
+	BangumiID uint     `json:"bangumi_id" example:"1" binding:"required" description:"番剧ID"`
+	Episode   *float64 `json:"episode" example:"5" description:"集数，为空时保留解析结果"`
+}
+
+// findReviewItem 按路径参数查询待审核条目，不存在或查询失败时直接返回错误响应
+func findReviewItem(c *gin.Context, action string) (*models.ReviewItem, bool) {
+	id := c.Param("id")
+	var review models.ReviewItem
+	if err := models.DB.First(&review, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的待审核条目不存在", id)})
+		} else {
+			utils.LogError(fmt.Sprintf("获取ID为%s的待审核条目失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": action + "失败", "error": err.Error()})
+		}
+		return nil, false
+	}
+	if review.Status != models.ReviewStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"code": http.StatusConflict, "message": fmt.Sprintf("ID为%s的待审核条目已处理", id)})
+		return nil, false
+	}
+	return &review, true
+}
+
+// @Summary 获取待审核条目
+// @Description 分页获取解析失败或置信度较低的条目，按置信度升序、创建时间倒序
+// @Tags 待审核条目
+// @Produce json
+// @Security Bearer
+// @Param status query string false "状态(pending/assigned/discarded)，默认pending"
+// @Param feed_id query int false "RSS源ID"
+// @Param reason query string false "低置信度原因(parse_failed/no_name/no_episode/season_unclear/new_bangumi)"
+// @Param page query int false "页码，默认1"
+// @Param page_size query int false "每页数量，默认10"
+// @Success 200 {object} RSSResponse{data=[]models.ReviewItem}
+// @Failure 400 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/review_items [get]
+func GetReviewItems(c *gin.Context) {
+	page := utils.GetPage(c)
+	pageSize := utils.GetPageSize(c)
+
+	query := models.DB.Model(&models.ReviewItem{}).Where("status = ?", c.DefaultQuery("status", models.ReviewStatusPending))
+	if feedID := c.Query("feed_id"); feedID != "" {
+		id, err := strconv.ParseUint(feedID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "feed_id参数无效"})
+			return
+		}
+		query = query.Where("rss_id = ?", id)
+	}
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reasons LIKE ?", "%"+reason+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.LogError("获取待审核条目数量失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取待审核条目失败", "error": err.Error()})
+		return
+	}
+
+	reviews := make([]models.ReviewItem, 0)
+	if err := query.Order("confidence ASC, created_at DESC, id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&reviews).Error; err != nil {
+		utils.LogError("获取待审核条目失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取待审核条目失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取待审核条目成功",
+		"data": gin.H{
+			"total":       total,
+			"page":        page,
+			"page_size":   pageSize,
+			"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+			"list":        reviews,
+		},
+	})
+}
+
+// @Summary 指定待审核条目的番剧和集数
+// @Description 将待审核条目归入指定番剧和集数（已自动入库的条目会被修改），并生成覆盖规则，之后相同原始标题的条目直接按该规则入库
+// @Tags 待审核条目
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "待审核条目ID"
+// @Param assign body ReviewAssignRequest true "番剧和集数"
+// @Success 200 {object} RSSResponse{data=models.ReviewItem}
+// @Failure 400 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 409 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/review_items/{id}/assign [post]
+func AssignReviewItem(c *gin.Context) {
+	var req ReviewAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+
+	review, ok := findReviewItem(c, "指定番剧")
+	if !ok {
+		return
+	}
+
+	if err := rss.AssignReviewItem(models.DB, review, req.BangumiID, req.Episode); err != nil {
+		utils.LogError(fmt.Sprintf("指定待审核条目[ID:%d]的番剧失败", review.ID), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "指定番剧失败", "error": err.Error()})
+		return
+	}
+	clearRulePageCache(&review.RssID)
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "指定番剧成功", "data": review})
+}
+
+// @Summary 忽略待审核条目
+// @Description 忽略待审核条目，已自动入库的RSS条目会被删除，并生成忽略规则，之后相同原始标题的条目直接跳过
+// @Tags 待审核条目
+// @Produce json
+// @Security Bearer
+// @Param id path int true "待审核条目ID"
+// @Success 200 {object} RSSResponse{data=models.ReviewItem}
+// @Failure 404 {object} RSSResponse
+// @Failure 409 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/review_items/{id}/discard [post]
+func DiscardReviewItem(c *gin.Context) {
+	review, ok := findReviewItem(c, "忽略待审核条目")
+	if !ok {
+		return
+	}
+
+	if err := rss.DiscardReviewItem(models.DB, review); err != nil {
+		utils.LogError(fmt.Sprintf("忽略待审核条目[ID:%d]失败", review.ID), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "忽略待审核条目失败", "error": err.Error()})
+		return
+	}
+	clearRulePageCache(&review.RssID)
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "忽略待审核条目成功", "data": review})
+}
+
+// @Summary 获取标题覆盖规则
+// @Description 获取人工处理待审核条目后生成的覆盖规则
+// @Tags 待审核条目
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RSSResponse{data=[]models.TitleOverride}
+// @Failure 500 {object} RSSResponse
+// @Router /admin/title_overrides [get]
+func GetTitleOverrides(c *gin.Context) {
+	overrides := make([]models.TitleOverride, 0)
+	if err := models.DB.Order("id DESC").Find(&overrides).Error; err != nil {
+		utils.LogError("获取标题覆盖规则失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取标题覆盖规则失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取标题覆盖规则成功", "data": overrides})
+}
+
+// @Summary 删除标题覆盖规则
+// @Description 删除指定ID的标题覆盖规则，之后相同原始标题的条目重新按解析结果处理
+// @Tags 待审核条目
+// @Produce json
+// @Security Bearer
+// @Param id path int true "覆盖规则ID"
+// @Success 200 {object} RSSResponse
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/title_overrides/{id} [delete]
+func DeleteTitleOverride(c *gin.Context) {
+	id := c.Param("id")
+	result := models.DB.Delete(&models.TitleOverride{}, id)
+	if result.Error != nil {
+		utils.LogError(fmt.Sprintf("删除ID为%s的标题覆盖规则失败", id), result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "删除标题覆盖规则失败", "error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的标题覆盖规则不存在", id)})
+		return
+	}
+	// 覆盖规则对所有RSS源生效，清除全部分页缓存
+	clearRulePageCache(nil)
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "删除标题覆盖规则成功"})
+}
@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"backend/models"
 	"backend/services/rss"
 	"backend/utils"
+	"backend/utils/parser"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -23,6 +26,49 @@ type RSSResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
+// newRSSFeedResponse 将RSS订阅源转换为响应结构
+func newRSSFeedResponse(feed models.RSSFeed) models.RSSFeedResponse {
+	response := models.RSSFeedResponse{
+		ID:                  feed.ID,
+		Name:                feed.Name,
+		URL:                 feed.URL,
+		UpdateInterval:      feed.UpdateInterval,
+		Keywords:            feed.Keywords,
+		Priority:            feed.Priority,
+		ParserType:          feed.ParserType,
+		CreatedAt:           feed.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:           feed.UpdatedAt.Format("2006-01-02 15:04:05"),
+		PageStart:           feed.PageStart,
+		PageEnd:             feed.PageEnd,
+		ExcludeKeywords:     feed.ExcludeKeywords,
+		SubtitleLanguages:   feed.SubtitleLanguages,
+		CronExpr:            feed.CronExpr,
+		Paused:              feed.Paused,
+		Enabled:             feed.Enabled,
+		HealthStatus:        feed.HealthStatus(),
+		LastError:           feed.LastError,
+		ConsecutiveFailures: feed.ConsecutiveFailures,
+		LastItemsAdded:      feed.LastItemsAdded,
+	}
+	if feed.LastCheckedAt != nil {
+		lastChecked := feed.LastCheckedAt.Format("2006-01-02 15:04:05")
+		response.LastCheckedAt = &lastChecked
+	}
+	if feed.LastSuccessAt != nil {
+		lastSuccess := feed.LastSuccessAt.Format("2006-01-02 15:04:05")
+		response.LastSuccessAt = &lastSuccess
+	}
+	if feed.NextRunAt != nil {
+		nextRun := feed.NextRunAt.Format("2006-01-02 15:04:05")
+		response.NextRunAt = &nextRun
+	}
+	if feed.RunningSince != nil {
+		runningSince := feed.RunningSince.Format("2006-01-02 15:04:05")
+		response.RunningSince = &runningSince
+	}
+	return response
+}
+
 // @Summary 获取所有RSS订阅源
 // @Description 获取系统中所有已配置的RSS订阅源列表
 // @Tags RSS订阅源管理
@@ -40,20 +86,7 @@ func GetAllRSSFeeds(c *gin.Context) {
 
 	response := make([]models.RSSFeedResponse, len(feeds))
 	for i, feed := range feeds {
-		response[i] = models.RSSFeedResponse{
-			ID:              feed.ID,
-			Name:            feed.Name,
-			URL:             feed.URL,
-			UpdateInterval:  feed.UpdateInterval,
-			Keywords:        feed.Keywords,
-			Priority:        feed.Priority,
-			ParserType:      feed.ParserType,
-			CreatedAt:       feed.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:       feed.UpdatedAt.Format("2006-01-02 15:04:05"),
-			PageStart:       feed.PageStart,
-			PageEnd:         feed.PageEnd,
-			ExcludeKeywords: feed.ExcludeKeywords,
-		}
+		response[i] = newRSSFeedResponse(feed)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取RSS订阅源列表成功", "data": response})
@@ -81,20 +114,7 @@ func GetRSSFeedByID(c *gin.Context) {
 		return
 	}
 
-	response := models.RSSFeedResponse{
-		ID:              feed.ID,
-		Name:            feed.Name,
-		URL:             feed.URL,
-		UpdateInterval:  feed.UpdateInterval,
-		Keywords:        feed.Keywords,
-		Priority:        feed.Priority,
-		ParserType:      feed.ParserType,
-		CreatedAt:       feed.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:       feed.UpdatedAt.Format("2006-01-02 15:04:05"),
-		PageStart:       feed.PageStart,
-		PageEnd:         feed.PageEnd,
-		ExcludeKeywords: feed.ExcludeKeywords,
-	}
+	response := newRSSFeedResponse(feed)
 
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取RSS订阅源成功", "data": response})
 }
@@ -116,6 +136,23 @@ func CreateRSSFeed(c *gin.Context) {
 		return
 	}
 
+	if !rss.IsValidParserType(req.ParserType) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": fmt.Sprintf("不支持的解析器类型: %s", req.ParserType)})
+		return
+	}
+	if req.SubtitleLanguages != "" {
+		if _, err := parser.ParseSubtitlePreference(req.SubtitleLanguages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "字幕语言设置无效", "error": err.Error()})
+			return
+		}
+	}
+	if req.CronExpr != "" {
+		if _, err := rss.ParseCron(req.CronExpr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "cron表达式无效", "error": err.Error()})
+			return
+		}
+	}
+
 	// 检查URL是否已存在
 	var existingFeed models.RSSFeed
 	err := models.DB.Where("url = ?", req.URL).First(&existingFeed).Error
@@ -140,6 +177,10 @@ func CreateRSSFeed(c *gin.Context) {
 		PageStart:       req.PageStart,
 		PageEnd:         req.PageEnd,
 		ExcludeKeywords: req.ExcludeKeywords,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+
+		SubtitleLanguages: req.SubtitleLanguages,
+		CronExpr:          req.CronExpr,
 	}
 
 	if err := models.DB.Create(&feed).Error; err != nil {
@@ -147,22 +188,15 @@ func CreateRSSFeed(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "创建RSS订阅源失败", "error": err.Error()})
 		return
 	}
-
-	response := models.RSSFeedResponse{
-		ID:              feed.ID,
-		Name:            feed.Name,
-		URL:             feed.URL,
-		UpdateInterval:  feed.UpdateInterval,
-		Keywords:        feed.Keywords,
-		Priority:        feed.Priority,
-		ParserType:      feed.ParserType,
-		CreatedAt:       feed.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:       feed.UpdatedAt.Format("2006-01-02 15:04:05"),
-		PageStart:       feed.PageStart,
-		PageEnd:         feed.PageEnd,
-		ExcludeKeywords: feed.ExcludeKeywords,
+	// enabled字段带有数据库默认值，创建时的false会被忽略，需要单独更新
+	if !feed.Enabled {
+		if err := models.DB.Model(&feed).Update("enabled", false).Error; err != nil {
+			utils.LogError("停用RSS订阅源失败", err)
+		}
 	}
 
+	response := newRSSFeedResponse(feed)
+
 	c.JSON(http.StatusCreated, gin.H{"code": http.StatusCreated, "message": "创建RSS订阅源成功", "data": response})
 }
 
@@ -197,23 +231,58 @@ func UpdateRSSFeed(c *gin.Context) {
 		return
 	}
 
+	if !rss.IsValidParserType(req.ParserType) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": fmt.Sprintf("不支持的解析器类型: %s", req.ParserType)})
+		return
+	}
+	if req.SubtitleLanguages != "" {
+		if _, err := parser.ParseSubtitlePreference(req.SubtitleLanguages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "字幕语言设置无效", "error": err.Error()})
+			return
+		}
+	}
+	if req.CronExpr != "" {
+		if _, err := rss.ParseCron(req.CronExpr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "cron表达式无效", "error": err.Error()})
+			return
+		}
+	}
+
 	feed.Name = req.Name
 	feed.URL = req.URL
-	feed.UpdateInterval = req.UpdateInterval
 	feed.Keywords = req.Keywords
 	feed.Priority = req.Priority
 	feed.ParserType = req.ParserType
 	feed.PageStart = req.PageStart
 	feed.PageEnd = req.PageEnd
 	feed.ExcludeKeywords = req.ExcludeKeywords
+	feed.SubtitleLanguages = req.SubtitleLanguages
+	// 运行计划变更后按新的计划重新计算下次运行时间
+	if feed.UpdateInterval != req.UpdateInterval || feed.CronExpr != req.CronExpr {
+		feed.NextRunAt = nil
+	}
+	feed.UpdateInterval = req.UpdateInterval
+	feed.CronExpr = req.CronExpr
+	if req.Enabled != nil {
+		// 重新启用时清零连续失败次数，避免立即再次进入退避
+		if *req.Enabled && !feed.Enabled {
+			feed.ConsecutiveFailures = 0
+		}
+		feed.Enabled = *req.Enabled
+	}
 
-	// 从数据库中重新查询以确保数据是最新的
-	if err := models.DB.Save(&feed).Error; err != nil {
+	// 运行标记由更新任务维护，保存时不覆盖
+	if err := models.DB.Omit("running_since").Save(&feed).Error; err != nil {
 		utils.LogError(fmt.Sprintf("更新ID为%s的RSS订阅源失败", id), err)
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "更新RSS订阅源失败", "error": err.Error()})
 		return
 	}
 
+	// 订阅源配置（URL、关键词、分页等）变更后，已缓存的分页需要重新处理
+	if err := models.ClearRSSFeedPageCache(models.DB, feed.ID); err != nil {
+		utils.LogError(fmt.Sprintf("清除ID为%s的RSS订阅源分页缓存失败", id), err)
+	}
+
 	// 重新从数据库获取完整的feed信息
 	var updatedFeed models.RSSFeed
 	if err := models.DB.First(&updatedFeed, id).Error; err != nil {
@@ -222,54 +291,163 @@ func UpdateRSSFeed(c *gin.Context) {
 		return
 	}
 
-	response := models.RSSFeedResponse{
-		ID:              updatedFeed.ID,
-		Name:            updatedFeed.Name,
-		URL:             updatedFeed.URL,
-		UpdateInterval:  updatedFeed.UpdateInterval,
-		Keywords:        updatedFeed.Keywords,
-		Priority:        updatedFeed.Priority,
-		ParserType:      updatedFeed.ParserType,
-		CreatedAt:       updatedFeed.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:       updatedFeed.UpdatedAt.Format("2006-01-02 15:04:05"),
-		PageStart:       updatedFeed.PageStart,
-		PageEnd:         updatedFeed.PageEnd,
-		ExcludeKeywords: updatedFeed.ExcludeKeywords,
-	}
+	response := newRSSFeedResponse(updatedFeed)
 
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "更新RSS订阅源成功", "data": response})
 }
 
-// @Summary 手动更新所有RSS订阅
-// @Description 手动触发RSS订阅源的更新任务，立即返回并在后台执行更新
+// @Summary 获取可用的解析器类型
+// @Description 获取所有已注册的RSS订阅源解析器类型，用于创建或更新订阅源时选择parser_type
 // @Tags RSS订阅源管理
 // @Produce json
-// @Success 200 {object} RSSResponse
-// @Router /rss_feeds/update [post]
-func ManualUpdateRSSFeeds(c *gin.Context) {
-	// 立即返回成功响应
+// @Security Bearer
+// @Success 200 {object} RSSResponse{data=[]rss.ParserTypeInfo}
+// @Router /admin/rss_feeds/parser_types [get]
+func GetRSSParserTypes(c *gin.Context) {
 	c.JSON(http.StatusOK, RSSResponse{
 		Code:    http.StatusOK,
-		Message: "RSS更新任务已在后台触发",
+		Message: "获取解析器类型成功",
+		Data:    rss.ListParserTypes(),
 	})
+}
+
+// @Summary 预览RSS订阅源配置
+// @Description 使用请求中的配置执行抓取、解析和筛选，返回每个候选条目的解析结果、归属番剧和收录/拒绝原因，不写入数据库
+// @Tags RSS订阅源管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param feed body models.RSSFeedRequest true "RSS订阅源信息"
+// @Success 200 {object} RSSResponse{data=rss.FeedPreview}
+// @Failure 400 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/rss_feeds/preview [post]
+func PreviewRSSFeedConfig(c *gin.Context) {
+	var req models.RSSFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
 
-	// 在后台执行更新操作
-	go func() {
-		if err := rss.UpdateRSSFeeds(models.DB, true); err != nil {
-			utils.LogError("后台RSS更新失败", err)
+	if !rss.IsValidParserType(req.ParserType) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": fmt.Sprintf("不支持的解析器类型: %s", req.ParserType)})
+		return
+	}
+	if req.SubtitleLanguages != "" {
+		if _, err := parser.ParseSubtitlePreference(req.SubtitleLanguages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "字幕语言设置无效", "error": err.Error()})
+			return
+		}
+	}
+
+	feed := models.RSSFeed{
+		Name:            req.Name,
+		URL:             req.URL,
+		UpdateInterval:  req.UpdateInterval,
+		Keywords:        req.Keywords,
+		Priority:        req.Priority,
+		ParserType:      req.ParserType,
+		PageStart:       req.PageStart,
+		PageEnd:         req.PageEnd,
+		ExcludeKeywords: req.ExcludeKeywords,
+
+		SubtitleLanguages: req.SubtitleLanguages,
+	}
+
+	preview, err := rss.PreviewFeed(c.Request.Context(), models.DB, feed)
+	if err != nil {
+		utils.LogError("预览RSS订阅源配置失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "预览RSS订阅源失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "预览RSS订阅源成功", "data": preview})
+}
+
+// @Summary 预览已有RSS订阅源
+// @Description 使用已保存的订阅源配置执行抓取、解析和筛选，返回每个候选条目的收录/拒绝原因，不写入数据库
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "RSS订阅源ID"
+// @Success 200 {object} RSSResponse{data=rss.FeedPreview}
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/rss_feeds/{id}/preview [post]
+func PreviewRSSFeedByID(c *gin.Context) {
+	id := c.Param("id")
+	var feed models.RSSFeed
+	if err := models.DB.First(&feed, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的RSS订阅源不存在", id)})
 		} else {
-			utils.LogInfo("后台RSS更新完成")
+			utils.LogError(fmt.Sprintf("获取ID为%s的RSS订阅源失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "预览RSS订阅源失败", "error": err.Error()})
+		}
+		return
+	}
+
+	preview, err := rss.PreviewFeed(c.Request.Context(), models.DB, feed)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("预览ID为%s的RSS订阅源失败", id), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "预览RSS订阅源失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "预览RSS订阅源成功", "data": preview})
+}
+
+// @Summary 手动更新所有RSS订阅
+// @Description 手动触发RSS订阅源的更新任务，立即返回任务进度并在后台执行更新，进度可通过任务接口查询或从管理员WebSocket接收；
+// @Description 当前实例不是主节点时提交给主节点执行，不返回任务
+// @Tags RSS订阅源管理
+// @Produce json
+// @Success 200 {object} RSSResponse
+// @Success 202 {object} RSSResponse{data=rss.UpdateJobStatus}
+// @Failure 409 {object} RSSResponse{data=rss.UpdateJobStatus}
+// @Router /rss_feeds/update [post]
+func ManualUpdateRSSFeeds(c *gin.Context) {
+	// 多实例部署时由主节点执行更新，当前实例只记录请求
+	if !rss.IsLeader() {
+		if err := rss.RequestRun(models.DB, 0); err != nil {
+			utils.LogError("提交RSS更新请求失败", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "提交RSS更新请求失败", "error": err.Error()})
+			return
 		}
-	}()
+		c.JSON(http.StatusOK, RSSResponse{
+			Code:    http.StatusOK,
+			Message: "RSS更新任务已提交，将由主节点在下一轮调度时执行",
+		})
+		return
+	}
+
+	startRSSUpdateJob(c, 0, "RSS更新任务已在后台触发")
+}
+
+// startRSSUpdateJob 创建后台更新任务并返回任务进度，同一范围已有任务在执行时返回409和该任务
+func startRSSUpdateJob(c *gin.Context, feedID uint, message string) {
+	job, err := rss.StartUpdateJob(models.DB, feedID)
+	if errors.Is(err, rss.ErrUpdateJobRunning) {
+		c.JSON(http.StatusConflict, gin.H{"code": http.StatusConflict, "message": "已有相同的更新任务在执行", "data": job})
+		return
+	}
+	if err != nil {
+		utils.LogError("创建RSS更新任务失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "创建RSS更新任务失败", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"code": http.StatusAccepted, "message": message, "data": job})
 }
 
 // @Summary 手动更新指定RSS订阅
-// @Description 手动触发指定ID的RSS订阅源的更新任务
+// @Description 手动触发指定ID的RSS订阅源的更新任务，立即返回任务进度；当前实例不是主节点时提交给主节点执行，不返回任务
 // @Tags RSS订阅源管理
 // @Produce json
 // @Param id path int true "RSS订阅源ID"
 // @Success 200 {object} RSSResponse
+// @Success 202 {object} RSSResponse{data=rss.UpdateJobStatus}
 // @Failure 404 {object} RSSResponse
+// @Failure 409 {object} RSSResponse
 // @Failure 500 {object} RSSResponse
 // @Router /rss_feeds/{id}/update [post]
 func UpdateRSSFeedByID(c *gin.Context) {
@@ -292,21 +470,24 @@ func UpdateRSSFeedByID(c *gin.Context) {
 		})
 		return
 	}
-
-	// 立即返回响应
-	c.JSON(http.StatusOK, RSSResponse{
-		Code:    http.StatusOK,
-		Message: fmt.Sprintf("RSS订阅源[ID:%s]更新任务已在后台触发", id),
-	})
-
-	// 在后台执行更新操作
-	go func() {
-		if err := rss.UpdateSingleRSSFeed(models.DB, feed.ID); err != nil {
-			utils.LogError(fmt.Sprintf("后台更新RSS订阅源[ID:%s]失败", id), err)
-		} else {
-			utils.LogInfo(fmt.Sprintf("后台更新RSS订阅源[ID:%s]完成", id))
+	if rss.IsFeedRunning(feed, time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"code": http.StatusConflict, "message": fmt.Sprintf("RSS订阅源[ID:%s]正在更新中", id)})
+		return
+	}
+	if !rss.IsLeader() {
+		if err := rss.RequestRun(models.DB, feed.ID); err != nil {
+			utils.LogError(fmt.Sprintf("提交RSS订阅源[ID:%s]更新请求失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "提交RSS订阅源更新请求失败", "error": err.Error()})
+			return
 		}
-	}()
+		c.JSON(http.StatusOK, RSSResponse{
+			Code:    http.StatusOK,
+			Message: fmt.Sprintf("RSS订阅源[ID:%s]更新任务已提交，将由主节点在下一轮调度时执行", id),
+		})
+		return
+	}
+
+	startRSSUpdateJob(c, feed.ID, fmt.Sprintf("RSS订阅源[ID:%s]更新任务已在后台触发", id))
 }
 
 // @Summary 删除RSS订阅源
@@ -338,5 +519,12 @@ func DeleteRSSFeed(c *gin.Context) {
 		return
 	}
 
+	if err := models.ClearRSSFeedPageCache(models.DB, feed.ID); err != nil {
+		utils.LogError(fmt.Sprintf("清除ID为%s的RSS订阅源分页缓存失败", id), err)
+	}
+	if err := models.DB.Where("rss_id = ?", feed.ID).Delete(&models.FilterRule{}).Error; err != nil {
+		utils.LogError(fmt.Sprintf("删除ID为%s的RSS订阅源筛选规则失败", id), err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "删除RSS订阅源成功"})
 }
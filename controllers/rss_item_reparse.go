@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/models"
+	"backend/services/rss"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 重新解析RSS条目
+// @Description 用当前的标题解析器在后台按批重新解析已保存的RSS条目，进度和差异摘要（集数、分辨率、字幕等字段变化和番剧归属变化）通过状态接口查询。apply为false时只统计差异；apply为true时同时写入数据库
+// @Tags RSS条目
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param options body rss.ReparseOptions true "重新解析范围"
+// @Success 202 {object} RSSResponse
+// @Failure 400 {object} RSSResponse
+// @Failure 409 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/rss_items/reparse [post]
+func ReparseRSSItems(c *gin.Context) {
+	var opts rss.ReparseOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+
+	if err := rss.StartReparse(models.DB, opts); err != nil {
+		if errors.Is(err, rss.ErrReparseRunning) {
+			c.JSON(http.StatusConflict, gin.H{"code": http.StatusConflict, "message": err.Error()})
+			return
+		}
+		utils.LogError("启动重新解析RSS条目失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "启动重新解析失败", "error": err.Error()})
+		return
+	}
+
+	message := "重新解析任务已在后台开始"
+	if !opts.Apply {
+		message = "重新解析预览已在后台开始，差异摘要通过状态接口查询"
+	}
+	c.JSON(http.StatusAccepted, gin.H{"code": http.StatusAccepted, "message": message})
+}
+
+// @Summary 获取重新解析任务状态
+// @Description 获取最近一次后台重新解析任务的进度和差异摘要
+// @Tags RSS条目
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RSSResponse{data=rss.ReparseStatus}
+// @Router /admin/rss_items/reparse/status [get]
+func GetReparseStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取重新解析任务状态成功", "data": rss.GetReparseStatus()})
+}
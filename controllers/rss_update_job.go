@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/services/rss"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 获取RSS手动更新任务列表
+// @Description 获取当前实例最近的手动更新任务及进度，按创建时间倒序；任务只保存在内存中，重启后清空
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RSSResponse{data=[]rss.UpdateJobStatus}
+// @Router /admin/rss_update_jobs [get]
+func GetRSSUpdateJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取RSS更新任务成功", "data": rss.ListUpdateJobs()})
+}
+
+// @Summary 获取RSS手动更新任务进度
+// @Description 获取指定手动更新任务的进度：已处理的RSS源数、分页数、新增条目数和错误
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Param id path string true "任务ID"
+// @Success 200 {object} RSSResponse{data=rss.UpdateJobStatus}
+// @Failure 404 {object} RSSResponse
+// @Router /admin/rss_update_jobs/{id} [get]
+func GetRSSUpdateJob(c *gin.Context) {
+	id := c.Param("id")
+	job, err := rss.GetUpdateJob(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": "ID为" + id + "的更新任务不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取RSS更新任务成功", "data": job})
+}
+
+// @Summary 取消RSS手动更新任务
+// @Description 取消执行中的手动更新任务：不再处理剩余的RSS源，正在抓取的分页中止，已收录的条目保留
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Param id path string true "任务ID"
+// @Success 200 {object} RSSResponse{data=rss.UpdateJobStatus}
+// @Failure 404 {object} RSSResponse
+// @Failure 409 {object} RSSResponse{data=rss.UpdateJobStatus}
+// @Router /admin/rss_update_jobs/{id}/cancel [post]
+func CancelRSSUpdateJob(c *gin.Context) {
+	id := c.Param("id")
+	job, err := rss.CancelUpdateJob(id)
+	switch {
+	case errors.Is(err, rss.ErrUpdateJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": "ID为" + id + "的更新任务不存在"})
+	case errors.Is(err, rss.ErrUpdateJobFinished):
+		c.JSON(http.StatusConflict, gin.H{"code": http.StatusConflict, "message": "更新任务已结束", "data": job})
+	default:
+		c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "已请求取消更新任务", "data": job})
+	}
+}
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/services/rss"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SchedulerSettingsRequest 更新定时更新设置的请求体，未提供的字段保持不变
+type SchedulerSettingsRequest struct {
+	Paused        *bool   `json:"paused" example:"false" description:"是否暂停所有RSS源的定时更新"`
+	QuietStart    *string `json:"quiet_start" example:"02:00" description:"静默时段开始时间（HH:MM），与结束时间同时为空表示不设静默时段"`
+	QuietEnd      *string `json:"quiet_end" example:"06:00" description:"静默时段结束时间（HH:MM），可早于开始时间表示跨午夜"`
+	JitterSeconds *int    `json:"jitter_seconds" example:"60" description:"下次运行时间的随机延后上限（秒）"`
+}
+
+// SchedulerFeedStatus 单个RSS源的调度状态
+type SchedulerFeedStatus struct {
+	ID             uint    `json:"id"`
+	Name           string  `json:"name"`
+	Enabled        bool    `json:"enabled"`
+	Paused         bool    `json:"paused"`
+	UpdateInterval int     `json:"update_interval" description:"更新间隔(小时)"`
+	CronExpr       string  `json:"cron_expr"`
+	NextRunAt      *string `json:"next_run_at"`
+	RunningSince   *string `json:"running_since"`
+}
+
+// SchedulerStatusResponse 定时更新的设置和各RSS源的调度状态
+type SchedulerStatusResponse struct {
+	Paused        bool                  `json:"paused" description:"是否暂停所有RSS源的定时更新"`
+	QuietStart    string                `json:"quiet_start" description:"静默时段开始时间"`
+	QuietEnd      string                `json:"quiet_end" description:"静默时段结束时间"`
+	JitterSeconds int                   `json:"jitter_seconds" description:"下次运行时间的随机延后上限（秒）"`
+	SkipReason    string                `json:"skip_reason,omitempty" description:"当前跳过定时更新的原因"`
+	Feeds         []SchedulerFeedStatus `json:"feeds"`
+}
+
+// newSchedulerStatusResponse 汇总全局设置和所有RSS源的调度状态，RSS源按下次运行时间排序
+func newSchedulerStatusResponse(settings *models.GlobalSettings) (*SchedulerStatusResponse, error) {
+	var feeds []models.RSSFeed
+	if err := models.DB.Order("next_run_at IS NULL, next_run_at ASC, id ASC").Find(&feeds).Error; err != nil {
+		return nil, err
+	}
+
+	response := &SchedulerStatusResponse{
+		Paused:        settings.SchedulerPaused,
+		QuietStart:    settings.SchedulerQuietStart,
+		QuietEnd:      settings.SchedulerQuietEnd,
+		JitterSeconds: settings.SchedulerJitterSeconds,
+		SkipReason:    rss.SchedulerSkipReason(time.Now()),
+		Feeds:         make([]SchedulerFeedStatus, 0, len(feeds)),
+	}
+	for _, feed := range feeds {
+		status := SchedulerFeedStatus{
+			ID:             feed.ID,
+			Name:           feed.Name,
+			Enabled:        feed.Enabled,
+			Paused:         feed.Paused,
+			UpdateInterval: feed.UpdateInterval,
+			CronExpr:       feed.CronExpr,
+		}
+		if feed.NextRunAt != nil {
+			nextRun := feed.NextRunAt.Format("2006-01-02 15:04:05")
+			status.NextRunAt = &nextRun
+		}
+		if feed.RunningSince != nil {
+			runningSince := feed.RunningSince.Format("2006-01-02 15:04:05")
+			status.RunningSince = &runningSince
+		}
+		response.Feeds = append(response.Feeds, status)
+	}
+	return response, nil
+}
+
+// @Summary 获取定时更新状态
+// @Description 获取定时更新的暂停状态、静默时段、随机延后设置，以及各RSS源的下次运行时间和运行状态
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RSSResponse{data=SchedulerStatusResponse}
+// @Failure 500 {object} RSSResponse
+// @Router /admin/scheduler [get]
+func GetSchedulerStatus(c *gin.Context) {
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		utils.LogError("获取全局设置失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取定时更新状态失败", "error": err.Error()})
+		return
+	}
+
+	response, err := newSchedulerStatusResponse(settings)
+	if err != nil {
+		utils.LogError("获取RSS源调度状态失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取定时更新状态失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "获取定时更新状态成功", "data": response})
+}
+
+// @Summary 更新定时更新设置
+// @Description 更新定时更新的暂停状态、静默时段和随机延后上限，未提供的字段保持不变
+// @Tags RSS订阅源管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param settings body SchedulerSettingsRequest true "定时更新设置"
+// @Success 200 {object} RSSResponse{data=SchedulerStatusResponse}
+// @Failure 400 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/scheduler [put]
+func UpdateSchedulerSettings(c *gin.Context) {
+	var req SchedulerSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "请求参数无效", "error": err.Error()})
+		return
+	}
+	if req.JitterSeconds != nil && *req.JitterSeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "随机延后上限不能为负数"})
+		return
+	}
+
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		utils.LogError("获取全局设置失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取全局设置失败", "error": err.Error()})
+		return
+	}
+
+	if req.QuietStart != nil {
+		settings.SchedulerQuietStart = *req.QuietStart
+	}
+	if req.QuietEnd != nil {
+		settings.SchedulerQuietEnd = *req.QuietEnd
+	}
+	if _, err := rss.ParseQuietHours(settings.SchedulerQuietStart, settings.SchedulerQuietEnd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "静默时段设置无效", "error": err.Error()})
+		return
+	}
+	if req.Paused != nil {
+		settings.SchedulerPaused = *req.Paused
+	}
+	if req.JitterSeconds != nil {
+		settings.SchedulerJitterSeconds = *req.JitterSeconds
+	}
+
+	if err := models.UpdateGlobalSettings(settings); err != nil {
+		utils.LogError("更新定时更新设置失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "更新定时更新设置失败", "error": err.Error()})
+		return
+	}
+
+	response, err := newSchedulerStatusResponse(settings)
+	if err != nil {
+		utils.LogError("获取RSS源调度状态失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "获取定时更新状态失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "更新定时更新设置成功", "data": response})
+}
+
+// @Summary 暂停定时更新
+// @Description 暂停所有RSS源的定时更新，手动更新不受影响
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/scheduler/pause [post]
+func PauseScheduler(c *gin.Context) {
+	setSchedulerPaused(c, true)
+}
+
+// @Summary 恢复定时更新
+// @Description 恢复所有RSS源的定时更新
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/scheduler/resume [post]
+func ResumeScheduler(c *gin.Context) {
+	setSchedulerPaused(c, false)
+}
+
+func setSchedulerPaused(c *gin.Context, paused bool) {
+	action := "恢复"
+	if paused {
+		action = "暂停"
+	}
+
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		utils.LogError("获取全局设置失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": action + "定时更新失败", "error": err.Error()})
+		return
+	}
+	if err := models.DB.Model(settings).Update("scheduler_paused", paused).Error; err != nil {
+		utils.LogError(action+"定时更新失败", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": action + "定时更新失败", "error": err.Error()})
+		return
+	}
+
+	utils.LogInfo(fmt.Sprintf("RSS定时更新已%s", action))
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": action + "定时更新成功"})
+}
+
+// @Summary 暂停RSS订阅源的定时更新
+// @Description 暂停指定RSS订阅源的定时更新，手动更新不受影响
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "RSS订阅源ID"
+// @Success 200 {object} RSSResponse{data=models.RSSFeedResponse}
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/rss_feeds/{id}/pause [post]
+func PauseRSSFeed(c *gin.Context) {
+	setRSSFeedPaused(c, true)
+}
+
+// @Summary 恢复RSS订阅源的定时更新
+// @Description 恢复指定RSS订阅源的定时更新，并在下一轮调度时立即运行
+// @Tags RSS订阅源管理
+// @Produce json
+// @Security Bearer
+// @Param id path int true "RSS订阅源ID"
+// @Success 200 {object} RSSResponse{data=models.RSSFeedResponse}
+// @Failure 404 {object} RSSResponse
+// @Failure 500 {object} RSSResponse
+// @Router /admin/rss_feeds/{id}/resume [post]
+func ResumeRSSFeed(c *gin.Context) {
+	setRSSFeedPaused(c, false)
+}
+
+func setRSSFeedPaused(c *gin.Context, paused bool) {
+	id := c.Param("id")
+	action := "恢复"
+	if paused {
+		action = "暂停"
+	}
+
+	var feed models.RSSFeed
+	if err := models.DB.First(&feed, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": fmt.Sprintf("ID为%s的RSS订阅源不存在", id)})
+		} else {
+			utils.LogError(fmt.Sprintf("获取ID为%s的RSS订阅源失败", id), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": action + "RSS订阅源失败", "error": err.Error()})
+		}
+		return
+	}
+
+	updates := map[string]interface{}{"paused": paused}
+	if !paused {
+		// 暂停期间错过的运行在恢复后的下一轮调度中补上
+		updates["next_run_at"] = nil
+	}
+	if err := models.DB.Model(&feed).UpdateColumns(updates).Error; err != nil {
+		utils.LogError(fmt.Sprintf("%sID为%s的RSS订阅源失败", action, id), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": action + "RSS订阅源失败", "error": err.Error()})
+		return
+	}
+	if err := models.DB.First(&feed, id).Error; err != nil {
+		utils.LogError(fmt.Sprintf("获取ID为%s的RSS订阅源失败", id), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": action + "RSS订阅源失败", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": action + "RSS订阅源成功", "data": newRSSFeedResponse(feed)})
+}
@@ -2,6 +2,9 @@ package controllers
 
 import (
 	"backend/models"
+	"backend/services/leader"
+	"backend/services/rss"
+	"backend/utils"
 	"net/http"
 	"time"
 
@@ -35,6 +38,18 @@ type SystemStatus struct {
 	DiskUsage     float64        `json:"diskUsage"`
 	NetworkStatus NetworkMetrics `json:"networkStatus"`
 	Uptime        float64        `json:"uptime"` // 添加系统运行时间字段
+
+	Scheduler SchedulerLeader `json:"scheduler"` // RSS定时更新的主节点
+}
+
+// SchedulerLeader RSS定时更新的主节点信息
+type SchedulerLeader struct {
+	Instance    string     `json:"instance"`    // 当前实例的标识
+	IsLeader    bool       `json:"isLeader"`    // 当前实例是否为主节点
+	Leader      string     `json:"leader"`      // 主节点实例的标识，没有有效租约时为空
+	AcquiredAt  *time.Time `json:"acquiredAt"`  // 主节点获得租约的时间
+	HeartbeatAt *time.Time `json:"heartbeatAt"` // 主节点最近一次续约时间
+	ExpiresAt   *time.Time `json:"expiresAt"`   // 租约到期时间
 }
 
 type NetworkMetrics struct {
@@ -71,7 +86,7 @@ func GetSystemStats(c *gin.Context) {
 
 // GetSystemStatus 获取系统状态信息
 // @Summary 获取系统状态信息
-// @Description 获取系统CPU、内存、磁盘和网络等实时状态信息
+// @Description 获取系统CPU、内存、磁盘和网络等实时状态信息，以及RSS定时更新的主节点
 // @Tags 系统管理
 // @Produce json
 // @Success 200 {object} SystemStatsResponse
@@ -117,6 +132,17 @@ func GetSystemStatus(c *gin.Context) {
 
 	status.NetworkStatus = networkMetrics
 
+	// 获取RSS定时更新的主节点
+	status.Scheduler = SchedulerLeader{Instance: rss.InstanceID(), IsLeader: rss.IsLeader()}
+	if lock, err := leader.Current(models.DB, models.SchedulerLockRSS); err != nil {
+		utils.LogError("获取调度主节点失败", err)
+	} else if lock != nil && lock.ExpiresAt.After(time.Now()) {
+		status.Scheduler.Leader = lock.Owner
+		status.Scheduler.AcquiredAt = &lock.AcquiredAt
+		status.Scheduler.HeartbeatAt = &lock.HeartbeatAt
+		status.Scheduler.ExpiresAt = &lock.ExpiresAt
+	}
+
 	c.JSON(http.StatusOK, SystemStatsResponse{
 		Code:    http.StatusOK,
 		Message: "获取系统状态信息成功",
@@ -8,9 +8,18 @@ import (
 	"backend/migrations"
 	"backend/models"
 	"backend/services/activity"
+	"backend/services/metadata"
 	"backend/services/rss"
 	"backend/utils"
+	"context"
+	"errors"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -18,6 +27,7 @@ import (
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"gorm.io/gorm"
 )
 
 // @title           动画网站 API
@@ -59,6 +69,7 @@ func main() {
 
 	// 运行数据库迁移
 	migrations.UpdateAdminBetaAccess()
+	migrations.ConvertFeedUpdateInterval()
 
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
@@ -179,6 +190,7 @@ func main() {
 				beta.GET("/bangumi/grouped_items/:id", controllers.GetGroupedBangumiRSSItems) // 获取番剧组
 				beta.GET("/bangumi/items/:id", controllers.GetBangumiRSSItems)                // 获取番剧RSS
 				beta.GET("/bangumi/:id/group_episode", controllers.GetGroupEpisodeInfo)       // 获取番剧集数信息
+				beta.GET("/bangumi/:id/metadata", controllers.GetBangumiMetadata)             // 获取番剧元数据
 			}
 
 			// 管理员路由组
@@ -214,6 +226,13 @@ func main() {
 				// 番剧管理路由
 				admin.DELETE("/bangumi/:id", controllers.DeleteBangumi)
 				admin.PUT("/bangumi/:id", controllers.UpdateBangumi)
+				admin.GET("/bangumi/:id/aliases", controllers.GetBangumiAliases)
+				admin.POST("/bangumi/:id/aliases", controllers.CreateBangumiAlias)
+				admin.DELETE("/bangumi_aliases/:id", controllers.DeleteBangumiAlias)
+				admin.POST("/bangumi/:id/merge", controllers.MergeBangumi)
+				admin.POST("/bangumi/:id/split", controllers.SplitBangumi)
+				admin.POST("/bangumi/:id/metadata/link", controllers.LinkBangumiMetadata)
+				admin.POST("/bangumi/:id/metadata/refresh", controllers.RefreshBangumiMetadata)
 
 				// 系统统计和状态路由
 				admin.GET("/stats", controllers.GetSystemStats)
@@ -228,6 +247,35 @@ func main() {
 				v1.DELETE("/rss_feeds/:id", controllers.DeleteRSSFeed)
 				v1.POST("/rss_feeds/update", controllers.ManualUpdateRSSFeeds)
 				v1.POST("/rss_feeds/:id/update", controllers.UpdateRSSFeedByID)
+				admin.GET("/rss_feeds/parser_types", controllers.GetRSSParserTypes)
+				admin.POST("/rss_feeds/preview", controllers.PreviewRSSFeedConfig)
+				admin.POST("/rss_feeds/:id/preview", controllers.PreviewRSSFeedByID)
+				admin.GET("/rss_update_runs", controllers.GetFeedUpdateRuns)
+				admin.GET("/rss_update_runs/:id", controllers.GetFeedUpdateRunByID)
+				admin.GET("/rss_update_jobs", controllers.GetRSSUpdateJobs)
+				admin.GET("/rss_update_jobs/:id", controllers.GetRSSUpdateJob)
+				admin.POST("/rss_update_jobs/:id/cancel", controllers.CancelRSSUpdateJob)
+				admin.POST("/rss_items/reparse", controllers.ReparseRSSItems)
+				admin.GET("/rss_items/reparse/status", controllers.GetReparseStatus)
+				admin.POST("/rss_feeds/:id/pause", controllers.PauseRSSFeed)
+				admin.POST("/rss_feeds/:id/resume", controllers.ResumeRSSFeed)
+				admin.GET("/scheduler", controllers.GetSchedulerStatus)
+				admin.PUT("/scheduler", controllers.UpdateSchedulerSettings)
+				admin.POST("/scheduler/pause", controllers.PauseScheduler)
+				admin.POST("/scheduler/resume", controllers.ResumeScheduler)
+
+				// 筛选规则管理路由
+				admin.GET("/filter_rules", controllers.GetFilterRules)
+				admin.POST("/filter_rules", controllers.CreateFilterRule)
+				admin.PUT("/filter_rules/:id", controllers.UpdateFilterRule)
+				admin.DELETE("/filter_rules/:id", controllers.DeleteFilterRule)
+
+				// 待审核条目路由
+				admin.GET("/review_items", controllers.GetReviewItems)
+				admin.POST("/review_items/:id/assign", controllers.AssignReviewItem)
+				admin.POST("/review_items/:id/discard", controllers.DiscardReviewItem)
+				admin.GET("/title_overrides", controllers.GetTitleOverrides)
+				admin.DELETE("/title_overrides/:id", controllers.DeleteTitleOverride)
 
 				// 活动记录路由
 				admin.GET("/activities", activityController.GetRecentActivities) // Carousel管理路由
@@ -246,7 +294,80 @@ func main() {
 
 	// 初始化RSS定时任务调度器
 	rssScheduler := rss.NewRSSUpdateScheduler(db)
-	go rssScheduler.Start()
+	rssScheduler.Start()
+
+	// 初始化番剧元数据定时刷新
+	metadataRefresher := metadata.NewRefresher(db, metadata.Default(), metadata.LoadConfig().StaleAfter)
+	metadataRefresher.Start()
+
+	// 收到SIGINT/SIGTERM后停止接收新请求，在drain超时内等待进行中的请求和后台任务结束
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 请求上下文派生自baseCtx，drain超时后取消以中止仍在执行的请求（如发送邮件、预览订阅源）
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	srv := &http.Server{
+		Addr:        ":8081",
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		utils.LogInfo("HTTP服务已启动，监听 " + srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case <-ctx.Done():
+		utils.LogInfo("收到退出信号，开始关闭服务")
+	case err := <-serverErr:
+		utils.LogError("HTTP服务异常退出", err)
+	}
+	stop()
+
+	shutdown(srv, cancelRequests, rssScheduler, metadataRefresher, db, shutdownTimeout())
+}
+
+// shutdownTimeout 关闭服务时等待请求和后台任务结束的时长，可通过环境变量SHUTDOWN_TIMEOUT_SECONDS覆盖，默认30秒
+func shutdownTimeout() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("SHUTDOWN_TIMEOUT_SECONDS")); err == nil && v > 0 {
+		return time.Duration(v) * time.Second
+	}
+	return 30 * time.Second
+}
 
-	r.Run(":8081")
+// shutdown 按顺序关闭服务：HTTP请求 -> RSS调度器和元数据刷新 -> 手动更新和重新解析任务 -> WebSocket客户端 -> 数据库 -> 日志文件
+// HTTP请求和后台任务共用同一个drain超时，超时后取消仍在执行的工作
+func shutdown(srv *http.Server, cancelRequests context.CancelFunc, rssScheduler *rss.RSSUpdateScheduler, metadataRefresher *metadata.Refresher, db *gorm.DB, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		utils.LogWarning("等待HTTP请求结束超时，取消剩余请求", err)
+	}
+	cancelRequests()
+
+	rssScheduler.Stop(ctx)
+	metadataRefresher.Stop(ctx)
+	rss.ShutdownUpdateJobs(ctx)
+	rss.ShutdownReparse(ctx)
+	utils.LogInfo("后台任务已停止")
+
+	utils.CloseClients()
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			utils.LogError("关闭数据库连接失败", err)
+		}
+	}
+
+	utils.LogInfo("服务已关闭")
+	if err := utils.CloseLogger(); err != nil {
+		log.Println("关闭日志文件失败:", err)
+	}
 }
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"backend/models"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// ConvertFeedUpdateInterval 将旧版本以秒为单位保存的RSS源更新间隔（默认3600）转换为小时，
+// 向上取整且限制在1到models.MaxFeedUpdateInterval之间，同时清除按旧间隔计算的下次运行时间，由调度器重新计算；
+// 转换完成后在全局设置中记录，之后启动不再执行
+func ConvertFeedUpdateInterval() {
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		log.Printf("获取全局设置失败，跳过更新间隔转换: %v", err)
+		return
+	}
+	if settings.FeedIntervalInHours {
+		return
+	}
+
+	converted := 0
+	err = models.DB.Transaction(func(tx *gorm.DB) error {
+		var feeds []models.RSSFeed
+		if err := tx.Select("id", "update_interval").Find(&feeds).Error; err != nil {
+			return err
+		}
+
+		for _, feed := range feeds {
+			hours := (feed.UpdateInterval + 3599) / 3600
+			if hours < 1 {
+				hours = 1
+			}
+			if hours > models.MaxFeedUpdateInterval {
+				hours = models.MaxFeedUpdateInterval
+			}
+			updates := map[string]interface{}{"update_interval": hours, "next_run_at": nil}
+			if err := tx.Model(&models.RSSFeed{}).Where("id = ?", feed.ID).UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+			converted++
+		}
+
+		return tx.Model(settings).UpdateColumn("feed_interval_in_hours", true).Error
+	})
+	if err != nil {
+		log.Printf("转换RSS源更新间隔失败: %v", err)
+		return
+	}
+
+	log.Printf("成功将 %d 个RSS源的更新间隔从秒转换为小时", converted)
+}
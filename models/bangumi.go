@@ -34,6 +34,20 @@ type BangumiUpdateRequest struct {
 	PosterLink    *string `json:"poster_link"`
 }
 
+// BangumiMergeRequest 合并番剧请求结构体，将SourceID番剧合并到路径中的番剧
+type BangumiMergeRequest struct {
+	SourceID uint `json:"source_id" binding:"required"`
+}
+
+// BangumiSplitRequest 拆分番剧请求结构体，将选中的RSS条目移到新番剧
+type BangumiSplitRequest struct {
+	RSSItemIDs    []uint  `json:"rss_item_ids" binding:"required,min=1"`
+	OfficialTitle string  `json:"official_title" binding:"required"`
+	Year          *string `json:"year"`
+	Season        int     `json:"season"`
+	PosterLink    *string `json:"poster_link"`
+}
+
 // BangumiResponse 响应结构体
 type BangumiResponse struct {
 	ID            uint    `json:"id"`
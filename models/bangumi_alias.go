@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// 别名语言
+const (
+	AliasLanguageZh     = "zh"     // 中文
+	AliasLanguageJa     = "ja"     // 日文
+	AliasLanguageEn     = "en"     // 英文
+	AliasLanguageRomaji = "romaji" // 罗马音
+	AliasLanguageOther  = "other"  // 其他写法
+)
+
+// 别名来源
+const (
+	AliasSourceParser = "parser" // 入库时从标题解析结果自动记录
+	AliasSourceManual = "manual" // 管理员添加
+)
+
+// BangumiAliasRequest 用于 Swagger 文档的番剧别名请求模型
+type BangumiAliasRequest struct {
+	Alias    string `json:"alias" example:"Sousou no Frieren" binding:"required" description:"别名"`
+	Language string `json:"language" example:"romaji" description:"语言(zh/ja/en/romaji/other)，默认other"`
+}
+
+// BangumiAlias 番剧别名，入库时按归一化后的别名匹配已有番剧
+// @Description 番剧别名
+type BangumiAlias struct {
+	ID         uint      `json:"id" gorm:"primarykey" example:"1"`
+	BangumiID  uint      `json:"bangumi_id" gorm:"not null;uniqueIndex:uniq_bangumi_alias" example:"1" description:"关联番剧ID"`
+	Alias      string    `json:"alias" gorm:"type:varchar(255);not null" example:"Sousou no Frieren" description:"别名"`
+	Normalized string    `json:"normalized" gorm:"type:varchar(255);not null;index;uniqueIndex:uniq_bangumi_alias" example:"sousounofrieren" description:"归一化后的别名（全半角、大小写、标点折叠）"`
+	Language   string    `json:"language" gorm:"type:varchar(10);not null;default:'other'" example:"romaji" description:"语言(zh/ja/en/romaji/other)"`
+	Source     string    `json:"source" gorm:"type:varchar(10);not null;default:'parser'" example:"parser" description:"来源(parser/manual)"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (BangumiAlias) TableName() string {
+	return "bangumi_aliases"
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 元数据来源
+const (
+	MetadataProviderBgm = "bgm" // bgm.tv（Bangumi 番组计划）
+)
+
+// 元数据关联方式
+const (
+	MetadataMatchAuto   = "auto"   // 按番剧名、别名和年份自动匹配
+	MetadataMatchManual = "manual" // 管理员指定条目ID，刷新时不再重新匹配
+)
+
+// SchedulerLockMetadata 番剧元数据定时刷新使用的锁名称
+const SchedulerLockMetadata = "metadata_refresh"
+
+// BangumiMetadataLinkRequest 用于 Swagger 文档的关联条目请求模型
+type BangumiMetadataLinkRequest struct {
+	SubjectID string `json:"subject_id" example:"400602" binding:"required" description:"元数据来源中的条目ID"`
+}
+
+// BangumiMetadata 番剧在外部数据源中的条目信息，每个番剧最多一条；
+// SubjectID为空表示自动匹配未找到条目，刷新时会重新尝试
+// @Description 番剧元数据
+type BangumiMetadata struct {
+	ID           uint                   `json:"id" gorm:"primarykey" example:"1"`
+	BangumiID    uint                   `json:"bangumi_id" gorm:"not null;uniqueIndex" example:"1" description:"关联番剧ID"`
+	Provider     string                 `json:"provider" gorm:"type:varchar(20);not null;index:idx_metadata_subject" example:"bgm" description:"元数据来源"`
+	SubjectID    string                 `json:"subject_id" gorm:"type:varchar(32);index:idx_metadata_subject" example:"400602" description:"来源中的条目ID"`
+	MatchedBy    string                 `json:"matched_by" gorm:"type:varchar(10);not null;default:'auto'" example:"auto" description:"关联方式(auto/manual)"`
+	Name         string                 `json:"name" gorm:"type:varchar(255)" example:"葬送のフリーレン" description:"日文原名"`
+	NameCn       string                 `json:"name_cn" gorm:"type:varchar(255)" example:"葬送的芙莉莲" description:"中文名"`
+	Summary      string                 `json:"summary" gorm:"type:text" description:"简介"`
+	AirDate      string                 `json:"air_date" gorm:"type:varchar(10)" example:"2023-09-29" description:"首播日期"`
+	Weekday      int                    `json:"weekday" example:"5" description:"放送星期(1-7，0表示未知)"`
+	EpisodeCount int                    `json:"episode_count" example:"28" description:"总集数"`
+	Score        float64                `json:"score" gorm:"type:decimal(4,2);default:0" example:"8.9" description:"评分"`
+	ScoreCount   int                    `json:"score_count" example:"12000" description:"评分人数"`
+	Tags         []BangumiMetadataTag   `json:"tags" gorm:"foreignKey:MetadataID" description:"标签"`
+	Staff        []BangumiMetadataStaff `json:"staff" gorm:"foreignKey:MetadataID" description:"制作人员"`
+	SyncedAt     *time.Time             `json:"synced_at" gorm:"index" description:"最近一次从来源同步的时间"`
+	SyncError    string                 `json:"sync_error,omitempty" gorm:"type:varchar(500)" description:"最近一次同步失败的原因"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+func (BangumiMetadata) TableName() string {
+	return "bangumi_metadata"
+}
+
+// BangumiMetadataTag 番剧在元数据来源中的标签
+// @Description 番剧标签
+type BangumiMetadataTag struct {
+	ID         uint   `json:"-" gorm:"primarykey"`
+	MetadataID uint   `json:"-" gorm:"not null;index"`
+	Name       string `json:"name" gorm:"type:varchar(100);not null" example:"奇幻" description:"标签"`
+	Count      int    `json:"count" example:"3500" description:"标记人数"`
+}
+
+func (BangumiMetadataTag) TableName() string {
+	return "bangumi_metadata_tags"
+}
+
+// BangumiMetadataStaff 番剧的制作人员
+// @Description 番剧制作人员
+type BangumiMetadataStaff struct {
+	ID         uint   `json:"-" gorm:"primarykey"`
+	MetadataID uint   `json:"-" gorm:"not null;index"`
+	Role       string `json:"role" gorm:"type:varchar(50);not null" example:"导演" description:"职位"`
+	Name       string `json:"name" gorm:"type:varchar(255);not null" example:"斋藤圭一郎" description:"姓名"`
+	PersonID   string `json:"person_id,omitempty" gorm:"type:varchar(32)" example:"12345" description:"来源中的人物ID"`
+}
+
+func (BangumiMetadataStaff) TableName() string {
+	return "bangumi_metadata_staff"
+}
+
+// DeleteBangumiMetadata 删除番剧的元数据及其标签和制作人员
+func DeleteBangumiMetadata(tx *gorm.DB, bangumiID uint) error {
+	metadataIDs := tx.Model(&BangumiMetadata{}).Select("id").Where("bangumi_id = ?", bangumiID)
+	if err := tx.Where("metadata_id IN (?)", metadataIDs).Delete(&BangumiMetadataTag{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("metadata_id IN (?)", metadataIDs).Delete(&BangumiMetadataStaff{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("bangumi_id = ?", bangumiID).Delete(&BangumiMetadata{}).Error
+}
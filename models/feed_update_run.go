@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// RSS更新触发方式
+const (
+	RunTriggerScheduler = "scheduler" // 定时任务
+	RunTriggerManual    = "manual"    // 手动更新全部订阅源
+	RunTriggerSingle    = "single"    // 手动更新单个订阅源
+)
+
+// RSS更新运行状态
+const (
+	RunStatusRunning   = "running"
+	RunStatusSuccess   = "success"
+	RunStatusFailed    = "failed"
+	RunStatusCancelled = "cancelled" // 手动更新任务被取消
+)
+
+// FeedUpdateRun 单个RSS源一次更新的运行记录
+// @Description RSS源更新运行记录及统计
+type FeedUpdateRun struct {
+	ID         uint       `json:"id" gorm:"primarykey" example:"1"`
+	RssID      uint       `json:"rss_id" gorm:"index;not null" example:"1" description:"关联RSS源ID"`
+	FeedName   string     `json:"feed_name" gorm:"type:varchar(255)" example:"莉可丽丝" description:"RSS源名称（记录时）"`
+	Trigger    string     `json:"trigger" gorm:"type:varchar(20);index;not null" example:"scheduler" description:"触发方式(scheduler/manual/single)"`
+	Status     string     `json:"status" gorm:"type:varchar(20);not null" example:"success" description:"运行状态(running/success/failed/cancelled)"`
+	StartedAt  time.Time  `json:"started_at" gorm:"index" description:"开始时间"`
+	FinishedAt *time.Time `json:"finished_at" description:"结束时间"`
+
+	PagesTotal     int `json:"pages_total" description:"分页总数"`
+	PagesFetched   int `json:"pages_fetched" description:"抓取到新内容的分页数"`
+	PagesUnchanged int `json:"pages_unchanged" description:"未变化（304或内容哈希相同）的分页数"`
+	PagesFailed    int `json:"pages_failed" description:"抓取或解析失败的分页数"`
+
+	ItemsSeen        int `json:"items_seen" description:"解析出的条目数"`
+	ItemsExcluded    int `json:"items_excluded" description:"命中排除关键词的条目数"`
+	ItemsBlacklisted int `json:"items_blacklisted" description:"字幕组在黑名单中的条目数"`
+	ItemsSubMismatch int `json:"items_sub_mismatch" description:"字幕不符且未匹配关键词的条目数"`
+	ItemsParseFailed int `json:"items_parse_failed" description:"标题解析失败的条目数"`
+	ItemsDuplicate   int `json:"items_duplicate" description:"已存在的重复条目数"`
+	ItemsCreated     int `json:"items_created" description:"新增条目数"`
+	ItemsFailed      int `json:"items_failed" description:"保存失败的条目数"`
+
+	Error     string    `json:"error" gorm:"type:text" description:"错误信息"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (FeedUpdateRun) TableName() string {
+	return "feed_update_runs"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// FilterRuleRequest 用于 Swagger 文档的筛选规则请求模型
+type FilterRuleRequest struct {
+	RssID       *uint  `json:"rss_id" example:"1" description:"关联RSS源ID，为空表示全局规则"`
+	Type        string `json:"type" example:"exclude" binding:"required" description:"规则类型(include/exclude)"`
+	Field       string `json:"field" example:"raw_title" binding:"required" description:"匹配字段(title/raw_title/official_title/group/resolution/sub/source/episode)"`
+	Mode        string `json:"mode" example:"regex" description:"匹配方式(substring/regex/glob)，集数字段可不填"`
+	Pattern     string `json:"pattern" example:"(?i)\\bPV\\b" binding:"required" description:"匹配内容，集数字段为范围如1-12"`
+	Enabled     *bool  `json:"enabled" example:"true" description:"是否启用，默认启用"`
+	Description string `json:"description" example:"排除PV" description:"备注"`
+}
+
+// FilterRule 筛选规则（数据库模型）
+type FilterRule struct {
+	gorm.Model
+	RssID       *uint  `json:"rss_id" gorm:"index" description:"关联RSS源ID，为空表示全局规则"`
+	Type        string `json:"type" gorm:"type:varchar(20);not null" description:"规则类型(include/exclude)"`
+	Field       string `json:"field" gorm:"type:varchar(30);not null" description:"匹配字段"`
+	Mode        string `json:"mode" gorm:"type:varchar(20);not null;default:'substring'" description:"匹配方式"`
+	Pattern     string `json:"pattern" gorm:"type:varchar(511);not null" description:"匹配内容"`
+	Enabled     bool   `json:"enabled" gorm:"not null;default:true" description:"是否启用"`
+	Description string `json:"description" gorm:"type:varchar(255)" description:"备注"`
+}
+
+func (FilterRule) TableName() string {
+	return "filter_rules"
+}
@@ -10,6 +10,16 @@ type GlobalSettings struct {
 	GlobalKeywords    string `json:"global_keywords" gorm:"type:text" description:"全局关键词"`
 	ExcludeKeywords   string `json:"exclude_keywords" gorm:"type:text" description:"全局排除关键词"`
 	SubGroupBlacklist string `json:"sub_group_blacklist" gorm:"type:text" description:"字幕组黑名单"`
+	SubtitleLanguages string `json:"subtitle_languages" gorm:"type:varchar(255);not null;default:'zh-Hans'" description:"可接受的字幕语言，多个用逗号分隔"`
+
+	// 定时更新设置
+	SchedulerPaused        bool   `json:"scheduler_paused" gorm:"not null;default:false" description:"是否暂停所有RSS源的定时更新"`
+	SchedulerQuietStart    string `json:"scheduler_quiet_start" gorm:"type:varchar(5)" description:"静默时段开始时间（HH:MM），为空表示不设静默时段"`
+	SchedulerQuietEnd      string `json:"scheduler_quiet_end" gorm:"type:varchar(5)" description:"静默时段结束时间（HH:MM），可早于开始时间表示跨午夜"`
+	SchedulerJitterSeconds int    `json:"scheduler_jitter_seconds" gorm:"not null;default:60" description:"下次运行时间的随机延后上限（秒）"`
+
+	// 数据迁移标记
+	FeedIntervalInHours bool `json:"-" gorm:"not null;default:false" description:"RSS源更新间隔是否已从秒转换为小时"`
 
 	// 邮件服务器设置
 	SMTPHost     string `json:"smtp_host" gorm:"type:varchar(255)" description:"SMTP服务器地址"`
@@ -36,6 +46,9 @@ func GetGlobalSettings() (*GlobalSettings, error) {
 				GlobalKeywords:    "",
 				ExcludeKeywords:   "",
 				SubGroupBlacklist: "",
+				SubtitleLanguages: "zh-Hans",
+
+				SchedulerJitterSeconds: 60,
 			}
 			if err := DB.Create(&settings).Error; err != nil {
 				return nil, err
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// 待审核条目状态
+const (
+	ReviewStatusPending   = "pending"   // 待处理
+	ReviewStatusAssigned  = "assigned"  // 已人工指定番剧和集数
+	ReviewStatusDiscarded = "discarded" // 已忽略
+)
+
+// 低置信度原因
+const (
+	ReviewReasonParseFailed   = "parse_failed"   // 标题解析失败
+	ReviewReasonNoName        = "no_name"        // 未能提取番剧名
+	ReviewReasonNoEpisode     = "no_episode"     // 未识别出集数
+	ReviewReasonSeasonUnclear = "season_unclear" // 季度不明确（如Part 2、后篇）
+	ReviewReasonNewBangumi    = "new_bangumi"    // 未匹配到已有番剧，自动创建了新番剧
+)
+
+// ReviewItem 低置信度的入库决定，等待人工确认
+// @Description 待审核条目
+type ReviewItem struct {
+	ID          uint    `json:"id" gorm:"primarykey" example:"1"`
+	RssID       uint    `json:"rss_id" gorm:"index;not null" example:"1" description:"来源RSS源ID"`
+	RawTitle    string  `json:"raw_title" gorm:"type:text;not null" description:"原始标题"`
+	TitleHash   string  `json:"-" gorm:"type:char(64);index;not null" description:"原始标题哈希"`
+	TorrentURL  string  `json:"torrent_url" gorm:"type:varchar(511);index" description:"种子URL"`
+	Homepage    string  `json:"homepage" gorm:"type:varchar(511)" description:"来源页面URL"`
+	ReleaseDate string  `json:"release_date" gorm:"type:varchar(50)" description:"发布日期"`
+	Reasons     string  `json:"reasons" gorm:"type:varchar(255)" example:"no_episode,new_bangumi" description:"低置信度原因，逗号分隔"`
+	Confidence  float64 `json:"confidence" example:"0.6" description:"置信度（0-1）"`
+
+	// 解析器的判断，条目已自动入库时RSSItemID不为空
+	ParsedTitle   string   `json:"parsed_title" gorm:"type:varchar(255)" description:"解析出的番剧名"`
+	ParsedEpisode *float64 `json:"parsed_episode" description:"解析出的集数"`
+	RSSItemID     *uint    `json:"rss_item_id" gorm:"index" description:"已自动入库的RSS条目ID"`
+
+	// 人工处理结果
+	Status     string     `json:"status" gorm:"type:varchar(20);index;not null;default:'pending'" example:"pending" description:"状态(pending/assigned/discarded)"`
+	BangumiID  *uint      `json:"bangumi_id" description:"人工指定的番剧ID"`
+	Episode    *float64   `json:"episode" description:"人工指定的集数"`
+	ResolvedAt *time.Time `json:"resolved_at" description:"处理时间"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ReviewItem) TableName() string {
+	return "review_items"
+}
+
+// TitleOverride 人工处理待审核条目后生成的覆盖规则，之后相同原始标题的条目直接按规则处理
+// @Description 标题覆盖规则
+type TitleOverride struct {
+	ID        uint      `json:"id" gorm:"primarykey" example:"1"`
+	TitleHash string    `json:"-" gorm:"type:char(64);uniqueIndex;not null" description:"原始标题哈希"`
+	RawTitle  string    `json:"raw_title" gorm:"type:text;not null" description:"原始标题"`
+	Discard   bool      `json:"discard" gorm:"not null;default:false" description:"是否忽略该标题"`
+	BangumiID uint      `json:"bangumi_id" description:"指定的番剧ID，忽略时为0"`
+	Episode   *float64  `json:"episode" description:"指定的集数"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (TitleOverride) TableName() string {
+	return "title_overrides"
+}
+
+// TitleHash 计算原始标题的哈希，原始标题可能超过索引长度限制，因此按哈希建立索引
+func TitleHash(rawTitle string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(rawTitle)))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,20 +1,44 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// RSS源健康状态
+const (
+	FeedHealthUnknown  = "unknown"  // 尚未更新过
+	FeedHealthHealthy  = "healthy"  // 最近一次更新成功
+	FeedHealthDegraded = "degraded" // 最近一次更新部分分页失败，或偶发失败
+	FeedHealthFailing  = "failing"  // 连续多次更新失败
+	FeedHealthDisabled = "disabled" // 已停用（手动或连续失败后自动停用）
+)
+
+// FeedFailingThreshold 连续失败达到该次数后健康状态视为failing
+const FeedFailingThreshold = 3
+
+// MaxFeedUpdateInterval 更新间隔的上限（小时，即30天），与RSSFeedRequest的校验保持一致
+const MaxFeedUpdateInterval = 720
+
 // RSSFeedRequest 用于 Swagger 文档的RSS订阅源请求模型
 type RSSFeedRequest struct {
 	Name            string `json:"name" example:"莉可丽丝" binding:"required" description:"RSS源名称"`
 	URL             string `json:"url" example:"https://mikanani.me/RSS/Bangumi?bangumiId=3644" binding:"required" description:"RSS源URL"`
-	UpdateInterval  int    `json:"update_interval" example:"1" binding:"required" description:"更新间隔（小时）"`
+	UpdateInterval  int    `json:"update_interval" example:"1" binding:"required,min=1,max=720" description:"更新间隔（小时，1-720）"`
 	Keywords        string `json:"keywords" example:"莉可丽丝,友谊是时间的窃贼" description:"关键词，多个关键词用逗号分隔"`
 	Priority        int    `json:"priority" example:"0" description:"优先级"`
 	ParserType      string `json:"parser_type" example:"mikanani" binding:"required" description:"解析器类型（mikanani/generic_rss）"`
 	PageStart       *int   `json:"page_start,omitempty" example:"1" description:"分页起始页码（可选）"`
 	PageEnd         *int   `json:"page_end,omitempty" example:"5" description:"分页结束页码（可选）"`
 	ExcludeKeywords string `json:"exclude_keywords" example:"预告,PV" description:"排除关键词，多个用逗号分隔"`
+	Enabled         *bool  `json:"enabled,omitempty" example:"true" description:"是否启用（可选，重新启用时清零连续失败次数）"`
+
+	// 字幕语言偏好，如"zh-Hans,zh-Hant"或"zh-Hans+ja"，为空时使用全局设置
+	SubtitleLanguages string `json:"subtitle_languages" example:"zh-Hans,zh-Hant" description:"可接受的字幕语言，多个用逗号分隔，为空时使用全局设置"`
+
+	// cron表达式，设置后代替更新间隔
+	CronExpr string `json:"cron_expr" example:"*/30 * * * *" description:"cron表达式（分 时 日 月 周），为空时按更新间隔（小时）运行"`
 }
 
 // RSSFeedResponse 用于 Swagger 文档的RSS订阅源响应模型
@@ -31,6 +55,21 @@ type RSSFeedResponse struct {
 	PageStart       *int   `json:"page_start,omitempty" description:"分页起始页码（可选）"`
 	PageEnd         *int   `json:"page_end,omitempty" description:"分页结束页码（可选）"`
 	ExcludeKeywords string `json:"exclude_keywords" description:"排除关键词，多个用逗号分隔"`
+
+	SubtitleLanguages string `json:"subtitle_languages" description:"可接受的字幕语言，为空时使用全局设置"`
+
+	CronExpr     string  `json:"cron_expr" description:"cron表达式，为空时按更新间隔运行"`
+	NextRunAt    *string `json:"next_run_at" description:"下次计划运行时间"`
+	Paused       bool    `json:"paused" description:"是否暂停定时更新"`
+	RunningSince *string `json:"running_since" description:"正在运行的更新开始时间"`
+
+	Enabled             bool    `json:"enabled" description:"是否启用"`
+	HealthStatus        string  `json:"health_status" example:"healthy" description:"健康状态（unknown/healthy/degraded/failing/disabled）"`
+	LastCheckedAt       *string `json:"last_checked_at" description:"最近一次更新时间（无论成功与否）"`
+	LastSuccessAt       *string `json:"last_success_at" description:"最近一次成功更新时间"`
+	LastError           string  `json:"last_error" description:"最近一次更新的错误信息"`
+	ConsecutiveFailures int     `json:"consecutive_failures" description:"连续失败次数"`
+	LastItemsAdded      int     `json:"last_items_added" description:"最近一次更新新增的条目数"`
 }
 
 // RSSFeed RSS订阅源模型（数据库模型）
@@ -38,17 +77,53 @@ type RSSFeed struct {
 	gorm.Model             // 这会自动包含 ID、CreatedAt、UpdatedAt、DeletedAt
 	Name            string `json:"name" gorm:"type:varchar(255);not null" description:"RSS源名称"`
 	URL             string `json:"url" gorm:"type:varchar(511);not null;unique" description:"RSS源URL"`
-	UpdateInterval  int    `json:"update_interval" gorm:"not null;default:3600" description:"更新间隔(秒)"`
+	UpdateInterval  int    `json:"update_interval" gorm:"not null;default:1" description:"更新间隔(小时)"`
 	Keywords        string `json:"keywords" gorm:"type:text" description:"关键词"`
 	Priority        int    `json:"priority" gorm:"not null;default:0" description:"优先级"`
 	ParserType      string `json:"parser_type" gorm:"type:varchar(50);not null;default:'raw'" description:"解析器类型"`
 	PageStart       *int   `json:"page_start" gorm:"default:1" description:"起始页码"`
 	PageEnd         *int   `json:"page_end" gorm:"default:1" description:"结束页码"`
 	ExcludeKeywords string `json:"exclude_keywords" gorm:"type:text" description:"排除关键词"`
+
+	// 字幕语言偏好
+	SubtitleLanguages string `json:"subtitle_languages" gorm:"type:varchar(255)" description:"可接受的字幕语言，为空时使用全局设置"`
+
+	// 调度：设置了cron表达式时按表达式运行，否则按更新间隔（小时）运行
+	CronExpr     string     `json:"cron_expr" gorm:"type:varchar(100)" description:"cron表达式（分 时 日 月 周），为空时按更新间隔运行"`
+	NextRunAt    *time.Time `json:"next_run_at" gorm:"index" description:"下次计划运行时间"`
+	Paused       bool       `json:"paused" gorm:"not null;default:false" description:"是否暂停定时更新（手动更新不受影响）"`
+	RunningSince *time.Time `json:"running_since" description:"正在运行的更新开始时间，用于防止重复运行"`
+
+	// 非主节点实例收到的手动更新请求，由主节点在下一轮调度时执行
+	RunRequestedAt *time.Time `json:"run_requested_at" gorm:"index" description:"待主节点执行的手动更新请求时间"`
+
+	// 健康状态
+	Enabled             bool       `json:"enabled" gorm:"not null;default:true" description:"是否启用"`
+	LastCheckedAt       *time.Time `json:"last_checked_at" description:"最近一次更新时间（无论成功与否）"`
+	LastSuccessAt       *time.Time `json:"last_success_at" description:"最近一次成功更新时间"`
+	LastError           string     `json:"last_error" gorm:"type:text" description:"最近一次更新的错误信息"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null;default:0" description:"连续失败次数"`
+	LastItemsAdded      int        `json:"last_items_added" gorm:"not null;default:0" description:"最近一次更新新增的条目数"`
 	// 可以添加与RSS条目的关联关系
 	// Items []RSSItem `json:"items,omitempty" gorm:"foreignKey:FeedID"`
 }
 
+// HealthStatus 根据最近的更新结果计算健康状态
+func (f RSSFeed) HealthStatus() string {
+	switch {
+	case !f.Enabled:
+		return FeedHealthDisabled
+	case f.LastCheckedAt == nil:
+		return FeedHealthUnknown
+	case f.ConsecutiveFailures >= FeedFailingThreshold:
+		return FeedHealthFailing
+	case f.ConsecutiveFailures > 0 || f.LastError != "":
+		return FeedHealthDegraded
+	default:
+		return FeedHealthHealthy
+	}
+}
+
 // TableName 指定表名
 func (RSSFeed) TableName() string {
 	return "rss_feeds"
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RSSFeedPageCache RSS订阅源分页的抓取缓存，用于条件请求和内容去重
+type RSSFeedPageCache struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	RssID        uint      `json:"rss_id" gorm:"not null;uniqueIndex:idx_rss_page" description:"关联RSS源ID"`
+	PageURL      string    `json:"page_url" gorm:"type:varchar(511);not null;uniqueIndex:idx_rss_page" description:"分页URL"`
+	ETag         string    `json:"etag" gorm:"type:varchar(255)" description:"响应头ETag"`
+	LastModified string    `json:"last_modified" gorm:"type:varchar(100)" description:"响应头Last-Modified"`
+	ContentHash  string    `json:"content_hash" gorm:"type:char(64)" description:"响应内容SHA-256"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (RSSFeedPageCache) TableName() string {
+	return "rss_feed_page_caches"
+}
+
+// ClearRSSFeedPageCache 清除RSS源的分页缓存，使下次更新重新处理全部条目
+func ClearRSSFeedPageCache(db *gorm.DB, rssID uint) error {
+	return db.Where("rss_id = ?", rssID).Delete(&RSSFeedPageCache{}).Error
+}
+
+// ClearAllRSSFeedPageCache 清除所有RSS源的分页缓存，用于全局筛选条件变更后
+func ClearAllRSSFeedPageCache(db *gorm.DB) error {
+	return db.Where("1 = 1").Delete(&RSSFeedPageCache{}).Error
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
 	"gorm.io/gorm"
 )
 
@@ -12,13 +16,37 @@ type RSSItem struct {
 	URL         string   `json:"url" gorm:"type:varchar(511);not null;default:'https://example.com/torrent.torrent'" description:"种子URL"`
 	Homepage    string   `json:"homepage,omitempty" gorm:"type:varchar(511)" description:"主页URL"`
 	Downloaded  bool     `json:"downloaded" gorm:"default:false" description:"下载状态"`
-	Episode     *float64 `json:"episode" description:"集数"`
+	Episode     *float64 `json:"episode" description:"集数，合集为起始集数"`
+	EpisodeEnd  *float64 `json:"episode_end" description:"结束集数，单集与起始集数相同"`
+	ReleaseKind string   `json:"release_kind" gorm:"type:varchar(20);not null;default:'episode';index" description:"发布类型（episode/batch/special/movie）"`
 	Resolution  string   `json:"resolution,omitempty" gorm:"type:varchar(50)" description:"分辨率"`
 	Source      string   `json:"source,omitempty" gorm:"type:varchar(100)" description:"来源"`
 	Group       string   `json:"group,omitempty" gorm:"type:varchar(100)" description:"字幕组"`
 	ReleaseDate string   `json:"release_date,omitempty" gorm:"type:varchar(50)" description:"发布日期"`
 	Sub         string   `json:"sub,omitempty" gorm:"type:varchar(50)" description:"字幕"`
 
+	// 标题解析出的结构化信息
+	SubLang    string `json:"sub_lang,omitempty" gorm:"type:varchar(50);index" description:"字幕语言，如zh-Hans+ja"`
+	SubForm    string `json:"sub_form,omitempty" gorm:"type:varchar(20)" description:"字幕形式（hardsub/softsub）"`
+	VideoCodec string `json:"video_codec,omitempty" gorm:"type:varchar(20)" description:"视频编码"`
+	BitDepth   int    `json:"bit_depth,omitempty" description:"色深"`
+	AudioCodec string `json:"audio_codec,omitempty" gorm:"type:varchar(20)" description:"音频编码"`
+	Container  string `json:"container,omitempty" gorm:"type:varchar(20)" description:"封装格式"`
+	Height     int    `json:"height,omitempty" gorm:"index" description:"视频高度"`
+	Version    int    `json:"version" gorm:"not null;default:1" description:"发布版本"`
+
+	// 原始发布标题，解析器改进后用于重新解析已有条目
+	RawTitle string `json:"raw_title,omitempty" gorm:"type:text" description:"原始发布标题"`
+
+	// 种子元数据，下载或解析失败时为空；InfoHash用于跨RSS源和镜像站去重
+	InfoHash string       `json:"info_hash,omitempty" gorm:"type:char(40);index" description:"种子infohash"`
+	Size     int64        `json:"size,omitempty" description:"文件总大小（字节）"`
+	Files    TorrentFiles `json:"files,omitempty" gorm:"type:mediumtext" description:"种子文件列表"`
+	Magnet   string       `json:"magnet,omitempty" gorm:"type:text" description:"磁力链接"`
+
+	// 被同一发布的新版本（如v2）取代时指向新版本条目
+	SupersededByID *uint `json:"superseded_by_id,omitempty" gorm:"index" description:"取代该条目的新版本条目ID"`
+
 	// 更新外键配置
 	RssFeed RSSFeed `gorm:"foreignKey:RssID;references:ID"`
 	Bangumi Bangumi `gorm:"foreignKey:BangumiID;references:ID"`
@@ -32,3 +60,45 @@ func (RSSItem) TableName() string {
 func (i *RSSItem) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
+
+// TorrentFile 种子中的单个文件
+type TorrentFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// TorrentFiles 种子文件列表，以JSON格式存储
+type TorrentFiles []TorrentFile
+
+// Value 实现driver.Valuer
+func (f TorrentFiles) Value() (driver.Value, error) {
+	if len(f) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现sql.Scanner
+func (f *TorrentFiles) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将%T转换为TorrentFiles", value)
+	}
+	if len(data) == 0 {
+		*f = nil
+		return nil
+	}
+	return json.Unmarshal(data, f)
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// SchedulerLockRSS RSS定时更新使用的锁名称
+const SchedulerLockRSS = "rss_scheduler"
+
+// SchedulerLock 多实例部署时的主节点租约，同一名称同一时间只有一个有效持有者
+// @Description 调度主节点租约
+type SchedulerLock struct {
+	Name        string    `json:"name" gorm:"type:varchar(64);primaryKey" example:"rss_scheduler" description:"锁名称"`
+	Owner       string    `json:"owner" gorm:"type:varchar(255);not null" example:"api-1:1234:9f2c" description:"持有者（实例标识）"`
+	AcquiredAt  time.Time `json:"acquired_at" description:"当前持有者获得租约的时间"`
+	HeartbeatAt time.Time `json:"heartbeat_at" description:"最近一次续约时间"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index" description:"租约到期时间，过期后其他实例可以接管"`
+}
@@ -0,0 +1,165 @@
+package bangumi
+
+import (
+	"backend/models"
+	"backend/utils/parser"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Name 番剧的一个名称及其语言
+type Name struct {
+	Title    string
+	Language string
+}
+
+// romajiRE 罗马音中常见的助词和敬称，用于区分罗马音和英文名
+var romajiRE = regexp.MustCompile(`(?i)(?:^|\s)(?:no|wa|ga|wo|ni|to|de|mo|ka)(?:\s|$)|-(?:san|kun|chan|sama)\b`)
+
+// NormalizeTitle 将名称归一化用于匹配：全角转半角、半角片假名转全角、转小写并去掉空白和标点
+func NormalizeTitle(title string) string {
+	folded := strings.ToLower(width.Fold.String(title))
+
+	var b strings.Builder
+	for _, r := range folded {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DetectLanguage 根据字符判断名称的语言
+func DetectLanguage(title string) string {
+	hasHan, hasLatin := false, false
+	for _, r := range title {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return models.AliasLanguageJa
+		case unicode.Is(unicode.Han, r):
+			hasHan = true
+		case unicode.Is(unicode.Latin, r):
+			hasLatin = true
+		}
+	}
+
+	switch {
+	case hasHan:
+		return models.AliasLanguageZh
+	case hasLatin && romajiRE.MatchString(title):
+		return models.AliasLanguageRomaji
+	case hasLatin:
+		return models.AliasLanguageEn
+	}
+	return models.AliasLanguageOther
+}
+
+// EpisodeNames 汇总番剧名和标题解析出的中日英名称，episode可以为nil
+func EpisodeNames(officialTitle string, episode *parser.Episode) []Name {
+	titles := []string{officialTitle}
+	if episode != nil {
+		titles = append(titles, episode.NameZh, episode.NameJp, episode.NameEn)
+	}
+
+	var names []Name
+	seen := make(map[string]bool)
+	for _, title := range titles {
+		title = strings.TrimSpace(title)
+		normalized := NormalizeTitle(title)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		names = append(names, Name{Title: title, Language: DetectLanguage(title)})
+	}
+	return names
+}
+
+// Resolve 按名称查找已有番剧：先精确匹配番剧名，再按归一化后的别名匹配，未找到时返回nil
+func Resolve(db *gorm.DB, names []Name, season int) (*models.Bangumi, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if season <= 0 {
+		season = 1
+	}
+
+	titles := make([]string, 0, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		titles = append(titles, name.Title)
+		normalized = append(normalized, NormalizeTitle(name.Title))
+	}
+
+	var bangumi models.Bangumi
+	err := db.Where("official_title IN ? AND season = ?", titles, season).Order("id").First(&bangumi).Error
+	if err == nil {
+		return &bangumi, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	err = db.Joins("JOIN bangumi_aliases ON bangumi_aliases.bangumi_id = bangumi.id").
+		Where("bangumi_aliases.normalized IN ? AND bangumi.season = ?", normalized, season).
+		Order("bangumi.id").First(&bangumi).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bangumi, nil
+}
+
+// RecordAliases 记录番剧的名称，已存在的别名忽略
+func RecordAliases(db *gorm.DB, bangumiID uint, names []Name) error {
+	for _, name := range names {
+		alias := models.BangumiAlias{
+			BangumiID:  bangumiID,
+			Alias:      name.Title,
+			Normalized: NormalizeTitle(name.Title),
+			Language:   name.Language,
+			Source:     models.AliasSourceParser,
+		}
+		if alias.Normalized == "" {
+			continue
+		}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alias).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches 检查番剧的名称或别名是否与给定名称之一相同
+func Matches(db *gorm.DB, bangumiID uint, names []Name) (bool, error) {
+	if len(names) == 0 {
+		return false, nil
+	}
+
+	titles := make([]string, 0, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		titles = append(titles, name.Title)
+		normalized = append(normalized, NormalizeTitle(name.Title))
+	}
+
+	var count int64
+	if err := db.Model(&models.Bangumi{}).Where("id = ? AND official_title IN ?", bangumiID, titles).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := db.Model(&models.BangumiAlias{}).Where("bangumi_id = ? AND normalized IN ?", bangumiID, normalized).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,405 @@
+package bangumi
+
+import (
+	"backend/models"
+	"backend/services/activity"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBangumiNotFound  = errors.New("番剧不存在")
+	ErrSameBangumi      = errors.New("不能将番剧合并到自身")
+	ErrBangumiExists    = errors.New("相同名称和季度的番剧已存在")
+	ErrItemsNotInSource = errors.New("部分RSS条目不属于该番剧")
+)
+
+// MergeResult 合并番剧的结果
+type MergeResult struct {
+	Target         models.Bangumi `json:"target"`
+	MovedItems     int64          `json:"moved_items"`
+	DuplicateItems int            `json:"duplicate_items"`
+	MovedFavorites int            `json:"moved_favorites"`
+	MovedRatings   int            `json:"moved_ratings"`
+}
+
+// Merge 将source番剧合并到target：RSS条目、收藏、评分、播放记录和别名改为指向target，
+// 重新计算统计数据后删除source，source的名称记录为target的别名
+func Merge(db *gorm.DB, targetID, sourceID uint) (*MergeResult, error) {
+	if targetID == sourceID {
+		return nil, ErrSameBangumi
+	}
+
+	result := &MergeResult{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var target, source models.Bangumi
+		if err := findBangumi(tx, targetID, &target); err != nil {
+			return err
+		}
+		if err := findBangumi(tx, sourceID, &source); err != nil {
+			return err
+		}
+
+		duplicates, err := mergeDuplicateItems(tx, target.ID, source.ID)
+		if err != nil {
+			return fmt.Errorf("合并重复的RSS条目失败: %w", err)
+		}
+		result.DuplicateItems = duplicates
+
+		moved := tx.Unscoped().Model(&models.RSSItem{}).Where("bangumi_id = ?", source.ID).
+			Updates(map[string]interface{}{"bangumi_id": target.ID, "title": target.OfficialTitle})
+		if moved.Error != nil {
+			return fmt.Errorf("移动RSS条目失败: %w", moved.Error)
+		}
+		result.MovedItems = moved.RowsAffected
+
+		if result.MovedFavorites, err = mergeFavorites(tx, target.ID, source.ID); err != nil {
+			return fmt.Errorf("合并收藏记录失败: %w", err)
+		}
+		if result.MovedRatings, err = mergeRatings(tx, target.ID, source.ID); err != nil {
+			return fmt.Errorf("合并评分记录失败: %w", err)
+		}
+		if err := mergeAliases(tx, target.ID, source); err != nil {
+			return fmt.Errorf("合并番剧别名失败: %w", err)
+		}
+		if err := mergeMetadata(tx, target.ID, source.ID); err != nil {
+			return fmt.Errorf("合并番剧元数据失败: %w", err)
+		}
+
+		// 待审核条目和覆盖规则中人工指定的番剧
+		if err := tx.Model(&models.ReviewItem{}).Where("bangumi_id = ?", source.ID).Update("bangumi_id", target.ID).Error; err != nil {
+			return fmt.Errorf("更新待审核条目失败: %w", err)
+		}
+		if err := tx.Model(&models.TitleOverride{}).Where("bangumi_id = ?", source.ID).Update("bangumi_id", target.ID).Error; err != nil {
+			return fmt.Errorf("更新标题覆盖规则失败: %w", err)
+		}
+
+		if err := tx.Model(&models.Bangumi{}).Where("id = ?", target.ID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", source.ViewCount)).Error; err != nil {
+			return fmt.Errorf("更新点击量失败: %w", err)
+		}
+		if err := RecomputeStats(tx, target.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&source).Error; err != nil {
+			return fmt.Errorf("删除被合并的番剧失败: %w", err)
+		}
+
+		if err := tx.First(&result.Target, target.ID).Error; err != nil {
+			return err
+		}
+
+		return activity.NewActivityService(tx).RecordActivity("bangumi",
+			fmt.Sprintf("番剧 \"%s\"(第%d季) 已合并到 \"%s\"(第%d季)", source.OfficialTitle, source.Season, target.OfficialTitle, target.Season))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Split 将source番剧中选中的RSS条目移到新建的番剧，收藏和评分保留在source
+func Split(db *gorm.DB, sourceID uint, req models.BangumiSplitRequest) (*models.Bangumi, error) {
+	var created models.Bangumi
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var source models.Bangumi
+		if err := findBangumi(tx, sourceID, &source); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.RSSItem{}).Where("id IN ? AND bangumi_id = ?", req.RSSItemIDs, source.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(uniqueIDs(req.RSSItemIDs))) {
+			return ErrItemsNotInSource
+		}
+
+		created = models.Bangumi{
+			OfficialTitle: strings.TrimSpace(req.OfficialTitle),
+			Year:          req.Year,
+			Season:        req.Season,
+			Source:        source.Source,
+			PosterLink:    req.PosterLink,
+		}
+		if created.Season <= 0 {
+			created.Season = source.Season
+		}
+		if created.Year == nil {
+			created.Year = source.Year
+		}
+
+		if err := tx.Model(&models.Bangumi{}).Where("official_title = ? AND season = ?", created.OfficialTitle, created.Season).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrBangumiExists
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			return fmt.Errorf("创建番剧失败: %w", err)
+		}
+
+		if err := tx.Model(&models.RSSItem{}).Where("id IN ?", req.RSSItemIDs).
+			Updates(map[string]interface{}{"bangumi_id": created.ID, "title": created.OfficialTitle}).Error; err != nil {
+			return fmt.Errorf("移动RSS条目失败: %w", err)
+		}
+
+		// 新旧版本分属两个番剧后不再互相取代
+		var remainingIDs []uint
+		if err := tx.Model(&models.RSSItem{}).Where("bangumi_id = ?", source.ID).Pluck("id", &remainingIDs).Error; err != nil {
+			return err
+		}
+		if len(remainingIDs) > 0 {
+			if err := tx.Model(&models.RSSItem{}).
+				Where("(id IN ? AND superseded_by_id IN ?) OR (id IN ? AND superseded_by_id IN ?)",
+					req.RSSItemIDs, remainingIDs, remainingIDs, req.RSSItemIDs).
+				Update("superseded_by_id", nil).Error; err != nil {
+				return fmt.Errorf("更新版本关系失败: %w", err)
+			}
+		}
+
+		if err := tx.Model(&models.ReviewItem{}).Where("rss_item_id IN ? AND bangumi_id = ?", req.RSSItemIDs, source.ID).
+			Update("bangumi_id", created.ID).Error; err != nil {
+			return fmt.Errorf("更新待审核条目失败: %w", err)
+		}
+
+		if err := RecordAliases(tx, created.ID, EpisodeNames(created.OfficialTitle, nil)); err != nil {
+			return fmt.Errorf("记录番剧别名失败: %w", err)
+		}
+
+		return activity.NewActivityService(tx).RecordActivity("bangumi",
+			fmt.Sprintf("从番剧 \"%s\" 拆分出 \"%s\"(第%d季)，移动%d个RSS条目", source.OfficialTitle, created.OfficialTitle, created.Season, len(req.RSSItemIDs)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// RecomputeStats 根据收藏和评分记录重新计算番剧的收藏量、平均分和评分人数
+func RecomputeStats(db *gorm.DB, bangumiID uint) error {
+	var favoriteCount int64
+	if err := db.Model(&models.BangumiFavorite{}).Where("bangumi_id = ?", bangumiID).Count(&favoriteCount).Error; err != nil {
+		return fmt.Errorf("计算收藏量失败: %w", err)
+	}
+
+	var avgScore sql.NullFloat64
+	var ratingCount int64
+	if err := db.Model(&models.BangumiRating{}).Where("bangumi_id = ?", bangumiID).
+		Select("ROUND(AVG(score), 2), COUNT(*)").Row().Scan(&avgScore, &ratingCount); err != nil {
+		return fmt.Errorf("计算评分统计失败: %w", err)
+	}
+
+	return db.Model(&models.Bangumi{}).Where("id = ?", bangumiID).Updates(map[string]interface{}{
+		"favorite_count": favoriteCount,
+		"rating_avg":     avgScore.Float64,
+		"rating_count":   ratingCount,
+	}).Error
+}
+
+// findBangumi 查询番剧，不存在时返回ErrBangumiNotFound
+func findBangumi(db *gorm.DB, id uint, bangumi *models.Bangumi) error {
+	err := db.First(bangumi, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: ID为%d", ErrBangumiNotFound, id)
+	}
+	return err
+}
+
+// mergeDuplicateItems 删除source中与target种子URL相同的重复条目，播放记录等引用改为指向target中的条目
+func mergeDuplicateItems(tx *gorm.DB, targetID, sourceID uint) (int, error) {
+	var pairs []struct {
+		DupID  uint
+		KeepID uint
+	}
+	if err := tx.Table("rss_items AS s").
+		Select("s.id AS dup_id, MIN(t.id) AS keep_id").
+		Joins("JOIN rss_items AS t ON t.url = s.url AND t.bangumi_id = ? AND t.deleted_at IS NULL", targetID).
+		Where("s.bangumi_id = ? AND s.deleted_at IS NULL", sourceID).
+		Group("s.id").Scan(&pairs).Error; err != nil {
+		return 0, err
+	}
+
+	for _, pair := range pairs {
+		if err := movePlayHistory(tx, pair.DupID, pair.KeepID); err != nil {
+			return 0, err
+		}
+		if err := tx.Model(&models.RSSItem{}).Where("superseded_by_id = ?", pair.DupID).Update("superseded_by_id", pair.KeepID).Error; err != nil {
+			return 0, err
+		}
+		if err := tx.Model(&models.ReviewItem{}).Where("rss_item_id = ?", pair.DupID).Update("rss_item_id", pair.KeepID).Error; err != nil {
+			return 0, err
+		}
+		if err := tx.Unscoped().Delete(&models.RSSItem{}, pair.DupID).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(pairs), nil
+}
+
+// movePlayHistory 将播放记录从一个RSS条目移到另一个，同一用户两边都有记录时保留较新的一条
+func movePlayHistory(tx *gorm.DB, fromItemID, toItemID uint) error {
+	var histories []models.PlayHistory
+	if err := tx.Unscoped().Where("rss_items_id = ?", fromItemID).Find(&histories).Error; err != nil {
+		return err
+	}
+
+	for _, history := range histories {
+		var existing models.PlayHistory
+		err := tx.Unscoped().Where("user_id = ? AND rss_items_id = ?", history.UserId, toItemID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Unscoped().Model(&history).Update("rss_items_id", toItemID).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if history.UpdatedAt.After(existing.UpdatedAt) {
+			if err := tx.Unscoped().Model(&existing).UpdateColumns(map[string]interface{}{
+				"updated_at": history.UpdatedAt,
+				"deleted_at": history.DeletedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Delete(&history).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeFavorites 将收藏记录移到target，同一用户两边都收藏过时只保留一条
+func mergeFavorites(tx *gorm.DB, targetID, sourceID uint) (int, error) {
+	var favorites []models.BangumiFavorite
+	if err := tx.Unscoped().Where("bangumi_id = ?", sourceID).Find(&favorites).Error; err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, favorite := range favorites {
+		var existing models.BangumiFavorite
+		err := tx.Unscoped().Where("user_id = ? AND bangumi_id = ?", favorite.UserID, targetID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Unscoped().Model(&favorite).Update("bangumi_id", targetID).Error; err != nil {
+				return 0, err
+			}
+			moved++
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		// target上的收藏已取消而source上仍在收藏时恢复
+		if existing.DeletedAt.Valid && !favorite.DeletedAt.Valid {
+			if err := tx.Unscoped().Model(&existing).Update("deleted_at", nil).Error; err != nil {
+				return 0, err
+			}
+		}
+		if err := tx.Unscoped().Delete(&favorite).Error; err != nil {
+			return 0, err
+		}
+	}
+	return moved, nil
+}
+
+// mergeRatings 将评分记录移到target，同一用户两边都评过分时保留较新的评分
+func mergeRatings(tx *gorm.DB, targetID, sourceID uint) (int, error) {
+	var ratings []models.BangumiRating
+	if err := tx.Unscoped().Where("bangumi_id = ?", sourceID).Find(&ratings).Error; err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, rating := range ratings {
+		var existing models.BangumiRating
+		err := tx.Unscoped().Where("user_id = ? AND bangumi_id = ?", rating.UserID, targetID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Unscoped().Model(&rating).Update("bangumi_id", targetID).Error; err != nil {
+				return 0, err
+			}
+			moved++
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		newer := rating.UpdatedAt.After(existing.UpdatedAt)
+		if !rating.DeletedAt.Valid && (existing.DeletedAt.Valid || newer) {
+			if err := tx.Unscoped().Model(&existing).Updates(map[string]interface{}{
+				"score":      rating.Score,
+				"comment":    rating.Comment,
+				"deleted_at": nil,
+			}).Error; err != nil {
+				return 0, err
+			}
+		}
+		if err := tx.Unscoped().Delete(&rating).Error; err != nil {
+			return 0, err
+		}
+	}
+	return moved, nil
+}
+
+// mergeAliases 将source的别名移到target，并将source的名称记录为target的别名
+func mergeAliases(tx *gorm.DB, targetID uint, source models.Bangumi) error {
+	var existing []string
+	if err := tx.Model(&models.BangumiAlias{}).Where("bangumi_id = ?", targetID).Pluck("normalized", &existing).Error; err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		if err := tx.Where("bangumi_id = ? AND normalized IN ?", source.ID, existing).Delete(&models.BangumiAlias{}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&models.BangumiAlias{}).Where("bangumi_id = ?", source.ID).Update("bangumi_id", targetID).Error; err != nil {
+		return err
+	}
+
+	names := EpisodeNames(source.OfficialTitle, nil)
+	return RecordAliases(tx, targetID, names)
+}
+
+// mergeMetadata target尚未关联元数据条目时沿用source的元数据，否则删除source的元数据
+func mergeMetadata(tx *gorm.DB, targetID, sourceID uint) error {
+	var linked int64
+	if err := tx.Model(&models.BangumiMetadata{}).Where("bangumi_id = ? AND subject_id <> ''", targetID).Count(&linked).Error; err != nil {
+		return err
+	}
+	if linked == 0 {
+		var sourceLinked int64
+		if err := tx.Model(&models.BangumiMetadata{}).Where("bangumi_id = ? AND subject_id <> ''", sourceID).Count(&sourceLinked).Error; err != nil {
+			return err
+		}
+		if sourceLinked > 0 {
+			if err := models.DeleteBangumiMetadata(tx, targetID); err != nil {
+				return err
+			}
+			return tx.Model(&models.BangumiMetadata{}).Where("bangumi_id = ?", sourceID).Update("bangumi_id", targetID).Error
+		}
+	}
+	return models.DeleteBangumiMetadata(tx, sourceID)
+}
+
+// uniqueIDs 去除重复的ID
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
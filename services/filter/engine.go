@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"strings"
+)
+
+// Engine 筛选规则引擎，创建后只读，可在多个协程间共享
+type Engine struct {
+	excludes []*compiledRule
+	includes []*compiledRule
+}
+
+// NewEngine 编译规则并创建引擎，任一规则无效时返回错误
+func NewEngine(rules []Rule) (*Engine, error) {
+	engine := &Engine{}
+	for _, rule := range rules {
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		if compiled.Type == TypeExclude {
+			engine.excludes = append(engine.excludes, compiled)
+		} else {
+			engine.includes = append(engine.includes, compiled)
+		}
+	}
+	return engine, nil
+}
+
+// MatchExclude 返回第一条命中的排除规则
+func (e *Engine) MatchExclude(subject Subject) (Rule, bool) {
+	return firstMatch(e.excludes, subject)
+}
+
+// MatchInclude 返回第一条命中的包含规则
+func (e *Engine) MatchInclude(subject Subject) (Rule, bool) {
+	return firstMatch(e.includes, subject)
+}
+
+func firstMatch(rules []*compiledRule, subject Subject) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.matches(subject) {
+			return rule.Rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// KeywordRules 将逗号分隔的旧关键词转换为标题子串规则
+func KeywordRules(ruleType string, lists ...string) []Rule {
+	var rules []Rule
+	for _, list := range lists {
+		for _, keyword := range strings.Split(list, ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				rules = append(rules, Rule{Type: ruleType, Field: FieldTitle, Mode: ModeSubstring, Pattern: keyword})
+			}
+		}
+	}
+	return rules
+}
@@ -0,0 +1,212 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 规则类型
+const (
+	TypeInclude = "include" // 包含规则：字幕不符合要求时，命中即收录
+	TypeExclude = "exclude" // 排除规则：命中即跳过，优先级高于包含规则
+)
+
+// 匹配字段
+const (
+	FieldTitle         = "title"          // 原始标题或番剧名（兼容旧的逗号分隔关键词）
+	FieldRawTitle      = "raw_title"      // 原始发布标题
+	FieldOfficialTitle = "official_title" // 番剧名
+	FieldGroup         = "group"          // 字幕组
+	FieldResolution    = "resolution"     // 分辨率
+	FieldSub           = "sub"            // 字幕信息
+	FieldSource        = "source"         // 来源
+	FieldEpisode       = "episode"        // 集数范围，如"5"、"1-12"、"13-"
+)
+
+// 匹配方式
+const (
+	ModeSubstring = "substring" // 子串匹配，不区分大小写
+	ModeRegex     = "regex"     // 正则表达式，可使用(?i)忽略大小写
+	ModeGlob      = "glob"      // 通配符，*匹配任意字符，?匹配单个字符，不区分大小写
+)
+
+var (
+	validTypes  = map[string]bool{TypeInclude: true, TypeExclude: true}
+	validFields = map[string]bool{
+		FieldTitle: true, FieldRawTitle: true, FieldOfficialTitle: true, FieldGroup: true,
+		FieldResolution: true, FieldSub: true, FieldSource: true, FieldEpisode: true,
+	}
+)
+
+// Rule 筛选规则
+type Rule struct {
+	ID      uint   // 数据库规则ID，由旧关键词生成的规则为0
+	Type    string // 规则类型
+	Field   string // 匹配字段
+	Mode    string // 匹配方式，集数字段忽略该项
+	Pattern string // 匹配内容
+}
+
+// String 规则的可读描述，用于日志和预览
+func (r Rule) String() string {
+	if r.ID != 0 {
+		return fmt.Sprintf("#%d %s %s:%s", r.ID, r.Field, r.Mode, r.Pattern)
+	}
+	return fmt.Sprintf("%s %s:%s", r.Field, r.Mode, r.Pattern)
+}
+
+// Subject 参与匹配的条目信息
+type Subject struct {
+	RawTitle      string
+	OfficialTitle string
+	Group         string
+	Resolution    string
+	Sub           string
+	Source        string
+	Episode       float64
+	HasEpisode    bool // 是否解析出了集数，未解析出集数时集数规则不匹配
+}
+
+// compiledRule 预编译的规则
+type compiledRule struct {
+	Rule
+	match func(string) bool
+	// 集数范围，episodeMax为负数表示无上限
+	episodeMin, episodeMax float64
+}
+
+// Validate 校验规则配置
+func Validate(rule Rule) error {
+	_, err := compile(rule)
+	return err
+}
+
+// compile 校验并预编译规则
+func compile(rule Rule) (*compiledRule, error) {
+	if !validTypes[rule.Type] {
+		return nil, fmt.Errorf("不支持的规则类型: %s", rule.Type)
+	}
+	if !validFields[rule.Field] {
+		return nil, fmt.Errorf("不支持的匹配字段: %s", rule.Field)
+	}
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return nil, fmt.Errorf("匹配内容不能为空")
+	}
+
+	compiled := &compiledRule{Rule: rule}
+	if rule.Field == FieldEpisode {
+		lo, hi, err := parseEpisodeRange(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled.episodeMin, compiled.episodeMax = lo, hi
+		return compiled, nil
+	}
+
+	switch rule.Mode {
+	case ModeSubstring:
+		pattern := strings.ToLower(rule.Pattern)
+		compiled.match = func(s string) bool { return strings.Contains(strings.ToLower(s), pattern) }
+	case ModeRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式无效: %v", err)
+		}
+		compiled.match = re.MatchString
+	case ModeGlob:
+		re, err := regexp.Compile(globToRegexp(rule.Pattern))
+		if err != nil {
+			return nil, fmt.Errorf("通配符无效: %v", err)
+		}
+		compiled.match = re.MatchString
+	default:
+		return nil, fmt.Errorf("不支持的匹配方式: %s", rule.Mode)
+	}
+	return compiled, nil
+}
+
+// matches 检查条目是否命中规则
+func (r *compiledRule) matches(subject Subject) bool {
+	switch r.Field {
+	case FieldEpisode:
+		if !subject.HasEpisode {
+			return false
+		}
+		return subject.Episode >= r.episodeMin && (r.episodeMax < 0 || subject.Episode <= r.episodeMax)
+	case FieldTitle:
+		return r.matchText(subject.RawTitle) || r.matchText(subject.OfficialTitle)
+	case FieldRawTitle:
+		return r.matchText(subject.RawTitle)
+	case FieldOfficialTitle:
+		return r.matchText(subject.OfficialTitle)
+	case FieldGroup:
+		return r.matchText(subject.Group)
+	case FieldResolution:
+		return r.matchText(subject.Resolution)
+	case FieldSub:
+		return r.matchText(subject.Sub)
+	case FieldSource:
+		return r.matchText(subject.Source)
+	}
+	return false
+}
+
+// matchText 空字段不参与匹配，避免".*"之类的规则命中缺失的信息
+func (r *compiledRule) matchText(s string) bool {
+	return s != "" && r.match(s)
+}
+
+// globToRegexp 将通配符转换为整串匹配的正则表达式
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, ch := range glob {
+		switch ch {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// parseEpisodeRange 解析集数范围："5"、"1-12"、"13-"、"-12"
+func parseEpisodeRange(pattern string) (float64, float64, error) {
+	pattern = strings.TrimSpace(pattern)
+	invalid := fmt.Errorf("集数范围无效: %s", pattern)
+
+	if !strings.Contains(pattern, "-") {
+		n, err := strconv.ParseFloat(pattern, 64)
+		if err != nil {
+			return 0, 0, invalid
+		}
+		return n, n, nil
+	}
+
+	parts := strings.SplitN(pattern, "-", 2)
+	lo, hi := 0.0, -1.0
+	if s := strings.TrimSpace(parts[0]); s != "" {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, 0, invalid
+		}
+		lo = n
+	}
+	if s := strings.TrimSpace(parts[1]); s != "" {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, 0, invalid
+		}
+		hi = n
+	}
+	if hi >= 0 && hi < lo {
+		return 0, 0, invalid
+	}
+	return lo, hi, nil
+}
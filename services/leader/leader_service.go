@@ -0,0 +1,179 @@
+package leader
+
+import (
+	"backend/models"
+	"backend/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Elector 基于数据库租约的主节点选举：持有者定期续约，租约过期后其他实例可以接管。
+// 只使用普通的INSERT和带条件的UPDATE，MySQL和SQLite都可以使用
+type Elector struct {
+	db    *gorm.DB
+	name  string
+	owner string
+	ttl   time.Duration
+
+	leader   atomic.Bool
+	mu       sync.Mutex
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// NewElector 创建选举器，owner为当前实例的标识，ttl为租约时长
+func NewElector(db *gorm.DB, name, owner string, ttl time.Duration) *Elector {
+	return &Elector{db: db, name: name, owner: owner, ttl: ttl}
+}
+
+// DefaultOwner 生成当前实例的标识：主机名:进程号:随机后缀，避免同一主机上的多个进程冲突
+func DefaultOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	}
+	return fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Owner 当前实例的标识
+func (e *Elector) Owner() string {
+	return e.owner
+}
+
+// IsLeader 当前实例是否持有有效的租约
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// TryAcquire 尝试获得或续约租约，返回当前实例是否为主节点
+func (e *Elector) TryAcquire(now time.Time) (bool, error) {
+	leader, err := e.tryAcquire(now)
+	if err != nil {
+		// 无法确认租约状态时按失去主节点处理，宁可暂停调度也不重复运行
+		e.setLeader(false)
+		return false, err
+	}
+	e.setLeader(leader)
+	return leader, nil
+}
+
+func (e *Elector) tryAcquire(now time.Time) (bool, error) {
+	expiresAt := now.Add(e.ttl)
+
+	// 锁记录不存在时创建，已存在则忽略
+	lock := models.SchedulerLock{Name: e.name, Owner: e.owner, AcquiredAt: now, HeartbeatAt: now, ExpiresAt: expiresAt}
+	if err := e.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&lock).Error; err != nil {
+		return false, fmt.Errorf("创建锁记录失败: %w", err)
+	}
+
+	// 自己持有时续约
+	renewed := e.db.Model(&models.SchedulerLock{}).
+		Where("name = ? AND owner = ?", e.name, e.owner).
+		Updates(map[string]interface{}{"heartbeat_at": now, "expires_at": expiresAt})
+	if renewed.Error != nil {
+		return false, fmt.Errorf("续约失败: %w", renewed.Error)
+	}
+
+	// 其他实例的租约已过期时接管
+	if renewed.RowsAffected == 0 {
+		takeover := e.db.Model(&models.SchedulerLock{}).
+			Where("name = ? AND owner <> ? AND expires_at < ?", e.name, e.owner, now).
+			Updates(map[string]interface{}{"owner": e.owner, "acquired_at": now, "heartbeat_at": now, "expires_at": expiresAt})
+		if takeover.Error != nil {
+			return false, fmt.Errorf("接管租约失败: %w", takeover.Error)
+		}
+	}
+
+	// MySQL在值未变化时影响行数为0，以重新读取的持有者为准
+	var current models.SchedulerLock
+	if err := e.db.Where("name = ?", e.name).First(&current).Error; err != nil {
+		return false, fmt.Errorf("读取锁记录失败: %w", err)
+	}
+	return current.Owner == e.owner && current.ExpiresAt.After(now), nil
+}
+
+// setLeader 更新主节点状态，状态变化时记录日志
+func (e *Elector) setLeader(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+	if leader {
+		utils.LogInfo(fmt.Sprintf("实例 %s 成为 %s 的主节点", e.owner, e.name))
+	} else {
+		utils.LogInfo(fmt.Sprintf("实例 %s 不再是 %s 的主节点", e.owner, e.name))
+	}
+}
+
+// Release 主动释放租约，便于其他实例立即接管
+func (e *Elector) Release() error {
+	e.setLeader(false)
+	return e.db.Where("name = ? AND owner = ?", e.name, e.owner).Delete(&models.SchedulerLock{}).Error
+}
+
+// Start 在后台按interval续约或尝试获得租约，interval应明显小于租约时长
+func (e *Elector) Start(interval time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopChan != nil {
+		return
+	}
+	e.stopChan = make(chan struct{})
+	e.done = make(chan struct{})
+
+	go func(stopChan <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := e.TryAcquire(time.Now()); err != nil {
+				utils.LogError(fmt.Sprintf("实例 %s 获取 %s 租约失败", e.owner, e.name), err)
+			}
+			select {
+			case <-ticker.C:
+			case <-stopChan:
+				return
+			}
+		}
+	}(e.stopChan, e.done)
+}
+
+// Stop 停止续约并释放租约
+func (e *Elector) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopChan == nil {
+		return
+	}
+	close(e.stopChan)
+	<-e.done
+	e.stopChan = nil
+
+	if err := e.Release(); err != nil {
+		utils.LogError(fmt.Sprintf("实例 %s 释放 %s 租约失败", e.owner, e.name), err)
+	}
+}
+
+// Current 获取指定锁当前的持有情况，不存在时返回nil
+func Current(db *gorm.DB, name string) (*models.SchedulerLock, error) {
+	var lock models.SchedulerLock
+	err := db.Where("name = ?", name).First(&lock).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
@@ -3,6 +3,7 @@ package mail
 import (
 	"backend/config"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
 	"html/template"
@@ -50,6 +51,24 @@ func (s *MailService) preventDuplicateSend(mailKey string) bool {
 	return !loaded
 }
 
+// sendWithRetry 发送邮件，对特定错误重试一次；ctx取消后不再发起新的尝试
+func (s *MailService) sendWithRetry(ctx context.Context, e *email.Email) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := s.sendMailInternal(e)
+	if err != nil && s.shouldRetry(err) {
+		// 仅对特定错误进行一次重试
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		err = s.sendMailInternal(e)
+	}
+	return err
+}
+
 // sendMailInternal 内部邮件发送函数
 func (s *MailService) sendMailInternal(e *email.Email) error {
 	addr := fmt.Sprintf("%s:%d", s.config.Mail.Host, s.config.Mail.Port)
@@ -83,7 +102,7 @@ func (s *MailService) sendMailInternal(e *email.Email) error {
 }
 
 // SendMail 发送邮件
-func (s *MailService) SendMail(to string, subject string, content string) error {
+func (s *MailService) SendMail(ctx context.Context, to string, subject string, content string) error {
 	mailKey := fmt.Sprintf("%s_%s_%s", to, subject, content[:20])
 	if !s.preventDuplicateSend(mailKey) {
 		return fmt.Errorf("检测到重复发送请求，已跳过")
@@ -95,12 +114,7 @@ func (s *MailService) SendMail(to string, subject string, content string) error
 	e.Subject = subject
 	e.HTML = []byte(content)
 
-	err := s.sendMailInternal(e)
-	if err != nil && s.shouldRetry(err) {
-		// 仅对特定错误进行一次重试
-		time.Sleep(2 * time.Second)
-		err = s.sendMailInternal(e)
-	}
+	err := s.sendWithRetry(ctx, e)
 	if isShortResponseError(err) {
 		// 记录警告，但视为成功
 		fmt.Printf("[邮件警告] 发送成功但响应异常: %v\n", err)
@@ -113,19 +127,14 @@ func (s *MailService) SendMail(to string, subject string, content string) error
 }
 
 // SendHTMLMail 发送HTML格式邮件
-func (s *MailService) SendHTMLMail(to []string, subject, htmlContent string) error {
+func (s *MailService) SendHTMLMail(ctx context.Context, to []string, subject, htmlContent string) error {
 	e := email.NewEmail()
 	e.From = fmt.Sprintf("%s <%s>", s.config.Mail.FromName, s.config.Mail.FromAddress)
 	e.To = to
 	e.Subject = subject
 	e.HTML = []byte(htmlContent)
 
-	err := s.sendMailInternal(e)
-	if err != nil && s.shouldRetry(err) {
-		// 仅对特定错误进行一次重试
-		time.Sleep(2 * time.Second)
-		err = s.sendMailInternal(e)
-	}
+	err := s.sendWithRetry(ctx, e)
 	if isShortResponseError(err) {
 		fmt.Printf("[邮件警告] 发送成功但响应异常: %v\n", err)
 		return nil
@@ -137,7 +146,7 @@ func (s *MailService) SendHTMLMail(to []string, subject, htmlContent string) err
 }
 
 // SendInvitationCode 发送邀请码邮件
-func (s *MailService) SendInvitationCode(to string, code string, expiresAt *time.Time) error {
+func (s *MailService) SendInvitationCode(ctx context.Context, to string, code string, expiresAt *time.Time) error {
 	// 邀请码邮件模板
 	const invitationTemplate = `
 	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
@@ -191,13 +200,16 @@ func (s *MailService) SendInvitationCode(to string, code string, expiresAt *time
 	}
 
 	// 发送邮件
-	return s.SendMail(to, "您的邀请码已就绪", body.String())
+	return s.SendMail(ctx, to, "您的邀请码已就绪", body.String())
 }
 
 // SendCustomMail 发送自定义邮件
-func (s *MailService) SendCustomMail(to []string, subject string, content string, isHTML bool) error {
+func (s *MailService) SendCustomMail(ctx context.Context, to []string, subject string, content string, isHTML bool) error {
 	if isHTML {
-		return s.SendHTMLMail(to, subject, content)
+		return s.SendHTMLMail(ctx, to, subject, content)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
 	e := email.NewEmail()
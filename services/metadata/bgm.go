@@ -0,0 +1,250 @@
+package metadata
+
+import (
+	"backend/models"
+	"backend/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBgmBaseURL bgm.tv API地址
+	DefaultBgmBaseURL = "https://api.bgm.tv"
+
+	bgmSubjectTypeAnime = 2   // 条目类型：动画
+	bgmSearchLimit      = 10  // 每次搜索返回的候选条目数
+	bgmStaffLimit       = 100 // 保存的制作人员上限
+	bgmMaxResponseSize  = 4 << 20
+)
+
+// bgmWeekdays 信息框中放送星期的写法
+var bgmWeekdays = map[string]int{
+	"星期一": 1, "星期二": 2, "星期三": 3, "星期四": 4, "星期五": 5, "星期六": 6, "星期日": 7, "星期天": 7,
+	"周一": 1, "周二": 2, "周三": 3, "周四": 4, "周五": 5, "周六": 6, "周日": 7, "周天": 7,
+}
+
+// BgmProvider bgm.tv（Bangumi 番组计划）v0 API
+type BgmProvider struct {
+	baseURL string
+	token   string
+	client  *utils.OutboundClient
+}
+
+// NewBgmProvider 创建bgm.tv元数据来源，baseURL为空时使用官方地址，token为空时匿名访问；
+// 请求经由出站客户端发送，遵守按主机的限速
+func NewBgmProvider(baseURL, token string, client *utils.OutboundClient) *BgmProvider {
+	if baseURL == "" {
+		baseURL = DefaultBgmBaseURL
+	}
+	if client == nil {
+		client = utils.Outbound()
+	}
+	return &BgmProvider{baseURL: strings.TrimRight(baseURL, "/"), token: token, client: client}
+}
+
+// Name 来源名称
+func (p *BgmProvider) Name() string {
+	return models.MetadataProviderBgm
+}
+
+// bgmSubject v0 API中的条目，搜索结果只包含部分字段
+type bgmSubject struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	NameCn        string `json:"name_cn"`
+	Summary       string `json:"summary"`
+	Date          string `json:"date"`
+	Eps           int    `json:"eps"`
+	TotalEpisodes int    `json:"total_episodes"`
+	Rating        struct {
+		Score float64 `json:"score"`
+		Total int     `json:"total"`
+	} `json:"rating"`
+	Tags []struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	} `json:"tags"`
+	Infobox []struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	} `json:"infobox"`
+}
+
+// bgmPerson 条目关联的人物
+type bgmPerson struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Relation string `json:"relation"`
+}
+
+// Search 按关键词搜索动画条目
+func (p *BgmProvider) Search(ctx context.Context, keyword string) ([]Subject, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"keyword": keyword,
+		"filter":  map[string]interface{}{"type": []int{bgmSubjectTypeAnime}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []bgmSubject `json:"data"`
+	}
+	endpoint := fmt.Sprintf("%s/v0/search/subjects?limit=%d", p.baseURL, bgmSearchLimit)
+	if err := p.do(ctx, http.MethodPost, endpoint, body, &result); err != nil {
+		return nil, fmt.Errorf("搜索bgm条目失败: %w", err)
+	}
+
+	subjects := make([]Subject, 0, len(result.Data))
+	for _, item := range result.Data {
+		subjects = append(subjects, item.toSubject())
+	}
+	return subjects, nil
+}
+
+// Subject 获取条目详情和制作人员
+func (p *BgmProvider) Subject(ctx context.Context, id string) (*Subject, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, fmt.Errorf("bgm条目ID无效: %s", id)
+	}
+
+	var item bgmSubject
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/v0/subjects/%s", p.baseURL, url.PathEscape(id)), nil, &item); err != nil {
+		return nil, fmt.Errorf("获取bgm条目[%s]失败: %w", id, err)
+	}
+
+	var persons []bgmPerson
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/v0/subjects/%s/persons", p.baseURL, url.PathEscape(id)), nil, &persons); err != nil {
+		return nil, fmt.Errorf("获取bgm条目[%s]制作人员失败: %w", id, err)
+	}
+
+	subject := item.toSubject()
+	for _, person := range persons {
+		if len(subject.Staff) >= bgmStaffLimit {
+			break
+		}
+		if person.Name == "" || person.Relation == "" {
+			continue
+		}
+		subject.Staff = append(subject.Staff, Person{ID: strconv.Itoa(person.ID), Name: person.Name, Role: person.Relation})
+	}
+	return &subject, nil
+}
+
+// do 发送请求并解码JSON响应，条目不存在时返回ErrSubjectNotFound
+func (p *BgmProvider) do(ctx context.Context, method, endpoint string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrSubjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, bgmMaxResponseSize)).Decode(out)
+}
+
+// toSubject 转换为通用的条目信息
+func (s bgmSubject) toSubject() Subject {
+	subject := Subject{
+		ID:           strconv.Itoa(s.ID),
+		Name:         s.Name,
+		NameCn:       s.NameCn,
+		Summary:      strings.TrimSpace(s.Summary),
+		AirDate:      s.Date,
+		EpisodeCount: s.TotalEpisodes,
+		Score:        s.Rating.Score,
+		ScoreCount:   s.Rating.Total,
+	}
+	if subject.EpisodeCount == 0 {
+		subject.EpisodeCount = s.Eps
+	}
+	for _, tag := range s.Tags {
+		subject.Tags = append(subject.Tags, Tag{Name: tag.Name, Count: tag.Count})
+	}
+
+	for _, field := range s.Infobox {
+		values := infoboxValues(field.Value)
+		switch field.Key {
+		case "别名":
+			subject.Aliases = append(subject.Aliases, values...)
+		case "中文名":
+			if subject.NameCn == "" && len(values) > 0 {
+				subject.NameCn = values[0]
+			}
+		case "放送星期":
+			if len(values) > 0 {
+				subject.Weekday = bgmWeekdays[strings.TrimSpace(values[0])]
+			}
+		}
+	}
+
+	// 信息框中没有放送星期时按首播日期推算
+	if subject.Weekday == 0 && subject.AirDate != "" {
+		if date, err := time.Parse("2006-01-02", subject.AirDate); err == nil {
+			subject.Weekday = isoWeekday(date)
+		}
+	}
+	return subject
+}
+
+// infoboxValues 信息框的值可能是字符串或{"v": ...}列表
+func infoboxValues(raw json.RawMessage) []string {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		if single = strings.TrimSpace(single); single != "" {
+			return []string{single}
+		}
+		return nil
+	}
+
+	var list []struct {
+		V string `json:"v"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if v := strings.TrimSpace(item.V); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// isoWeekday 星期一为1，星期日为7
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
@@ -0,0 +1,427 @@
+package metadata
+
+import (
+	"backend/models"
+	"backend/services/bangumi"
+	"backend/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSubjectNotFound 来源中不存在该条目
+	ErrSubjectNotFound = errors.New("条目不存在")
+	// ErrNoMatch 自动匹配未找到对应的条目
+	ErrNoMatch = errors.New("未找到匹配的条目")
+	// ErrProviderDisabled 未启用元数据来源
+	ErrProviderDisabled = errors.New("未启用元数据来源")
+	// ErrProviderRequest 请求元数据来源失败
+	ErrProviderRequest = errors.New("请求元数据来源失败")
+)
+
+const (
+	maxSearchNames     = 5                  // 自动匹配时最多使用的名称数
+	defaultStaleAfter  = 7 * 24 * time.Hour // 元数据默认刷新周期
+	maxSyncErrorLength = 500
+)
+
+// Subject 元数据来源中的条目
+type Subject struct {
+	ID           string
+	Name         string   // 原名（通常为日文）
+	NameCn       string   // 中文名
+	Aliases      []string // 其他名称
+	Summary      string
+	AirDate      string // 首播日期，格式为2006-01-02
+	Weekday      int    // 放送星期，1-7，0表示未知
+	EpisodeCount int
+	Score        float64
+	ScoreCount   int
+	Tags         []Tag
+	Staff        []Person
+}
+
+// Tag 条目标签
+type Tag struct {
+	Name  string
+	Count int
+}
+
+// Person 制作人员
+type Person struct {
+	ID   string
+	Name string
+	Role string
+}
+
+// Provider 番剧元数据来源
+type Provider interface {
+	// Name 来源名称，保存在元数据的provider字段中
+	Name() string
+	// Search 按关键词搜索动画条目，搜索结果可以只包含名称和首播日期
+	Search(ctx context.Context, keyword string) ([]Subject, error)
+	// Subject 获取条目的完整信息，包括标签和制作人员；条目不存在时返回ErrSubjectNotFound
+	Subject(ctx context.Context, id string) (*Subject, error)
+}
+
+// Config 元数据配置
+type Config struct {
+	Provider   string        // 元数据来源，bgm或none
+	BgmBaseURL string        // bgm.tv API地址
+	BgmToken   string        // bgm.tv访问令牌，可选
+	StaleAfter time.Duration // 元数据的刷新周期
+}
+
+// LoadConfig 从环境变量读取元数据配置
+// METADATA_PROVIDER（bgm/none，默认bgm）、BGM_API_BASE_URL、BGM_ACCESS_TOKEN、METADATA_REFRESH_DAYS（默认7）
+func LoadConfig() Config {
+	cfg := Config{
+		Provider:   models.MetadataProviderBgm,
+		BgmBaseURL: DefaultBgmBaseURL,
+		StaleAfter: defaultStaleAfter,
+	}
+	if v := strings.TrimSpace(os.Getenv("METADATA_PROVIDER")); v != "" {
+		cfg.Provider = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(os.Getenv("BGM_API_BASE_URL")); v != "" {
+		cfg.BgmBaseURL = v
+	}
+	cfg.BgmToken = strings.TrimSpace(os.Getenv("BGM_ACCESS_TOKEN"))
+	if v, err := strconv.Atoi(os.Getenv("METADATA_REFRESH_DAYS")); err == nil && v > 0 {
+		cfg.StaleAfter = time.Duration(v) * 24 * time.Hour
+	}
+	return cfg
+}
+
+// NewProvider 根据配置创建元数据来源，未启用时返回nil
+func NewProvider(cfg Config) Provider {
+	switch cfg.Provider {
+	case models.MetadataProviderBgm:
+		return NewBgmProvider(cfg.BgmBaseURL, cfg.BgmToken, nil)
+	case "none":
+		return nil
+	default:
+		utils.LogWarning(fmt.Sprintf("未知的元数据来源 %s，已禁用番剧元数据", cfg.Provider), nil)
+		return nil
+	}
+}
+
+var (
+	defaultProvider     Provider
+	defaultProviderOnce sync.Once
+)
+
+// Default 返回根据环境变量创建的元数据来源，未启用时返回nil
+func Default() Provider {
+	defaultProviderOnce.Do(func() {
+		defaultProvider = NewProvider(LoadConfig())
+	})
+	return defaultProvider
+}
+
+// seasonSuffixRE 条目名称末尾的季度标记，如"第2期"、"第二季"、"Season 2"、"2nd Season"
+var seasonSuffixRE = regexp.MustCompile(`(?i)\s*(?:第([0-9一二三四五六七八九十]+)[季期部]|season\s*([0-9]+)|([0-9]+)(?:st|nd|rd|th)\s+season)\s*$`)
+
+// chineseNumerals 季度标记中的中文数字
+var chineseNumerals = map[string]int{"一": 1, "二": 2, "三": 3, "四": 4, "五": 5, "六": 6, "七": 7, "八": 8, "九": 9, "十": 10}
+
+// splitSeason 拆分名称和季度标记，没有季度标记时为第1季
+func splitSeason(title string) (string, int) {
+	m := seasonSuffixRE.FindStringSubmatchIndex(title)
+	if m == nil {
+		return title, 1
+	}
+	for i := 2; i < len(m); i += 2 {
+		if m[i] < 0 {
+			continue
+		}
+		number := title[m[i]:m[i+1]]
+		if n, err := strconv.Atoi(number); err == nil {
+			return title[:m[0]], n
+		}
+		if n, ok := chineseNumerals[number]; ok {
+			return title[:m[0]], n
+		}
+	}
+	return title, 1
+}
+
+// Match 在来源中查找与番剧对应的条目：依次用番剧名和别名搜索，
+// 条目的原名、中文名或别名去掉季度标记后与番剧名或别名相同、季度相同，且番剧有年份时首播年份一致，才视为匹配
+func Match(ctx context.Context, db *gorm.DB, provider Provider, target models.Bangumi) (*Subject, error) {
+	titles := []string{target.OfficialTitle}
+	var aliases []string
+	if err := db.Model(&models.BangumiAlias{}).Where("bangumi_id = ?", target.ID).Order("id").Pluck("alias", &aliases).Error; err != nil {
+		return nil, fmt.Errorf("获取番剧别名失败: %w", err)
+	}
+	titles = append(titles, aliases...)
+
+	names := make(map[string]bool)
+	var keywords []string
+	for _, title := range titles {
+		base, _ := splitSeason(strings.TrimSpace(title))
+		normalized := bangumi.NormalizeTitle(base)
+		if normalized == "" || names[normalized] {
+			continue
+		}
+		names[normalized] = true
+		keywords = append(keywords, strings.TrimSpace(base))
+	}
+	if len(keywords) > maxSearchNames {
+		keywords = keywords[:maxSearchNames]
+	}
+
+	season := target.Season
+	if season <= 0 {
+		season = 1
+	}
+	year := ""
+	if target.Year != nil {
+		year = strings.TrimSpace(*target.Year)
+	}
+
+	for _, keyword := range keywords {
+		candidates, err := provider.Search(ctx, keyword)
+		if err != nil {
+			return nil, providerError(err)
+		}
+		for i := range candidates {
+			if subjectMatches(candidates[i], names, season, year) {
+				return &candidates[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// subjectMatches 判断条目的名称、季度和首播年份是否与番剧一致
+func subjectMatches(subject Subject, names map[string]bool, season int, year string) bool {
+	if year != "" && subject.AirDate != "" && !strings.HasPrefix(subject.AirDate, year) {
+		return false
+	}
+	candidates := append([]string{subject.Name, subject.NameCn}, subject.Aliases...)
+	for _, name := range candidates {
+		base, subjectSeason := splitSeason(strings.TrimSpace(name))
+		if subjectSeason == season && names[bangumi.NormalizeTitle(base)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Link 从来源获取条目信息并保存为番剧的元数据，替换原有的标签和制作人员
+func Link(ctx context.Context, db *gorm.DB, provider Provider, bangumiID uint, subjectID string, matchedBy string) (*models.BangumiMetadata, error) {
+	if provider == nil {
+		return nil, ErrProviderDisabled
+	}
+	var target models.Bangumi
+	if err := db.Select("id").First(&target, bangumiID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, bangumi.ErrBangumiNotFound
+		}
+		return nil, err
+	}
+
+	subject, err := provider.Subject(ctx, subjectID)
+	if err != nil {
+		return nil, providerError(err)
+	}
+	return saveSubject(db, provider.Name(), bangumiID, subject, matchedBy)
+}
+
+// providerError 将来源返回的错误包装为ErrProviderRequest，条目不存在和取消除外
+func providerError(err error) error {
+	if errors.Is(err, ErrSubjectNotFound) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrProviderRequest, err)
+}
+
+// saveSubject 在事务中保存条目信息、标签和制作人员
+func saveSubject(db *gorm.DB, providerName string, bangumiID uint, subject *Subject, matchedBy string) (*models.BangumiMetadata, error) {
+	var metadata models.BangumiMetadata
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bangumi_id = ?", bangumiID).Limit(1).Find(&metadata).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		metadata.BangumiID = bangumiID
+		metadata.Provider = providerName
+		metadata.SubjectID = subject.ID
+		metadata.MatchedBy = matchedBy
+		metadata.Name = subject.Name
+		metadata.NameCn = subject.NameCn
+		metadata.Summary = subject.Summary
+		metadata.AirDate = subject.AirDate
+		metadata.Weekday = subject.Weekday
+		metadata.EpisodeCount = subject.EpisodeCount
+		metadata.Score = subject.Score
+		metadata.ScoreCount = subject.ScoreCount
+		metadata.SyncedAt = &now
+		metadata.SyncError = ""
+		metadata.Tags = nil
+		metadata.Staff = nil
+		if err := tx.Save(&metadata).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("metadata_id = ?", metadata.ID).Delete(&models.BangumiMetadataTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("metadata_id = ?", metadata.ID).Delete(&models.BangumiMetadataStaff{}).Error; err != nil {
+			return err
+		}
+
+		tags := make([]models.BangumiMetadataTag, 0, len(subject.Tags))
+		for _, tag := range subject.Tags {
+			tags = append(tags, models.BangumiMetadataTag{MetadataID: metadata.ID, Name: tag.Name, Count: tag.Count})
+		}
+		if len(tags) > 0 {
+			if err := tx.Create(&tags).Error; err != nil {
+				return err
+			}
+		}
+		staff := make([]models.BangumiMetadataStaff, 0, len(subject.Staff))
+		for _, person := range subject.Staff {
+			staff = append(staff, models.BangumiMetadataStaff{MetadataID: metadata.ID, Role: person.Role, Name: person.Name, PersonID: person.ID})
+		}
+		if len(staff) > 0 {
+			if err := tx.Create(&staff).Error; err != nil {
+				return err
+			}
+		}
+		metadata.Tags, metadata.Staff = tags, staff
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存番剧[ID:%d]元数据失败: %w", bangumiID, err)
+	}
+	return &metadata, nil
+}
+
+// Refresh 刷新番剧的元数据：已关联条目的按条目ID重新获取，尚未关联的重新自动匹配；
+// 未找到匹配条目时返回ErrNoMatch，失败原因会记录在元数据中，等到下一个刷新周期再尝试
+func Refresh(ctx context.Context, db *gorm.DB, provider Provider, bangumiID uint) (*models.BangumiMetadata, error) {
+	if provider == nil {
+		return nil, ErrProviderDisabled
+	}
+	var target models.Bangumi
+	if err := db.First(&target, bangumiID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, bangumi.ErrBangumiNotFound
+		}
+		return nil, err
+	}
+
+	var existing models.BangumiMetadata
+	if err := db.Where("bangumi_id = ?", bangumiID).Limit(1).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	subjectID, matchedBy := existing.SubjectID, existing.MatchedBy
+	if subjectID == "" {
+		subject, err := Match(ctx, db, provider, target)
+		if err != nil {
+			recordSyncError(ctx, db, provider.Name(), bangumiID, err)
+			return nil, err
+		}
+		if subject == nil {
+			recordSyncError(ctx, db, provider.Name(), bangumiID, ErrNoMatch)
+			return nil, ErrNoMatch
+		}
+		subjectID, matchedBy = subject.ID, models.MetadataMatchAuto
+	}
+
+	subject, err := provider.Subject(ctx, subjectID)
+	if err != nil {
+		err = providerError(err)
+		recordSyncError(ctx, db, provider.Name(), bangumiID, err)
+		return nil, err
+	}
+	return saveSubject(db, provider.Name(), bangumiID, subject, matchedBy)
+}
+
+// recordSyncError 记录同步失败的原因和时间，避免在刷新周期内反复请求；取消导致的失败不记录
+func recordSyncError(ctx context.Context, db *gorm.DB, providerName string, bangumiID uint, syncErr error) {
+	if ctx.Err() != nil {
+		return
+	}
+	message := syncErr.Error()
+	if len(message) > maxSyncErrorLength {
+		message = message[:maxSyncErrorLength]
+	}
+
+	now := time.Now()
+	var metadata models.BangumiMetadata
+	if err := db.Where("bangumi_id = ?", bangumiID).Limit(1).Find(&metadata).Error; err != nil {
+		utils.LogError(fmt.Sprintf("记录番剧[ID:%d]元数据同步失败原因失败", bangumiID), err)
+		return
+	}
+	if metadata.ID == 0 {
+		metadata = models.BangumiMetadata{BangumiID: bangumiID, Provider: providerName, MatchedBy: models.MetadataMatchAuto}
+	}
+	metadata.SyncedAt = &now
+	metadata.SyncError = message
+	if err := db.Omit("Tags", "Staff").Save(&metadata).Error; err != nil {
+		utils.LogError(fmt.Sprintf("记录番剧[ID:%d]元数据同步失败原因失败", bangumiID), err)
+	}
+}
+
+// RefreshStale 刷新尚无元数据或超过刷新周期的番剧，最多处理limit个，返回成功刷新的数量
+func RefreshStale(ctx context.Context, db *gorm.DB, provider Provider, staleAfter time.Duration, limit int) (int, error) {
+	if provider == nil {
+		return 0, ErrProviderDisabled
+	}
+
+	var bangumiIDs []uint
+	err := db.Model(&models.Bangumi{}).
+		Joins("LEFT JOIN bangumi_metadata ON bangumi_metadata.bangumi_id = bangumi.id").
+		Where("bangumi_metadata.id IS NULL OR bangumi_metadata.synced_at IS NULL OR bangumi_metadata.synced_at < ?", time.Now().Add(-staleAfter)).
+		Order("bangumi_metadata.synced_at").Order("bangumi.id").
+		Limit(limit).Pluck("bangumi.id", &bangumiIDs).Error
+	if err != nil {
+		return 0, fmt.Errorf("获取待刷新元数据的番剧失败: %w", err)
+	}
+
+	refreshed := 0
+	for _, bangumiID := range bangumiIDs {
+		if ctx.Err() != nil {
+			return refreshed, ctx.Err()
+		}
+		if _, err := Refresh(ctx, db, provider, bangumiID); err != nil {
+			if errors.Is(err, ErrNoMatch) {
+				utils.LogInfo(fmt.Sprintf("番剧[ID:%d]在%s中未找到匹配的条目", bangumiID, provider.Name()))
+			} else {
+				utils.LogError(fmt.Sprintf("刷新番剧[ID:%d]元数据失败", bangumiID), err)
+			}
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// Get 获取番剧已关联条目的元数据，包括标签和制作人员；未关联时返回nil
+func Get(db *gorm.DB, bangumiID uint) (*models.BangumiMetadata, error) {
+	var metadata models.BangumiMetadata
+	err := db.Preload("Tags", func(tx *gorm.DB) *gorm.DB { return tx.Order("count DESC").Order("id") }).
+		Preload("Staff", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		Where("bangumi_id = ? AND subject_id <> ''", bangumiID).First(&metadata).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
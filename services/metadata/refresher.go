@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"backend/models"
+	"backend/services/leader"
+	"backend/utils"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	refreshEvery         = 10 * time.Minute // 检查待刷新番剧的间隔
+	refreshBatchSize     = 20               // 每轮最多刷新的番剧数，避免集中请求来源
+	leaderLeaseTTL       = time.Minute      // 主节点租约时长
+	leaderHeartbeatEvery = 15 * time.Second // 续约间隔
+)
+
+// Refresher 定时刷新番剧元数据，多实例部署时只有持有租约的主节点执行
+type Refresher struct {
+	db           *gorm.DB
+	provider     Provider
+	staleAfter   time.Duration
+	elector      *leader.Elector
+	mu           sync.Mutex
+	isRunning    bool
+	cancel       context.CancelFunc // 取消进行中的刷新
+	stopChan     chan struct{}
+	completeChan chan struct{}
+}
+
+// NewRefresher 创建元数据刷新器，provider为nil时Start不执行任何操作
+func NewRefresher(db *gorm.DB, provider Provider, staleAfter time.Duration) *Refresher {
+	if staleAfter <= 0 {
+		staleAfter = defaultStaleAfter
+	}
+	return &Refresher{
+		db:         db,
+		provider:   provider,
+		staleAfter: staleAfter,
+		elector:    leader.NewElector(db, models.SchedulerLockMetadata, leader.DefaultOwner(), leaderLeaseTTL),
+	}
+}
+
+// Start 启动定时刷新
+func (r *Refresher) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.provider == nil {
+		utils.LogInfo("未启用番剧元数据来源，跳过元数据定时刷新")
+		return
+	}
+	if r.isRunning {
+		return
+	}
+
+	r.isRunning = true
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.stopChan = make(chan struct{})
+	r.completeChan = make(chan struct{})
+	r.elector.Start(leaderHeartbeatEvery)
+	utils.LogInfo(fmt.Sprintf("番剧元数据刷新已启动（来源 %s，刷新周期 %s）", r.provider.Name(), r.staleAfter))
+
+	go func(stopChan <-chan struct{}, completeChan chan<- struct{}) {
+		defer close(completeChan)
+		for {
+			select {
+			case <-time.After(refreshEvery):
+				r.refresh(ctx)
+			case <-stopChan:
+				return
+			}
+		}
+	}(r.stopChan, r.completeChan)
+}
+
+// Stop 停止定时刷新，等待进行中的刷新结束，ctx到期后取消，最后释放主节点租约
+func (r *Refresher) Stop(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.isRunning {
+		return
+	}
+
+	close(r.stopChan)
+	select {
+	case <-r.completeChan:
+	case <-ctx.Done():
+		utils.LogWarning("等待番剧元数据刷新结束超时，取消进行中的刷新", ctx.Err())
+		r.cancel()
+		<-r.completeChan
+	}
+	r.cancel()
+	r.elector.Stop()
+	r.isRunning = false
+	utils.LogInfo("番剧元数据刷新已停止")
+}
+
+// refresh 刷新一批待刷新的番剧
+func (r *Refresher) refresh(ctx context.Context) {
+	if !r.elector.IsLeader() {
+		return
+	}
+	refreshed, err := RefreshStale(ctx, r.db, r.provider, r.staleAfter, refreshBatchSize)
+	if err != nil && ctx.Err() == nil {
+		utils.LogError("刷新番剧元数据失败", err)
+		return
+	}
+	if refreshed > 0 {
+		utils.LogInfo(fmt.Sprintf("已刷新%d个番剧的元数据", refreshed))
+	}
+}
@@ -0,0 +1,97 @@
+package rss
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReleaseCandidate 订阅源解析器产出的标准化发布条目
+type ReleaseCandidate struct {
+	RawTitle      string // 原始发布标题，交由RawParser解析
+	OfficialTitle string // 番剧官方名称，为空时使用RawParser解析出的名称
+	Group         string // 字幕组，为空时使用RawParser解析出的字幕组
+	Homepage      string // 条目主页
+	TorrentURL    string // 种子或磁力链接
+	MagnetURL     string // 磁力链接，为空时根据种子的infohash生成
+	ReleaseDate   string // 发布日期
+	ReleaseYear   string // 发布年份
+	Source        string // 来源标识，为空时使用RawParser解析出的来源
+	BangumiPage   string // 番剧主页，用于获取海报
+}
+
+// formatReleaseDate 将发布时间转换为条目使用的发布日期和年份
+func formatReleaseDate(t time.Time) (string, string) {
+	if t.IsZero() {
+		return "", ""
+	}
+	return t.Format("2006/01/02 15:04"), t.Format("2006")
+}
+
+// FeedParser 订阅源解析器，负责将抓取到的页面转换为候选条目
+type FeedParser interface {
+	// Description 解析器说明，用于管理后台展示
+	Description() string
+	// PageURLs 返回订阅源需要抓取的页面地址
+	PageURLs(feed models.RSSFeed) []string
+	// ParsePage 将单个页面的内容转换为候选条目，需要额外请求时应在ctx取消后停止
+	ParsePage(ctx context.Context, feed models.RSSFeed, pageURL string, content []byte) ([]ReleaseCandidate, error)
+}
+
+// PosterResolver 可选接口，用于在条目通过筛选后再获取海报，避免为被过滤的条目发起请求
+type PosterResolver interface {
+	ResolvePoster(ctx context.Context, candidate ReleaseCandidate) (string, error)
+}
+
+// ParserTypeInfo 已注册解析器的信息
+type ParserTypeInfo struct {
+	Type        string `json:"type" example:"mikanani"`
+	Description string `json:"description" example:"Mikan Project 订阅源"`
+}
+
+var (
+	feedParsersMu sync.RWMutex
+	feedParsers   = make(map[string]FeedParser)
+)
+
+// RegisterFeedParser 注册订阅源解析器，通常在实现文件的init中调用
+func RegisterFeedParser(parserType string, p FeedParser) {
+	feedParsersMu.Lock()
+	defer feedParsersMu.Unlock()
+
+	if _, exists := feedParsers[parserType]; exists {
+		panic(fmt.Sprintf("解析器类型重复注册: %s", parserType))
+	}
+	feedParsers[parserType] = p
+}
+
+// GetFeedParser 根据解析器类型获取已注册的解析器
+func GetFeedParser(parserType string) (FeedParser, bool) {
+	feedParsersMu.RLock()
+	defer feedParsersMu.RUnlock()
+
+	p, ok := feedParsers[parserType]
+	return p, ok
+}
+
+// IsValidParserType 检查解析器类型是否已注册
+func IsValidParserType(parserType string) bool {
+	_, ok := GetFeedParser(parserType)
+	return ok
+}
+
+// ListParserTypes 返回所有已注册的解析器，按类型名排序
+func ListParserTypes() []ParserTypeInfo {
+	feedParsersMu.RLock()
+	defer feedParsersMu.RUnlock()
+
+	types := make([]ParserTypeInfo, 0, len(feedParsers))
+	for t, p := range feedParsers {
+		types = append(types, ParserTypeInfo{Type: t, Description: p.Description()})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
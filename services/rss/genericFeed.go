@@ -0,0 +1,75 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"backend/utils/parser"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// genericFeedParser 通用RSS源解析器（nyaa、dmhy、acg.rip等）
+// 条目标题即原始发布标题，种子或磁力链接位于enclosure中，无需抓取任何条目页面
+type genericFeedParser struct{}
+
+func init() {
+	RegisterFeedParser("generic_rss", genericFeedParser{})
+}
+
+// Description 解析器说明
+func (genericFeedParser) Description() string {
+	return "通用RSS源，直接解析条目标题与enclosure中的种子或磁力链接"
+}
+
+// PageURLs 通用RSS源不分页
+func (genericFeedParser) PageURLs(feed models.RSSFeed) []string {
+	return []string{feed.URL}
+}
+
+// ParsePage 将RSS条目转换为候选条目
+func (genericFeedParser) ParsePage(ctx context.Context, feed models.RSSFeed, pageURL string, content []byte) ([]ReleaseCandidate, error) {
+	items, err := parser.ParseRSS(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []ReleaseCandidate
+	for _, item := range items {
+		if item.Title == "" {
+			utils.LogError(fmt.Sprintf("RSS源[ID:%d] 跳过无标题条目: %s", feed.ID, item.Link), nil)
+			continue
+		}
+
+		// 种子或磁力链接通常位于enclosure中，部分站点（如nyaa）直接放在link中
+		torrentLink := item.EnclosureURL
+		if torrentLink == "" {
+			torrentLink = item.Link
+		}
+		if torrentLink == "" {
+			utils.LogError(fmt.Sprintf("RSS源[ID:%d] 条目缺少种子链接: %s", feed.ID, item.Title), nil)
+			continue
+		}
+
+		homepage := item.Link
+		if homepage == torrentLink && strings.HasPrefix(item.GUID, "http") {
+			homepage = item.GUID
+		}
+
+		magnetURL := ""
+		if strings.HasPrefix(strings.ToLower(torrentLink), "magnet:") {
+			magnetURL = torrentLink
+		}
+
+		releaseDate, releaseYear := formatReleaseDate(item.PubDate)
+		candidates = append(candidates, ReleaseCandidate{
+			RawTitle:    item.Title,
+			Homepage:    homepage,
+			TorrentURL:  torrentLink,
+			MagnetURL:   magnetURL,
+			ReleaseDate: releaseDate,
+			ReleaseYear: releaseYear,
+		})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,87 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/services/leader"
+	"backend/utils"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 主节点租约配置
+const (
+	leaderLeaseTTL       = time.Minute      // 租约时长，主节点异常退出后最多经过该时长由其他实例接管
+	leaderHeartbeatEvery = 15 * time.Second // 续约间隔
+)
+
+var (
+	electorMu sync.RWMutex
+	elector   *leader.Elector
+)
+
+// setElector 设置当前进程使用的选举器
+func setElector(e *leader.Elector) {
+	electorMu.Lock()
+	defer electorMu.Unlock()
+	elector = e
+}
+
+// IsLeader 当前实例是否为RSS更新的主节点；未启动调度器（如命令行工具）时视为主节点
+func IsLeader() bool {
+	electorMu.RLock()
+	defer electorMu.RUnlock()
+	return elector == nil || elector.IsLeader()
+}
+
+// InstanceID 当前实例的标识，未启动调度器时返回空字符串
+func InstanceID() string {
+	electorMu.RLock()
+	defer electorMu.RUnlock()
+	if elector == nil {
+		return ""
+	}
+	return elector.Owner()
+}
+
+// RequestRun 记录手动更新请求，由主节点在下一轮调度时执行；feedID为0时请求更新所有启用的订阅源
+func RequestRun(db *gorm.DB, feedID uint) error {
+	query := db.Model(&models.RSSFeed{})
+	if feedID > 0 {
+		query = query.Where("id = ?", feedID)
+	} else {
+		query = query.Where("enabled = ?", true)
+	}
+	return query.UpdateColumn("run_requested_at", time.Now()).Error
+}
+
+// runRequestedFeeds 执行其他实例提交的手动更新请求，与手动更新单个订阅源一样忽略分页缓存
+func runRequestedFeeds(ctx context.Context, db *gorm.DB) {
+	var feedIDs []uint
+	if err := db.Model(&models.RSSFeed{}).Where("run_requested_at IS NOT NULL").Order("run_requested_at ASC").Pluck("id", &feedIDs).Error; err != nil {
+		utils.LogError("获取待执行的手动更新请求失败", err)
+		return
+	}
+	if len(feedIDs) == 0 {
+		return
+	}
+
+	// 先清除请求再执行，执行期间收到的新请求留到下一轮
+	if err := db.Model(&models.RSSFeed{}).Where("id IN ?", feedIDs).UpdateColumn("run_requested_at", nil).Error; err != nil {
+		utils.LogError("清除手动更新请求失败", err)
+		return
+	}
+
+	utils.LogInfo(fmt.Sprintf("执行其他实例提交的手动更新请求，共%d个订阅源", len(feedIDs)))
+	for _, feedID := range feedIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := UpdateSingleRSSFeed(ctx, db, feedID); err != nil {
+			utils.LogError(fmt.Sprintf("执行RSS源[ID:%d]的手动更新请求失败", feedID), err)
+		}
+	}
+}
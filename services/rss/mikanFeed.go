@@ -0,0 +1,163 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"backend/utils/parser"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// mikanFeedParser Mikan Project 订阅源解析器
+// 番剧订阅（RSS/Bangumi?bangumiId=）的条目信息全部来自RSS本身，只有海报需要按番剧抓取一次页面
+type mikanFeedParser struct {
+	posters sync.Map // 番剧主页 -> 海报路径
+}
+
+func init() {
+	RegisterFeedParser("mikanani", &mikanFeedParser{})
+}
+
+// Description 解析器说明
+func (p *mikanFeedParser) Description() string {
+	return "Mikan Project 订阅源，番剧订阅直接使用RSS中的标题、种子和发布时间"
+}
+
+// PageURLs 根据分页配置生成页面地址
+func (p *mikanFeedParser) PageURLs(feed models.RSSFeed) []string {
+	pageStart := 1
+	pageEnd := 1
+	if feed.PageStart != nil && feed.PageEnd != nil && *feed.PageEnd >= *feed.PageStart {
+		pageStart = *feed.PageStart
+		pageEnd = *feed.PageEnd
+	}
+
+	var pageURLs []string
+	for page := pageStart; page <= pageEnd; page++ {
+		var pageURL string
+		if page == 1 {
+			pageURL = feed.URL
+		} else {
+			if strings.HasSuffix(feed.URL, "/") {
+				pageURL = fmt.Sprintf("%s%d", feed.URL, page)
+			} else {
+				pageURL = fmt.Sprintf("%s/%d", feed.URL, page)
+			}
+		}
+		pageURLs = append(pageURLs, pageURL)
+	}
+	return pageURLs
+}
+
+// ParsePage 将RSS条目转换为候选条目，非番剧订阅时回退到抓取条目页面获取番剧名
+func (p *mikanFeedParser) ParsePage(ctx context.Context, feed models.RSSFeed, pageURL string, content []byte) ([]ReleaseCandidate, error) {
+	feedContent, err := parser.ParseFeed(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	// 判断是否为Mikan网站
+	isMikan := false
+	if parsedURL, err := url.Parse(feed.URL); err == nil {
+		isMikan = strings.Contains(parsedURL.Host, "mikanani")
+	}
+
+	bangumiPage, officialTitle := mikanBangumiOf(feed.URL, feedContent.Title)
+
+	var candidates []ReleaseCandidate
+	for _, item := range feedContent.Items {
+		// 已取消时不再抓取剩余条目的页面
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		releaseDate, releaseYear := formatReleaseDate(item.PubDate)
+		candidate := ReleaseCandidate{
+			RawTitle:      item.Title,
+			OfficialTitle: officialTitle,
+			Homepage:      item.Link,
+			TorrentURL:    item.EnclosureURL,
+			ReleaseDate:   releaseDate,
+			ReleaseYear:   releaseYear,
+			BangumiPage:   bangumiPage,
+		}
+
+		// 非番剧订阅（如"我的番组"）的频道标题不是番剧名，需要抓取条目页面
+		if candidate.OfficialTitle == "" {
+			info, err := parser.GetMikanEpisodeInfoContext(ctx, item.Link)
+			if err != nil {
+				utils.LogError(fmt.Sprintf("RSS源[ID:%d] 解析Mikan条目基本信息失败 %s", feed.ID, item.Link), err)
+				continue
+			}
+			candidate.OfficialTitle = info.OfficialTitle
+			candidate.Group = info.SubGroup
+			candidate.BangumiPage = info.BangumiURL
+			if candidate.RawTitle == "" {
+				candidate.RawTitle = info.OriginalTitle
+			}
+			if candidate.TorrentURL == "" {
+				candidate.TorrentURL = info.TorrentLink
+			}
+			candidate.MagnetURL = info.MagnetLink
+			if candidate.ReleaseDate == "" {
+				candidate.ReleaseDate, candidate.ReleaseYear = info.ReleaseDate, info.ReleaseYear
+			}
+			if info.PosterURL != "" && info.BangumiURL != "" {
+				p.posters.Store(info.BangumiURL, info.PosterURL)
+			}
+		}
+
+		// 跳过无title的条目，防止污染bangumi_id=1
+		if candidate.OfficialTitle == "" || candidate.RawTitle == "" {
+			utils.LogError(fmt.Sprintf("跳过无效条目：officialTitle或原始标题为空，itemURL=%s", item.Link), nil)
+			continue
+		}
+
+		if isMikan {
+			candidate.Source = "mikan"
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// ResolvePoster 获取番剧海报，同一番剧在进程内只请求一次
+func (p *mikanFeedParser) ResolvePoster(ctx context.Context, candidate ReleaseCandidate) (string, error) {
+	page := candidate.BangumiPage
+	if page == "" {
+		page = candidate.Homepage
+	}
+	if cached, ok := p.posters.Load(page); ok {
+		return cached.(string), nil
+	}
+
+	posterURL, err := parser.GetMikanPosterURLContext(ctx, page)
+	if err != nil {
+		return "", err
+	}
+	if posterURL != "" {
+		p.posters.Store(page, posterURL)
+	}
+	return posterURL, nil
+}
+
+// mikanBangumiOf 从番剧订阅地址和频道标题中获取番剧主页和番剧名
+// 频道标题形如"Mikan Project - 莉可丽丝"，非番剧订阅返回空字符串
+func mikanBangumiOf(feedURL string, channelTitle string) (string, string) {
+	parsedURL, err := url.Parse(feedURL)
+	if err != nil {
+		return "", ""
+	}
+	bangumiID := parsedURL.Query().Get("bangumiId")
+	if bangumiID == "" {
+		return "", ""
+	}
+
+	title := strings.TrimSpace(strings.TrimPrefix(channelTitle, "Mikan Project - "))
+	if title == "" || title == channelTitle {
+		return "", ""
+	}
+	return fmt.Sprintf("%s://%s/Home/Bangumi/%s", parsedURL.Scheme, parsedURL.Host, bangumiID), parser.TrimMikanSeason(title)
+}
@@ -0,0 +1,67 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fetchedPage 分页抓取结果
+type fetchedPage struct {
+	content   []byte
+	unchanged bool                     // 服务器返回304或内容哈希与上次一致，无需再处理
+	cache     *models.RSSFeedPageCache // 处理成功后需要写回的缓存
+}
+
+// fetchPage 使用条件请求抓取分页，force为true时忽略已有缓存
+func fetchPage(ctx context.Context, db *gorm.DB, feed models.RSSFeed, pageURL string, force bool) (*fetchedPage, error) {
+	cache := models.RSSFeedPageCache{RssID: feed.ID, PageURL: pageURL}
+	err := db.Where("rss_id = ? AND page_url = ?", feed.ID, pageURL).First(&cache).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 读取分页缓存失败: %s", feed.ID, pageURL), err)
+	}
+
+	etag, lastModified := cache.ETag, cache.LastModified
+	if force {
+		etag, lastModified = "", ""
+	}
+
+	result, err := utils.FetchURLConditionalWithRetryContext(ctx, pageURL, etag, lastModified, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.NotModified {
+		utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 分页未修改(304): %s", feed.ID, pageURL))
+		return &fetchedPage{unchanged: true}, nil
+	}
+
+	sum := sha256.Sum256(result.Body)
+	contentHash := hex.EncodeToString(sum[:])
+	unchanged := !force && cache.ContentHash == contentHash
+	if unchanged {
+		utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 分页内容未变化: %s", feed.ID, pageURL))
+	}
+
+	cache.ETag = result.ETag
+	cache.LastModified = result.LastModified
+	cache.ContentHash = contentHash
+	return &fetchedPage{content: result.Body, unchanged: unchanged, cache: &cache}, nil
+}
+
+// savePageCache 分页处理成功后保存缓存，处理失败的分页下次仍会重新处理
+func savePageCache(db *gorm.DB, cache *models.RSSFeedPageCache) {
+	if cache == nil {
+		return
+	}
+	if err := db.Save(cache).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 保存分页缓存失败: %s", cache.RssID, cache.PageURL), err)
+	}
+}
@@ -0,0 +1,204 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/services/bangumi"
+	"backend/utils"
+	"backend/utils/parser"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxPreviewPages 预览时最多抓取的分页数，避免一次请求抓取过多页面
+const maxPreviewPages = 10
+
+// 预览条目的处理结果
+const (
+	PreviewAccepted     = "accepted"      // 将被收录
+	PreviewDuplicate    = "duplicate"     // 条目已存在
+	PreviewExcluded     = "excluded"      // 命中排除规则
+	PreviewBlacklisted  = "blacklisted"   // 字幕组在黑名单中
+	PreviewSubMismatch  = "sub_mismatch"  // 字幕不符且未命中包含规则
+	PreviewParseFailed  = "parse_failed"  // 标题解析失败
+	PreviewProcessError = "process_error" // 处理失败
+)
+
+// PreviewPage 预览分页的抓取结果
+type PreviewPage struct {
+	URL   string `json:"url"`
+	Items int    `json:"items"`
+	Error string `json:"error,omitempty"`
+}
+
+// PreviewItem 预览的候选条目及其筛选结果
+type PreviewItem struct {
+	PageURL       string          `json:"page_url"`
+	RawTitle      string          `json:"raw_title"`
+	OfficialTitle string          `json:"official_title"`
+	Group         string          `json:"group"`
+	TorrentURL    string          `json:"torrent_url"`
+	Homepage      string          `json:"homepage"`
+	ReleaseDate   string          `json:"release_date"`
+	Episode       *parser.Episode `json:"episode"`
+	Result        string          `json:"result" example:"accepted"`
+	Reason        string          `json:"reason"`
+	BangumiID     uint            `json:"bangumi_id"`  // 归属的已有番剧ID，为0时将创建新番剧
+	NewBangumi    bool            `json:"new_bangumi"` // 是否会创建新番剧
+
+	// 低置信度原因，收录时会同时加入待审核队列
+	ReviewReasons []string `json:"review_reasons,omitempty" example:"new_bangumi"`
+}
+
+// FeedPreview RSS源配置的预览结果
+type FeedPreview struct {
+	FeedID    uint           `json:"feed_id"`
+	Pages     []PreviewPage  `json:"pages"`
+	Truncated bool           `json:"truncated"` // 分页数超过上限时只预览前maxPreviewPages页
+	Items     []PreviewItem  `json:"items"`
+	Summary   map[string]int `json:"summary"`
+}
+
+// PreviewFeed 对RSS源配置执行抓取、解析和筛选，不写入数据库也不使用分页缓存
+// feed.ID为0时表示尚未保存的订阅源，不检查重复条目；ctx取消时停止抓取
+func PreviewFeed(ctx context.Context, db *gorm.DB, feed models.RSSFeed) (*FeedPreview, error) {
+	feedParser, ok := GetFeedParser(feed.ParserType)
+	if !ok {
+		return nil, fmt.Errorf("未知的解析器类型: %s", feed.ParserType)
+	}
+
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		return nil, fmt.Errorf("获取全局设置失败: %v", err)
+	}
+	cf, err := newCandidateFilter(db, settings, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &FeedPreview{
+		FeedID:  feed.ID,
+		Pages:   make([]PreviewPage, 0),
+		Items:   make([]PreviewItem, 0),
+		Summary: make(map[string]int),
+	}
+
+	pageURLs := feedParser.PageURLs(feed)
+	if len(pageURLs) > maxPreviewPages {
+		pageURLs = pageURLs[:maxPreviewPages]
+		preview.Truncated = true
+	}
+
+	for _, pageURL := range pageURLs {
+		page := PreviewPage{URL: pageURL}
+
+		content, err := utils.FetchURLContentWithRetryContext(ctx, pageURL, 1, time.Second)
+		if err != nil {
+			page.Error = fmt.Sprintf("获取RSS内容失败: %v", err)
+			preview.Pages = append(preview.Pages, page)
+			continue
+		}
+		candidates, err := feedParser.ParsePage(ctx, feed, pageURL, content)
+		if err != nil {
+			page.Error = fmt.Sprintf("解析RSS内容失败: %v", err)
+			preview.Pages = append(preview.Pages, page)
+			continue
+		}
+
+		page.Items = len(candidates)
+		preview.Pages = append(preview.Pages, page)
+
+		for _, candidate := range candidates {
+			item := previewCandidate(db, feed, cf, candidate)
+			item.PageURL = pageURL
+			preview.Items = append(preview.Items, item)
+			preview.Summary[item.Result]++
+		}
+	}
+
+	return preview, nil
+}
+
+// previewCandidate 对单个候选条目执行筛选，并只读地查询归属番剧和重复条目
+func previewCandidate(db *gorm.DB, feed models.RSSFeed, cf candidateFilter, candidate ReleaseCandidate) PreviewItem {
+	eval := evaluateCandidate(cf, candidate)
+	item := PreviewItem{
+		RawTitle:      candidate.RawTitle,
+		OfficialTitle: eval.officialTitle,
+		Group:         eval.group,
+		TorrentURL:    candidate.TorrentURL,
+		Homepage:      candidate.Homepage,
+		ReleaseDate:   candidate.ReleaseDate,
+		Episode:       eval.episode,
+		Reason:        eval.reason,
+	}
+
+	switch eval.outcome {
+	case outcomeExcluded:
+		item.Result = PreviewExcluded
+		return item
+	case outcomeBlacklisted:
+		item.Result = PreviewBlacklisted
+		return item
+	case outcomeSubMismatch:
+		item.Result = PreviewSubMismatch
+		return item
+	case outcomeParseFailed:
+		item.Result = PreviewParseFailed
+		item.ReviewReasons = reviewReasons(eval, false)
+		return item
+	}
+
+	if eval.override != nil {
+		// 人工指定的番剧
+		item.BangumiID = eval.override.BangumiID
+	} else {
+		// 与入库时一样按番剧名和别名匹配已有番剧
+		existing, err := bangumi.Resolve(db, bangumi.EpisodeNames(eval.officialTitle, eval.episode), eval.episode.Season)
+		if err != nil {
+			item.Result = PreviewProcessError
+			item.Reason = fmt.Sprintf("查询番剧失败: %v", err)
+			return item
+		}
+		if existing != nil {
+			item.BangumiID = existing.ID
+			item.OfficialTitle = existing.OfficialTitle
+		}
+		item.ReviewReasons = reviewReasons(eval, existing == nil)
+	}
+	item.NewBangumi = item.BangumiID == 0
+
+	if item.BangumiID != 0 && feed.ID != 0 {
+		var existingCount int64
+		if err := db.Model(&models.RSSItem{}).Where("bangumi_id = ? AND rss_id = ? AND url = ?", item.BangumiID, feed.ID, candidate.TorrentURL).Count(&existingCount).Error; err != nil {
+			item.Result = PreviewProcessError
+			item.Reason = fmt.Sprintf("查询RSS条目失败: %v", err)
+			return item
+		}
+		if existingCount > 0 {
+			item.Result = PreviewDuplicate
+			item.Reason = "条目已存在"
+			return item
+		}
+	}
+
+	// 预览不下载种子，只按URL中能直接取得的infohash检查是否已由其他RSS源收录
+	if infoHash := urlInfoHash(candidate.TorrentURL); infoHash != "" {
+		var existingCount int64
+		if err := db.Model(&models.RSSItem{}).Where("info_hash = ?", infoHash).Count(&existingCount).Error; err != nil {
+			item.Result = PreviewProcessError
+			item.Reason = fmt.Sprintf("查询RSS条目失败: %v", err)
+			return item
+		}
+		if existingCount > 0 {
+			item.Result = PreviewDuplicate
+			item.Reason = "相同种子已收录"
+			return item
+		}
+	}
+
+	item.Result = PreviewAccepted
+	return item
+}
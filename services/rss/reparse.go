@@ -0,0 +1,361 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/services/bangumi"
+	"backend/utils"
+	"backend/utils/parser"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReparseBatchSize = 200
+	reparseSampleLimit      = 100 // 摘要中最多列出的变更条目数
+)
+
+// reparseFields 重新解析时比较的字段，顺序即摘要中字段的顺序
+var reparseFields = []string{
+	"episode", "episode_end", "release_kind", "resolution", "sub", "sub_lang", "sub_form",
+	"video_codec", "bit_depth", "audio_codec", "container", "height", "version", "group",
+}
+
+// ErrReparseRunning 已有重新解析任务在执行
+var ErrReparseRunning = errors.New("已有重新解析任务在执行")
+
+// ReparseOptions 重新解析的范围和方式
+type ReparseOptions struct {
+	FeedID    uint `json:"feed_id" example:"0" description:"只处理指定RSS源的条目，0表示全部"`
+	BangumiID uint `json:"bangumi_id" example:"0" description:"只处理指定番剧的条目，0表示全部"`
+	Apply     bool `json:"apply" example:"false" description:"是否写入数据库，为false时只统计差异"`
+	BatchSize int  `json:"batch_size" example:"200" description:"每批处理的条目数，默认200"`
+}
+
+// ReparseFieldChange 单个字段的变化
+type ReparseFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ReparseChange 单个条目的变化
+type ReparseChange struct {
+	ItemID       uint                 `json:"item_id"`
+	RawTitle     string               `json:"raw_title"`
+	Fields       []ReparseFieldChange `json:"fields,omitempty"`
+	OldBangumiID uint                 `json:"old_bangumi_id"`
+	NewBangumiID uint                 `json:"new_bangumi_id,omitempty"`
+	NewTitle     string               `json:"new_title,omitempty"`
+}
+
+// ReparseSummary 重新解析的差异摘要
+type ReparseSummary struct {
+	Total                int64           `json:"total"`
+	Processed            int             `json:"processed"`
+	MissingRawTitle      int             `json:"missing_raw_title"`
+	ParseFailed          int             `json:"parse_failed"`
+	Overridden           int             `json:"overridden"`
+	Unchanged            int             `json:"unchanged"`
+	Changed              int             `json:"changed"`
+	EpisodeChanges       int             `json:"episode_changes"`
+	BangumiReassignments int             `json:"bangumi_reassignments"`
+	FieldCounts          map[string]int  `json:"field_counts"`
+	Applied              bool            `json:"applied"`
+	Samples              []ReparseChange `json:"samples"`
+}
+
+// ReparseItems 用当前的解析器重新解析已保存的RSS条目，统计集数、分辨率、字幕等字段的变化，
+// 以及按新的解析结果应归入的已有番剧；Apply为true时按批写入数据库。
+// 只会改为已有番剧，不会新建番剧；人工指定过的标题保持不变。progress在每批处理完后调用，可以为nil；
+// ctx取消后在下一批开始前停止，已写入的批次保留
+func ReparseItems(ctx context.Context, db *gorm.DB, opts ReparseOptions, progress func(ReparseSummary)) (*ReparseSummary, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultReparseBatchSize
+	}
+
+	summary := &ReparseSummary{FieldCounts: make(map[string]int), Applied: opts.Apply, Samples: []ReparseChange{}}
+
+	var overrides []models.TitleOverride
+	if err := db.Select("title_hash").Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("加载标题覆盖规则失败: %w", err)
+	}
+	overridden := make(map[string]bool, len(overrides))
+	for _, override := range overrides {
+		overridden[override.TitleHash] = true
+	}
+
+	query := db.Model(&models.RSSItem{})
+	if opts.FeedID > 0 {
+		query = query.Where("rss_id = ?", opts.FeedID)
+	}
+	if opts.BangumiID > 0 {
+		query = query.Where("bangumi_id = ?", opts.BangumiID)
+	}
+	if err := query.Count(&summary.Total).Error; err != nil {
+		return nil, fmt.Errorf("统计RSS条目失败: %w", err)
+	}
+
+	var items []models.RSSItem
+	result := query.FindInBatches(&items, opts.BatchSize, func(tx *gorm.DB, batch int) error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("重新解析已取消: %w", err)
+		}
+
+		var changes []ReparseChange
+		var updates []map[string]interface{}
+		for i := range items {
+			item := &items[i]
+			summary.Processed++
+
+			if item.RawTitle == "" {
+				summary.MissingRawTitle++
+				continue
+			}
+			if overridden[models.TitleHash(item.RawTitle)] {
+				summary.Overridden++
+				continue
+			}
+
+			change, values, err := reparseItem(db, item)
+			if err != nil {
+				return err
+			}
+			if change == nil {
+				summary.ParseFailed++
+				continue
+			}
+			if len(values) == 0 {
+				summary.Unchanged++
+				continue
+			}
+
+			summary.Changed++
+			for _, field := range change.Fields {
+				summary.FieldCounts[field.Field]++
+			}
+			if _, ok := values["episode"]; ok {
+				summary.EpisodeChanges++
+			} else if _, ok := values["episode_end"]; ok {
+				summary.EpisodeChanges++
+			}
+			if change.NewBangumiID != 0 {
+				summary.BangumiReassignments++
+			}
+			if len(summary.Samples) < reparseSampleLimit {
+				summary.Samples = append(summary.Samples, *change)
+			}
+			changes = append(changes, *change)
+			updates = append(updates, values)
+		}
+
+		if opts.Apply && len(changes) > 0 {
+			if err := applyReparseChanges(db, changes, updates); err != nil {
+				return fmt.Errorf("第%d批写入失败: %w", batch, err)
+			}
+		}
+
+		utils.LogInfo(fmt.Sprintf("重新解析RSS条目: 已处理 %d/%d，变化 %d", summary.Processed, summary.Total, summary.Changed))
+		if progress != nil {
+			progress(summary.snapshot())
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return summary, result.Error
+	}
+	return summary, nil
+}
+
+// snapshot 复制摘要，供其他goroutine读取进度
+func (s *ReparseSummary) snapshot() ReparseSummary {
+	copied := *s
+	copied.FieldCounts = make(map[string]int, len(s.FieldCounts))
+	for field, count := range s.FieldCounts {
+		copied.FieldCounts[field] = count
+	}
+	copied.Samples = append([]ReparseChange(nil), s.Samples...)
+	return copied
+}
+
+// reparseItem 重新解析单个条目，解析失败时返回nil；values为需要更新的列
+func reparseItem(db *gorm.DB, item *models.RSSItem) (*ReparseChange, map[string]interface{}, error) {
+	episodeInfo := parser.RawParser(item.RawTitle, "")
+	if episodeInfo == nil {
+		return nil, nil, nil
+	}
+
+	updated := *item
+	applyEpisodeInfo(&updated, episodeInfo)
+
+	change := &ReparseChange{ItemID: item.ID, RawTitle: item.RawTitle, OldBangumiID: item.BangumiID}
+	oldValues, newValues := reparseValues(item), reparseValues(&updated)
+	values := make(map[string]interface{})
+	for _, field := range reparseFields {
+		if oldValues[field] != newValues[field] {
+			change.Fields = append(change.Fields, ReparseFieldChange{Field: field, Old: oldValues[field], New: newValues[field]})
+			values[field] = newValues[field]
+		}
+	}
+
+	// 当前番剧的名称或别名与新的解析结果不符时，改为按解析结果匹配到的已有番剧
+	names := bangumi.EpisodeNames(episodeTitle(episodeInfo), episodeInfo)
+	matched, err := bangumi.Matches(db, item.BangumiID, names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("匹配番剧失败: %w", err)
+	}
+	if !matched {
+		resolved, err := bangumi.Resolve(db, names, episodeInfo.Season)
+		if err != nil {
+			return nil, nil, fmt.Errorf("匹配番剧失败: %w", err)
+		}
+		if resolved != nil && resolved.ID != item.BangumiID {
+			change.NewBangumiID = resolved.ID
+			change.NewTitle = resolved.OfficialTitle
+			values["bangumi_id"] = resolved.ID
+			values["title"] = resolved.OfficialTitle
+		}
+	}
+	return change, values, nil
+}
+
+// reparseValues 取出条目中参与比较的字段，指针字段取值以便比较
+func reparseValues(item *models.RSSItem) map[string]interface{} {
+	return map[string]interface{}{
+		"episode":      floatValue(item.Episode),
+		"episode_end":  floatValue(item.EpisodeEnd),
+		"release_kind": item.ReleaseKind,
+		"resolution":   item.Resolution,
+		"sub":          item.Sub,
+		"sub_lang":     item.SubLang,
+		"sub_form":     item.SubForm,
+		"video_codec":  item.VideoCodec,
+		"bit_depth":    item.BitDepth,
+		"audio_codec":  item.AudioCodec,
+		"container":    item.Container,
+		"height":       item.Height,
+		"version":      item.Version,
+		"group":        item.Group,
+	}
+}
+
+// floatValue 取出指针的值，nil时返回nil
+func floatValue(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// applyReparseChanges 在一个事务中写入一批条目的变化，并重新关联同一发布的各版本
+func applyReparseChanges(db *gorm.DB, changes []ReparseChange, updates []map[string]interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i, change := range changes {
+			// 集数等发生变化后原有的版本关系可能不再成立，先解除再重新关联
+			updates[i]["superseded_by_id"] = nil
+			if err := tx.Model(&models.RSSItem{}).Where("id = ?", change.ItemID).Updates(updates[i]).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.RSSItem{}).Where("superseded_by_id = ?", change.ItemID).Update("superseded_by_id", nil).Error; err != nil {
+				return err
+			}
+
+			var item models.RSSItem
+			if err := tx.First(&item, change.ItemID).Error; err != nil {
+				return err
+			}
+			if err := linkSupersededVersions(tx, &item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReparseStatus 后台重新解析任务的状态
+type ReparseStatus struct {
+	Running    bool            `json:"running"`
+	Options    ReparseOptions  `json:"options"`
+	Summary    *ReparseSummary `json:"summary,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+var (
+	reparseMu     sync.Mutex
+	reparseState  ReparseStatus
+	reparseCancel context.CancelFunc // 取消进行中的任务
+	reparseDone   chan struct{}      // 任务结束时关闭
+)
+
+// StartReparse 在后台执行重新解析（包括只统计差异的预览），同一时间只允许一个任务
+func StartReparse(db *gorm.DB, opts ReparseOptions) error {
+	reparseMu.Lock()
+	defer reparseMu.Unlock()
+	if reparseState.Running {
+		return ErrReparseRunning
+	}
+
+	now := time.Now()
+	reparseState = ReparseStatus{Running: true, Options: opts, StartedAt: &now}
+	var ctx context.Context
+	ctx, reparseCancel = context.WithCancel(context.Background())
+	reparseDone = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		summary, err := ReparseItems(ctx, db, opts, func(progress ReparseSummary) {
+			reparseMu.Lock()
+			reparseState.Summary = &progress
+			reparseMu.Unlock()
+		})
+
+		reparseMu.Lock()
+		defer reparseMu.Unlock()
+		finished := time.Now()
+		reparseState.Running = false
+		reparseState.FinishedAt = &finished
+		if summary != nil {
+			reparseState.Summary = summary
+		}
+		if err != nil {
+			reparseState.Error = err.Error()
+			utils.LogError("重新解析RSS条目失败", err)
+			return
+		}
+		utils.LogInfo(fmt.Sprintf("重新解析RSS条目完成: 处理 %d 个，变化 %d 个，集数变化 %d 个，番剧变化 %d 个",
+			summary.Processed, summary.Changed, summary.EpisodeChanges, summary.BangumiReassignments))
+	}(reparseDone)
+	return nil
+}
+
+// ShutdownReparse 等待进行中的重新解析任务结束，ctx到期后取消任务，用于服务关闭
+func ShutdownReparse(ctx context.Context) {
+	reparseMu.Lock()
+	if !reparseState.Running {
+		reparseMu.Unlock()
+		return
+	}
+	cancel, done := reparseCancel, reparseDone
+	reparseMu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		utils.LogWarning("等待重新解析任务结束超时，取消任务", ctx.Err())
+		cancel()
+		<-done
+	}
+}
+
+// GetReparseStatus 获取最近一次后台重新解析任务的状态
+func GetReparseStatus() ReparseStatus {
+	reparseMu.Lock()
+	defer reparseMu.Unlock()
+	return reparseState
+}
@@ -0,0 +1,263 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"backend/utils/parser"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reviewPenalties 各低置信度原因扣除的置信度
+var reviewPenalties = map[string]float64{
+	models.ReviewReasonParseFailed:   1,
+	models.ReviewReasonNoName:        1,
+	models.ReviewReasonNoEpisode:     0.4,
+	models.ReviewReasonSeasonUnclear: 0.3,
+	models.ReviewReasonNewBangumi:    0.2,
+}
+
+// seasonUnclearRE 季度信息之外表示分割放送的标记，出现时自动识别的季度可能不准确
+var seasonUnclearRE = regexp.MustCompile(`(?i)\bpart\s*\d|第.部分|[前后後]篇|\bcour\s*\d|第.クール`)
+
+// reviewReasons 根据筛选结果列出低置信度原因，newBangumi表示条目归入了新建的番剧
+func reviewReasons(eval candidateEvaluation, newBangumi bool) []string {
+	if eval.episode == nil {
+		return []string{models.ReviewReasonParseFailed}
+	}
+
+	var reasons []string
+	if eval.officialTitle == "" {
+		reasons = append(reasons, models.ReviewReasonNoName)
+	}
+	if eval.episode.ReleaseKind == parser.ReleaseKindEpisode && eval.episode.EpisodeStart == 0 {
+		reasons = append(reasons, models.ReviewReasonNoEpisode)
+	}
+	if eval.episode.SeasonRaw == "" && seasonUnclearRE.MatchString(eval.episode.NameZh+" "+eval.episode.NameEn+" "+eval.episode.NameJp) {
+		reasons = append(reasons, models.ReviewReasonSeasonUnclear)
+	}
+	if newBangumi {
+		reasons = append(reasons, models.ReviewReasonNewBangumi)
+	}
+	return reasons
+}
+
+// reviewConfidence 根据低置信度原因计算置信度
+func reviewConfidence(reasons []string) float64 {
+	confidence := 1.0
+	for _, reason := range reasons {
+		confidence -= reviewPenalties[reason]
+	}
+	if confidence < 0 {
+		return 0
+	}
+	return confidence
+}
+
+// enqueueReview 将低置信度的条目加入待审核队列，同一RSS源的相同标题和种子只记录一次
+func enqueueReview(db *gorm.DB, rssID uint, candidate ReleaseCandidate, eval candidateEvaluation, reasons []string, rssItemID *uint) {
+	titleHash := models.TitleHash(candidate.RawTitle)
+
+	var count int64
+	if err := db.Model(&models.ReviewItem{}).Where("rss_id = ? AND title_hash = ? AND torrent_url = ?", rssID, titleHash, candidate.TorrentURL).Count(&count).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 查询待审核条目失败", rssID), err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	review := models.ReviewItem{
+		RssID:       rssID,
+		RawTitle:    candidate.RawTitle,
+		TitleHash:   titleHash,
+		TorrentURL:  candidate.TorrentURL,
+		Homepage:    candidate.Homepage,
+		ReleaseDate: candidate.ReleaseDate,
+		Reasons:     strings.Join(reasons, ","),
+		Confidence:  reviewConfidence(reasons),
+		ParsedTitle: eval.officialTitle,
+		RSSItemID:   rssItemID,
+		Status:      models.ReviewStatusPending,
+	}
+	if eval.episode != nil {
+		episode := eval.episode.EpisodeStart
+		review.ParsedEpisode = &episode
+	}
+
+	if err := db.Create(&review).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 记录待审核条目失败: %s", rssID, candidate.RawTitle), err)
+		return
+	}
+	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 标题 '%s' 置信度较低(%s)，已加入待审核队列", rssID, candidate.RawTitle, review.Reasons))
+}
+
+// ingestOverride 按人工指定规则将条目归入指定番剧
+func ingestOverride(ctx context.Context, db *gorm.DB, feed models.RSSFeed, candidate ReleaseCandidate, eval candidateEvaluation) ingestOutcome {
+	var bangumi models.Bangumi
+	if err := db.First(&bangumi, eval.override.BangumiID).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 人工指定的番剧[ID:%d]不存在", feed.ID, eval.override.BangumiID), err)
+		return outcomeFailed
+	}
+
+	rssItem := newRSSItem(feed.ID, candidate, eval.episode, bangumi.ID, bangumi.OfficialTitle, eval.group)
+	setItemEpisode(&rssItem, eval.override.Episode)
+	outcome := saveRSSItem(ctx, db, feed.ID, &rssItem)
+	if outcome == outcomeCreated {
+		utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 按人工指定规则添加RSS条目: %s", feed.ID, bangumi.OfficialTitle))
+	}
+	return outcome
+}
+
+// setItemEpisode 使用人工指定的集数，为nil时保留解析结果
+func setItemEpisode(item *models.RSSItem, episode *float64) {
+	if episode == nil {
+		return
+	}
+	start, end := *episode, *episode
+	item.Episode = &start
+	item.EpisodeEnd = &end
+	item.ReleaseKind = parser.ReleaseKindEpisode
+}
+
+// AssignReviewItem 将待审核条目归入指定番剧和集数，并记录覆盖规则供之后相同的标题使用
+func AssignReviewItem(db *gorm.DB, review *models.ReviewItem, bangumiID uint, episode *float64) error {
+	var bangumi models.Bangumi
+	if err := db.First(&bangumi, bangumiID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("ID为%d的番剧不存在", bangumiID)
+		}
+		return err
+	}
+
+	var item models.RSSItem
+	found := false
+	if review.RSSItemID != nil {
+		err := db.First(&item, *review.RSSItemID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		found = err == nil
+	}
+
+	if found {
+		// 已自动入库的条目改为指定的番剧和集数
+		item.BangumiID = bangumi.ID
+		item.Title = bangumi.OfficialTitle
+		setItemEpisode(&item, episode)
+	} else {
+		candidate := ReleaseCandidate{
+			RawTitle:    review.RawTitle,
+			TorrentURL:  review.TorrentURL,
+			Homepage:    review.Homepage,
+			ReleaseDate: review.ReleaseDate,
+		}
+		// 解析失败时只保存基本信息
+		episodeInfo := parser.RawParser(review.RawTitle, "")
+		group := ""
+		if episodeInfo != nil {
+			group = episodeInfo.Group
+		}
+		item = newRSSItem(review.RssID, candidate, episodeInfo, bangumi.ID, bangumi.OfficialTitle, group)
+		setItemEpisode(&item, episode)
+		// 在事务外下载种子元数据，避免网络请求期间占用数据库事务
+		fillTorrentMeta(context.Background(), &item)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if found {
+			if err := tx.Save(&item).Error; err != nil {
+				return err
+			}
+		} else if outcome, ok := checkDuplicateURL(tx, review.RssID, &item); ok {
+			if createRSSItem(tx, review.RssID, &item) == outcomeFailed {
+				return fmt.Errorf("保存RSS条目失败")
+			}
+		} else if outcome == outcomeFailed {
+			return fmt.Errorf("保存RSS条目失败")
+		}
+
+		now := time.Now()
+		review.Status = models.ReviewStatusAssigned
+		review.BangumiID = &bangumi.ID
+		review.Episode = episode
+		review.ResolvedAt = &now
+		if item.ID != 0 {
+			review.RSSItemID = &item.ID
+		}
+		if err := tx.Save(review).Error; err != nil {
+			return err
+		}
+
+		return saveTitleOverride(tx, models.TitleOverride{RawTitle: review.RawTitle, BangumiID: bangumi.ID, Episode: episode})
+	})
+}
+
+// DiscardReviewItem 忽略待审核条目，删除已自动入库的RSS条目，并记录忽略规则
+func DiscardReviewItem(db *gorm.DB, review *models.ReviewItem) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if review.RSSItemID != nil {
+			if err := deleteRSSItemVersion(tx, *review.RSSItemID); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		review.Status = models.ReviewStatusDiscarded
+		review.ResolvedAt = &now
+		if err := tx.Save(review).Error; err != nil {
+			return err
+		}
+
+		return saveTitleOverride(tx, models.TitleOverride{RawTitle: review.RawTitle, Discard: true})
+	})
+}
+
+// deleteRSSItemVersion 删除RSS条目，并为被它取代的旧版本重新关联仍存在的新版本，
+// 避免旧版本因指向已删除的条目而一直被隐藏
+func deleteRSSItemVersion(tx *gorm.DB, id uint) error {
+	var olderIDs []uint
+	if err := tx.Model(&models.RSSItem{}).Where("superseded_by_id = ?", id).Pluck("id", &olderIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&models.RSSItem{}, id).Error; err != nil {
+		return err
+	}
+	if len(olderIDs) == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&models.RSSItem{}).Where("id IN ?", olderIDs).Update("superseded_by_id", nil).Error; err != nil {
+		return err
+	}
+	for _, olderID := range olderIDs {
+		var older models.RSSItem
+		if err := tx.First(&older, olderID).Error; err != nil {
+			return err
+		}
+		if err := linkSupersededVersions(tx, &older); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveTitleOverride 按原始标题创建或更新覆盖规则
+func saveTitleOverride(db *gorm.DB, override models.TitleOverride) error {
+	override.TitleHash = models.TitleHash(override.RawTitle)
+
+	var existing models.TitleOverride
+	err := db.Where("title_hash = ?", override.TitleHash).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	override.ID = existing.ID
+	override.CreatedAt = existing.CreatedAt
+	return db.Save(&override).Error
+}
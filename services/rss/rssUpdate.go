@@ -3,31 +3,48 @@ package rss
 import (
 	"backend/models"
 	"backend/services/activity"
+	"backend/services/bangumi"
+	"backend/services/filter"
 	"backend/utils"
 	"backend/utils/parser"
+	"context"
 	"fmt"
-	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// UpdateRSSFeeds 更新所有RSS订阅源
-func UpdateRSSFeeds(db *gorm.DB, force bool) error {
+// UpdateRSSFeeds 更新所有RSS订阅源，trigger为触发方式（见models.RunTrigger常量）；
+// ctx取消时不再处理新的订阅源，正在抓取的分页中止
+func UpdateRSSFeeds(ctx context.Context, db *gorm.DB, trigger string, force bool) error {
+	return updateRSSFeeds(ctx, db, trigger, force, nil)
+}
+
+// updateRSSFeeds 更新所有RSS订阅源，job不为nil时向任务汇报进度
+func updateRSSFeeds(ctx context.Context, db *gorm.DB, trigger string, force bool, job *UpdateJob) error {
 	var rssFeeds []models.RSSFeed
 
 	// 启用GORM调试模式，显示SQL语句
 	db = db.Debug()
 	utils.LogInfo("启用GORM调试模式，显示SQL语句")
 
-	// 获取所有RSS订阅源
-	result := db.Find(&rssFeeds)
+	// 获取所有启用的RSS订阅源，定时更新时跳过已暂停和未到运行时间的订阅源
+	query := db.Where("enabled = ?", true)
+	if trigger == models.RunTriggerScheduler {
+		query = query.Where("paused = ?", false)
+	}
+	if !force {
+		query = query.Where("next_run_at IS NULL OR next_run_at <= ?", time.Now())
+	}
+	result := query.Find(&rssFeeds)
 	if result.Error != nil {
 		return fmt.Errorf("获取RSS订阅源失败: %v", result.Error)
 	}
 
 	utils.LogInfo(fmt.Sprintf("开始更新%d个RSS订阅源", len(rssFeeds)))
+	job.setFeedsTotal(len(rssFeeds))
 
 	// 使用工作池并发处理
 	numWorkers := 50 // 设置并发工作协程数量
@@ -43,36 +60,51 @@ func UpdateRSSFeeds(db *gorm.DB, force bool) error {
 					defer func() {
 						if r := recover(); r != nil {
 							utils.LogError(fmt.Sprintf("主工作协程 %d 处理订阅源[ID:%d] 发生panic: %v", workerID, feed.ID, r), nil)
+							job.feedDone(feed, fmt.Errorf("panic: %v", r))
 							results <- fmt.Errorf("主工作协程 %d panic: %v", workerID, r)
 						}
 					}()
 
+					// 已取消时跳过剩余的订阅源
+					if ctx.Err() != nil {
+						job.feedDone(feed, nil)
+						results <- nil
+						return
+					}
+
 					// 检查是否需要更新
 					if !shouldUpdate(db, feed, force) {
+						job.feedDone(feed, nil)
 						results <- nil // No error, just skip
 						return         // 跳过时也要确保发送结果
 					}
 
-					utils.LogInfo(fmt.Sprintf("工作协程 %d 开始处理订阅源[ID:%d] 配置: UpdateInterval=%d小时 ParserType=%s", workerID, feed.ID, feed.UpdateInterval, feed.ParserType))
-
-					// 根据解析器类型处理RSS源
-					switch feed.ParserType {
-					case "mikanani":
-						utils.LogInfo(fmt.Sprintf("工作协程 %d 开始处理Mikan RSS源 %s", workerID, feed.Name))
-						err := processMikanFeed(db, feed)
-						utils.LogInfo(fmt.Sprintf("工作协程 %d 处理Mikan RSS源 %s 完成", workerID, feed.Name))
-						if err != nil {
-							utils.LogError(fmt.Sprintf("工作协程 %d 处理Mikan RSS源 %s 失败", workerID, feed.Name), err)
-							results <- fmt.Errorf("处理Mikan RSS源 %s 失败: %v", feed.Name, err)
-						} else {
-							results <- nil
-						}
-					case "generic_rss":
-						utils.LogInfo(fmt.Sprintf("工作协程 %d 暂不支持的解析器类型: %s", workerID, feed.ParserType))
-						results <- fmt.Errorf("暂不支持的解析器类型: %s", feed.ParserType)
-					default:
-						utils.LogInfo(fmt.Sprintf("工作协程 %d 未知的解析器类型: %s", workerID, feed.ParserType))
-						results <- fmt.Errorf("未知的解析器类型: %s", feed.ParserType)
+					// 标记为运行中，避免与上一轮未结束的更新或手动更新重复处理
+					claimed, err := claimFeed(db, feed.ID, time.Now())
+					if err != nil {
+						job.feedDone(feed, err)
+						results <- fmt.Errorf("标记RSS源 %s 运行状态失败: %v", feed.Name, err)
+						return
+					}
+					if !claimed {
+						utils.LogInfo(fmt.Sprintf("工作协程 %d 跳过订阅源[ID:%d]：正在由其他任务更新", workerID, feed.ID))
+						job.feedDone(feed, ErrFeedRunning)
+						results <- nil
+						return
+					}
+					defer releaseFeed(db, feed.ID)
+					defer keepFeedClaimed(db, feed.ID)()
+
+					utils.LogInfo(fmt.Sprintf("工作协程 %d 开始处理订阅源[ID:%d] 配置: UpdateInterval=%d小时 CronExpr=%q ParserType=%s", workerID, feed.ID, feed.UpdateInterval, feed.CronExpr, feed.ParserType))
+
+					err = runFeed(ctx, db, feed, trigger, force, job.trackFeed())
+					job.feedDone(feed, err)
+					utils.LogInfo(fmt.Sprintf("工作协程 %d 处理RSS源 %s 完成", workerID, feed.Name))
+					if err != nil {
+						utils.LogError(fmt.Sprintf("工作协程 %d 处理RSS源 %s 失败", workerID, feed.Name), err)
+						results <- fmt.Errorf("处理RSS源 %s 失败: %v", feed.Name, err)
+					} else {
+						results <- nil
 					}
 				}() // 立即执行这个匿名函数，以便defer recover生效
 			}
@@ -94,6 +126,10 @@ func UpdateRSSFeeds(db *gorm.DB, force bool) error {
 		}
 	}
 
+	if ctx.Err() != nil {
+		utils.LogInfo("RSS订阅源更新任务已取消")
+		return ctx.Err()
+	}
 	utils.LogInfo("所有RSS订阅源更新任务完成")
 
 	// 如果有错误，返回第一个错误
@@ -105,7 +141,12 @@ func UpdateRSSFeeds(db *gorm.DB, force bool) error {
 }
 
 // UpdateSingleRSSFeed 更新单个RSS订阅源
-func UpdateSingleRSSFeed(db *gorm.DB, feedID uint) error {
+func UpdateSingleRSSFeed(ctx context.Context, db *gorm.DB, feedID uint) error {
+	return updateSingleRSSFeed(ctx, db, feedID, nil)
+}
+
+// updateSingleRSSFeed 更新单个RSS订阅源，job不为nil时向任务汇报进度
+func updateSingleRSSFeed(ctx context.Context, db *gorm.DB, feedID uint, job *UpdateJob) error {
 	var rssFeed models.RSSFeed
 
 	// 获取指定RSS订阅源
@@ -114,23 +155,26 @@ func UpdateSingleRSSFeed(db *gorm.DB, feedID uint) error {
 		return fmt.Errorf("获取RSS订阅源失败: %v", result.Error)
 	}
 
+	claimed, err := claimFeed(db, feedID, time.Now())
+	if err != nil {
+		return fmt.Errorf("标记RSS源运行状态失败: %v", err)
+	}
+	if !claimed {
+		return ErrFeedRunning
+	}
+	defer releaseFeed(db, feedID)
+	defer keepFeedClaimed(db, feedID)()
+
 	utils.LogInfo(fmt.Sprintf("开始更新单个RSS订阅源 ID:%d", feedID))
 
-	// 根据解析器类型处理RSS源
-	switch rssFeed.ParserType {
-	case "mikanani":
-		utils.LogInfo(fmt.Sprintf("开始处理Mikan RSS源 %s", rssFeed.Name))
-		err := processMikanFeed(db, rssFeed)
-		if err != nil {
-			utils.LogError(fmt.Sprintf("处理Mikan RSS源 %s 失败", rssFeed.Name), err)
-			return err
-		}
-		utils.LogInfo(fmt.Sprintf("处理Mikan RSS源 %s 完成", rssFeed.Name))
-	case "generic_rss":
-		return fmt.Errorf("暂不支持的解析器类型: %s", rssFeed.ParserType)
-	default:
-		return fmt.Errorf("未知的解析器类型: %s", rssFeed.ParserType)
+	// 手动更新单个订阅源时忽略分页缓存，确保重新处理全部条目
+	err = runFeed(ctx, db, rssFeed, models.RunTriggerSingle, true, job.trackFeed())
+	job.feedDone(rssFeed, err)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("处理RSS源 %s 失败", rssFeed.Name), err)
+		return err
 	}
+	utils.LogInfo(fmt.Sprintf("处理RSS源 %s 完成", rssFeed.Name))
 
 	return nil
 }
@@ -140,45 +184,117 @@ func shouldUpdate(db *gorm.DB, feed models.RSSFeed, force bool) bool {
 	if force {
 		return true
 	}
-	// 根据最后更新时间判断是否需要更新
-	result := time.Since(feed.UpdatedAt).Hours() >= float64(feed.UpdateInterval)
-	utils.LogInfo(fmt.Sprintf("更新间隔计算：时间差%.1f小时 >= 间隔%d小时 -> %t", time.Since(feed.UpdatedAt).Hours(), feed.UpdateInterval, result))
+	now := time.Now()
+	if feed.NextRunAt != nil {
+		result := !now.Before(*feed.NextRunAt)
+		utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 下次运行时间%s -> %t", feed.ID, feed.NextRunAt.Format("2006-01-02 15:04:05"), result))
+		return result
+	}
+	if feed.LastCheckedAt == nil {
+		return true
+	}
+	// 尚未计算过下次运行时间的订阅源（如升级前创建的），根据最后一次尝试更新的时间推算
+	next := NextRunTime(feed, *feed.LastCheckedAt, nil, 0)
+	result := !now.Before(next)
+	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 按最后更新时间推算下次运行时间%s(连续失败%d次) -> %t", feed.ID, next.Format("2006-01-02 15:04:05"), feed.ConsecutiveFailures, result))
 	return result
 }
 
-// processMikanFeed 处理Mikan RSS源
-func processMikanFeed(db *gorm.DB, feed models.RSSFeed) error {
-	utils.LogInfo(fmt.Sprintf("开始处理RSS源[ID:%d] 名称:%s", feed.ID, feed.Name))
+// 失败退避配置
+const (
+	minBackoffInterval   = 10 * time.Minute // 更新间隔为0时退避的起始间隔
+	maxBackoffInterval   = 24 * time.Hour   // 退避间隔上限
+	feedDisableThreshold = 10               // 连续失败达到该次数后自动停用
+)
+
+// PollInterval 计算RSS源的轮询间隔，连续失败时按2的指数倍退避
+func PollInterval(feed models.RSSFeed) time.Duration {
+	interval := time.Duration(feed.UpdateInterval) * time.Hour
+	if feed.ConsecutiveFailures <= 0 {
+		return interval
+	}
+
+	backoff := interval
+	if backoff < minBackoffInterval {
+		backoff = minBackoffInterval
+	}
+	for i := 0; i < feed.ConsecutiveFailures && backoff < maxBackoffInterval; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoffInterval {
+		backoff = maxBackoffInterval
+	}
+	if backoff < interval {
+		backoff = interval
+	}
+	return backoff
+}
+
+// runFeed 处理RSS源，记录运行统计和健康状态
+// stats由调用方创建，便于手动更新任务汇总进度；ctx取消时不计入连续失败次数
+func runFeed(ctx context.Context, db *gorm.DB, feed models.RSSFeed, trigger string, force bool, stats *runStats) error {
+	run := startRun(db, feed, trigger)
+
+	pageErrors, err := processFeed(ctx, db, feed, force, stats)
+	if ctx.Err() != nil {
+		err = fmt.Errorf("更新已取消: %w", ctx.Err())
+		finishRun(db, run, stats, err)
+		return err
+	}
+	pagesTotal := int(stats.pagesTotal)
+	if err == nil && pagesTotal > 0 && len(pageErrors) == pagesTotal {
+		err = fmt.Errorf("全部%d个分页处理失败: %v", pagesTotal, pageErrors[0])
+	}
+	finishRun(db, run, stats, err)
 
-	// 解析URL获取主机名
-	parsedURL, err := url.Parse(feed.URL)
 	if err != nil {
-		return fmt.Errorf("解析URL失败: %v", err)
+		markFeedFailed(db, feed, err)
+		return err
 	}
 
-	// 获取主机名
-	hostname := parsedURL.Host
+	itemsCreated := int(stats.itemsCreated)
+	activityContent := fmt.Sprintf("更新RSS源 \"%s\"，抓取%d个分页（%d个未变化），新增%d个条目", feed.Name, pagesTotal, stats.pagesUnchanged, itemsCreated)
+	var partialErr string
+	if len(pageErrors) > 0 {
+		partialErr = fmt.Sprintf("%d/%d个分页处理失败: %v", len(pageErrors), pagesTotal, pageErrors[0])
+	}
+	markFeedUpdated(db, feed, itemsCreated, partialErr, activityContent)
+	return nil
+}
+
+// processFeed 使用订阅源对应的解析器抓取所有分页并收录新条目，返回各分页的处理错误
+// 未修改（304）或内容哈希未变化的分页直接跳过，force为true时忽略分页缓存
+func processFeed(ctx context.Context, db *gorm.DB, feed models.RSSFeed, force bool, stats *runStats) ([]error, error) {
+	feedParser, ok := GetFeedParser(feed.ParserType)
+	if !ok {
+		return nil, fmt.Errorf("未知的解析器类型: %s", feed.ParserType)
+	}
 
-	// 判断是否为Mikan网站
-	isMikan := strings.Contains(hostname, "mikanani")
+	utils.LogInfo(fmt.Sprintf("开始处理RSS源[ID:%d] 名称:%s 解析器:%s", feed.ID, feed.Name, feed.ParserType))
 
-	// 处理分页逻辑
-	pageStart := 1
-	pageEnd := 1
-	if feed.PageStart != nil && feed.PageEnd != nil && *feed.PageEnd >= *feed.PageStart {
-		pageStart = *feed.PageStart
-		pageEnd = *feed.PageEnd
+	// 获取全局设置
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		return nil, fmt.Errorf("获取全局设置失败: %v", err)
 	}
 
-	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 开始处理分页，范围[%d-%d]", feed.ID, pageStart, pageEnd))
+	cf, err := newCandidateFilter(db, settings, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	pageURLs := feedParser.PageURLs(feed)
+	atomic.StoreInt64(&stats.pagesTotal, int64(len(pageURLs)))
+	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 开始处理%d个分页", feed.ID, len(pageURLs)))
 
 	// 使用工作池并发处理分页
+	// 实际出站请求的速率与并发由utils.Outbound按主机限制，这里的协程数只决定排队的分页数
 	numPageWorkers := 250 // 设置并发处理分页的协程数量，可以根据实际情况调整
-	pageJobs := make(chan string, pageEnd-pageStart+1)
-	pageResults := make(chan error, pageEnd-pageStart+1)
+	pageJobs := make(chan string, len(pageURLs))
+	pageResults := make(chan error, len(pageURLs))
 
 	// 启动分页工作协程
-	for w := 0; w < numPageWorkers; w++ {
+	for w := 0; w < numPageWorkers && w < len(pageURLs); w++ {
 		go func(workerID int, pageJobs <-chan string, pageResults chan<- error) {
 			for pageURL := range pageJobs {
 				// 使用defer确保即使发生panic也能向results通道发送信号
@@ -186,251 +302,465 @@ func processMikanFeed(db *gorm.DB, feed models.RSSFeed) error {
 					defer func() {
 						if r := recover(); r != nil {
 							utils.LogError(fmt.Sprintf("分页工作协程 %d 处理URL %s 发生panic: %v", workerID, pageURL, r), nil)
+							atomic.AddInt64(&stats.pagesFailed, 1)
 							pageResults <- fmt.Errorf("分页工作协程 %d panic: %v", workerID, r)
 						}
 					}()
 
+					// 已取消时不再抓取剩余的分页
+					if err := ctx.Err(); err != nil {
+						pageResults <- err
+						return
+					}
+
 					utils.LogInfo(fmt.Sprintf("分页工作协程 %d 抓取分页URL: %s", workerID, pageURL))
-					// 获取RSS内容，增加重试和延迟
-					rssContent, err := utils.FetchURLContentWithRetry(pageURL, 3, 2*time.Second)
+					// 获取RSS内容，使用条件请求并增加重试和延迟
+					page, err := fetchPage(ctx, db, feed, pageURL, force)
 					if err != nil {
 						utils.LogError(fmt.Sprintf("分页工作协程 %d 获取RSS内容失败: %s", workerID, pageURL), err)
+						atomic.AddInt64(&stats.pagesFailed, 1)
 						pageResults <- fmt.Errorf("获取RSS内容失败: %s, %v", pageURL, err)
 						return // 发生错误时返回，确保发送一个结果
 					}
 
-					// 解析RSS条目链接
-					itemLinks, err := parser.ParseRSS(string(rssContent))
-					if err != nil {
-						utils.LogError(fmt.Sprintf("分页工作协程 %d 解析RSS内容失败: %s", workerID, pageURL), err)
-						pageResults <- fmt.Errorf("解析RSS内容失败: %s, %v", pageURL, err)
-						return // 发生错误时返回，确保发送一个结果
+					if page.unchanged {
+						savePageCache(db, page.cache)
+						atomic.AddInt64(&stats.pagesUnchanged, 1)
+						pageResults <- nil
+						return
 					}
 
-					// 获取全局设置
-					settings, err := models.GetGlobalSettings()
+					atomic.AddInt64(&stats.pagesFetched, 1)
+					candidates, err := feedParser.ParsePage(ctx, feed, pageURL, page.content)
 					if err != nil {
-						utils.LogError("获取全局设置失败", err)
-						pageResults <- fmt.Errorf("分页工作协程 %d 获取全局设置失败: %v", workerID, err)
+						utils.LogError(fmt.Sprintf("分页工作协程 %d 解析RSS内容失败: %s", workerID, pageURL), err)
+						atomic.AddInt64(&stats.pagesFailed, 1)
+						pageResults <- fmt.Errorf("解析RSS内容失败: %s, %v", pageURL, err)
 						return // 发生错误时返回，确保发送一个结果
 					}
 
-					// 合并全局关键词和订阅源关键词
-					var allKeywords []string
-					if settings.GlobalKeywords != "" {
-						for _, k := range strings.Split(settings.GlobalKeywords, ",") {
-							allKeywords = append(allKeywords, strings.TrimSpace(k))
+					itemFailed := false
+					for _, candidate := range candidates {
+						// 已取消时停止收录，已保存的条目保留；不保存分页缓存，下次更新时重新处理该分页
+						if err := ctx.Err(); err != nil {
+							pageResults <- err
+							return
 						}
-					}
-					if feed.Keywords != "" {
-						for _, k := range strings.Split(feed.Keywords, ",") {
-							allKeywords = append(allKeywords, strings.TrimSpace(k))
+						outcome := ingestCandidate(ctx, db, feed, feedParser, cf, candidate)
+						if outcome == outcomeFailed {
+							itemFailed = true
 						}
+						stats.record(outcome)
 					}
 
-					// 合并全局排除关键词和订阅源排除关键词
-					var allExcludeKeywords []string
-					if settings.ExcludeKeywords != "" {
-						for _, k := range strings.Split(settings.ExcludeKeywords, ",") {
-							allExcludeKeywords = append(allExcludeKeywords, strings.TrimSpace(k))
-						}
-					}
-					if feed.ExcludeKeywords != "" {
-						for _, k := range strings.Split(feed.ExcludeKeywords, ",") {
-							allExcludeKeywords = append(allExcludeKeywords, strings.TrimSpace(k))
-						}
+					// 有条目收录失败时不保存分页缓存，下次更新时即使分页未变化也会重试这些条目
+					if itemFailed {
+						utils.LogWarning(fmt.Sprintf("分页工作协程 %d 分页中有条目收录失败，不保存分页缓存: %s", workerID, pageURL), nil)
+					} else {
+						savePageCache(db, page.cache)
 					}
+					pageResults <- nil
+				}() // 立即执行这个匿名函数，以便defer recover生效
+			}
+		}(w, pageJobs, pageResults)
+	}
 
-					// 遍历所有条目链接
-					for _, itemURL := range itemLinks {
-						defer func() {
-							if err := recover(); err != nil {
-								utils.LogError(fmt.Sprintf("分页工作协程 %d 处理Mikan条目 %s 失败: %v", workerID, itemURL, err), nil)
-							}
-						}()
-
-						// 先获取基本信息，不包含海报
-						officialTitle, subGroup, originalTitle, releaseDate, releaseYear, _, _, torrentLink, _, _, err := parser.GetMikanBasicInfo(itemURL)
-						if err != nil {
-							utils.LogError(fmt.Sprintf("分页工作协程 %d 解析Mikan条目基本信息失败 %s", workerID, itemURL), err)
-							continue
-						}
+	// 发送分页任务
+	for _, pageURL := range pageURLs {
+		pageJobs <- pageURL
+	}
+	close(pageJobs)
 
-						// 跳过无title的条目，防止污染bangumi_id=1
-						if officialTitle == "" {
-							utils.LogError(fmt.Sprintf("跳过无效条目：officialTitle为空，itemURL=%s", itemURL), nil)
-							continue
-						}
+	// 收集分页结果
+	var pageErrors []error
+	for i := 0; i < len(pageURLs); i++ {
+		err := <-pageResults
+		atomic.AddInt64(&stats.pagesDone, 1)
+		if err != nil {
+			pageErrors = append(pageErrors, err)
+		}
+	}
 
-						// 全局排除关键词优先级最高
-						for _, ex := range allExcludeKeywords {
-							if ex != "" && (strings.Contains(originalTitle, ex) || strings.Contains(officialTitle, ex)) {
-								utils.LogInfo(fmt.Sprintf("分页工作协程 %d 命中排除关键词[%s]，跳过", workerID, ex))
-								continue // 跳过该item
-							}
-						}
+	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 完成处理分页，发现 %d 个分页处理错误", feed.ID, len(pageErrors)))
 
-						// 解析原始标题
-						episodeInfo := parser.RawParser(originalTitle, settings.SubGroupBlacklist)
-						if episodeInfo == nil {
-							utils.LogError(fmt.Sprintf("分页工作协程 %d 解析原始标题失败: %s", workerID, originalTitle), nil)
-							continue
-						}
+	// 如果有分页处理错误，记录下来但不中断整个feed的处理
+	if len(pageErrors) > 0 {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 分页处理完成，发现 %d 个分页处理错误", feed.ID, len(pageErrors)), fmt.Errorf("%v", pageErrors))
+	}
 
-						// 检查字幕信息是否符合要求
-						validChineseSubs := []string{"简体", "简日", "简", "CHS", "GB", "简日繁", "简中", "bibili", "Bilibili"}
-						isChineseSub := false
-						for _, validSub := range validChineseSubs {
-							if episodeInfo.Sub == validSub {
-								isChineseSub = true
-								break
-							}
-						}
+	return pageErrors, nil
+}
 
-						if isChineseSub {
-							utils.LogInfo(fmt.Sprintf("分页工作协程 %d 标题 '%s' (字幕 '%s') 符合字幕要求，优先处理", workerID, originalTitle, episodeInfo.Sub))
-							// 字幕符合要求，直接进入后续处理流程
-						} else {
-							// 字幕不符合要求，需要检查关键词（合并后的allKeywords）
-							if len(allKeywords) > 0 {
-								matched := false
-								for _, keyword := range allKeywords {
-									if keyword != "" && (strings.Contains(originalTitle, keyword) || strings.Contains(officialTitle, keyword)) {
-										matched = true
-										utils.LogInfo(fmt.Sprintf("分页工作协程 %d (字幕 '%s' 不符后) 标题匹配关键词[%s]: %s (官方: %s)", workerID, episodeInfo.Sub, keyword, originalTitle, officialTitle))
-										break
-									}
-								}
-								if !matched {
-									utils.LogInfo(fmt.Sprintf("分页工作协程 %d 标题 '%s' (字幕 '%s') 不符合字幕要求，且不匹配任何关键词，跳过", workerID, originalTitle, episodeInfo.Sub))
-									continue
-								}
-								// 字幕不符但关键词匹配，继续处理
-								utils.LogInfo(fmt.Sprintf("分页工作协程 %d 标题 '%s' (字幕 '%s' 不符) 但匹配关键词，继续处理", workerID, originalTitle, episodeInfo.Sub))
-							} else {
-								// 字幕不符合要求，且没有设置关键词，则跳过
-								utils.LogInfo(fmt.Sprintf("分页工作协程 %d 标题 '%s' (字幕 '%s') 不符合字幕要求 (无关键词)，跳过", workerID, originalTitle, episodeInfo.Sub))
-								continue
-							}
-						}
+// candidateFilter 条目筛选配置
+type candidateFilter struct {
+	rules     *filter.Engine
+	blacklist string
+	subtitles parser.SubtitlePreference
+	overrides map[string]models.TitleOverride // 按原始标题哈希索引的人工处理规则
+}
 
-						// 关键词匹配成功后，再获取海报URL
-						posterURL := ""
-						if shouldGetPosterURL := true; shouldGetPosterURL {
-							posterURL, err = parser.GetMikanPosterURL(itemURL)
-							if err != nil {
-								utils.LogError(fmt.Sprintf("分页工作协程 %d 获取海报URL失败: %s", workerID, itemURL), err)
-								// 海报URL获取失败不影响主流程
-							}
-						}
+// newCandidateFilter 合并全局设置、订阅源的旧关键词以及全局和订阅源的筛选规则生成筛选配置
+func newCandidateFilter(db *gorm.DB, settings *models.GlobalSettings, feed models.RSSFeed) (candidateFilter, error) {
+	rules := filter.KeywordRules(filter.TypeExclude, settings.ExcludeKeywords, feed.ExcludeKeywords)
+	rules = append(rules, filter.KeywordRules(filter.TypeInclude, settings.GlobalKeywords, feed.Keywords)...)
+
+	var ruleRecords []models.FilterRule
+	query := db.Where("enabled = ?", true)
+	if feed.ID != 0 {
+		query = query.Where("rss_id IS NULL OR rss_id = ?", feed.ID)
+	} else {
+		query = query.Where("rss_id IS NULL")
+	}
+	if err := query.Order("id").Find(&ruleRecords).Error; err != nil {
+		return candidateFilter{}, fmt.Errorf("获取筛选规则失败: %v", err)
+	}
+	for _, record := range ruleRecords {
+		rules = append(rules, filter.Rule{ID: record.ID, Type: record.Type, Field: record.Field, Mode: record.Mode, Pattern: record.Pattern})
+	}
 
-						// 处理番剧信息
-						bangumiID, err := processOrCreateBangumi(db, officialTitle, releaseYear, episodeInfo.Season, isMikan, posterURL)
-						if err != nil {
-							utils.LogError(fmt.Sprintf("分页工作协程 %d 处理番剧信息失败: %v", workerID, err), nil)
-							continue
-						}
+	engine, err := filter.NewEngine(rules)
+	if err != nil {
+		return candidateFilter{}, fmt.Errorf("编译筛选规则失败: %v", err)
+	}
 
-						if bangumiID == 0 {
-							utils.LogError(fmt.Sprintf("分页工作协程 %d 无效的bangumiID[0] 来自番剧:%s", workerID, officialTitle), nil)
-							continue
-						}
+	// 订阅源未设置字幕语言时使用全局设置
+	subtitleLanguages := feed.SubtitleLanguages
+	if subtitleLanguages == "" {
+		subtitleLanguages = settings.SubtitleLanguages
+	}
+	if subtitleLanguages == "" {
+		subtitleLanguages = parser.DefaultSubtitleLanguages
+	}
+	subtitles, err := parser.ParseSubtitlePreference(subtitleLanguages)
+	if err != nil {
+		return candidateFilter{}, fmt.Errorf("字幕语言设置无效: %v", err)
+	}
 
-						// 创建RSS条目
-						episodeFloat := float64(episodeInfo.Episode)
-						rssItem := models.RSSItem{
-							BangumiID:   bangumiID,
-							RssID:       feed.ID,
-							Title:       officialTitle,
-							URL:         torrentLink,
-							Homepage:    itemURL,
-							Downloaded:  false,
-							Episode:     &episodeFloat,
-							Resolution:  episodeInfo.Resolution,
-							Group:       subGroup,
-							ReleaseDate: releaseDate,
-							Sub:         episodeInfo.Sub,
-						}
+	var overrideRecords []models.TitleOverride
+	if err := db.Find(&overrideRecords).Error; err != nil {
+		return candidateFilter{}, fmt.Errorf("获取标题覆盖规则失败: %v", err)
+	}
+	overrides := make(map[string]models.TitleOverride, len(overrideRecords))
+	for _, record := range overrideRecords {
+		overrides[record.TitleHash] = record
+	}
 
-						// 设置来源
-						if isMikan {
-							rssItem.Source = "mikan"
-						} else if episodeInfo.Source != "" {
-							rssItem.Source = episodeInfo.Source
-						}
+	return candidateFilter{rules: engine, blacklist: settings.SubGroupBlacklist, subtitles: subtitles, overrides: overrides}, nil
+}
 
-						// 检查是否已存在相同的RSS条目
-						var existingItems []models.RSSItem
-						result := db.Where("bangumi_id = ? AND rss_id = ? AND url = ?", bangumiID, feed.ID, torrentLink).Find(&existingItems)
-						if result.Error != nil {
-							utils.LogError("分页工作协程 %d 查询RSS条目失败", result.Error)
-							continue
-						}
+// candidateEvaluation 候选条目的筛选结果，不涉及数据库
+type candidateEvaluation struct {
+	outcome       ingestOutcome   // 筛选通过时为outcomeCreated
+	reason        string          // 通过或拒绝的原因
+	episode       *parser.Episode // 标题解析结果，解析失败时为nil
+	officialTitle string          // 番剧名
+	group         string          // 字幕组
 
-						if len(existingItems) > 0 {
-							utils.LogInfo(fmt.Sprintf("分页工作协程 %d 发现重复条目[BangumiID:%d URL:%s]，跳过创建", workerID, bangumiID, torrentLink))
-							continue
-						} else {
-							utils.LogInfo(fmt.Sprintf("分页工作协程 %d 确认无重复条目[BangumiID:%d URL:%s]，开始创建新条目", workerID, bangumiID, torrentLink))
-						}
+	override *models.TitleOverride // 命中的人工指定规则
+}
 
-						// 保存RSS条目
-						result = db.Create(&rssItem)
-						if result.Error != nil {
-							utils.LogError("分页工作协程 %d 保存RSS条目失败", result.Error)
-							continue
-						}
+// accepted 条目是否通过筛选
+func (e candidateEvaluation) accepted() bool {
+	return e.outcome == outcomeCreated
+}
 
-						utils.LogInfo(fmt.Sprintf("分页工作协程 %d 成功添加RSS条目: %s (第%d集)", workerID, officialTitle, episodeInfo.Episode))
-					}
+// evaluateCandidate 对候选条目执行标题解析、排除规则、黑名单和字幕筛选
+func evaluateCandidate(cf candidateFilter, candidate ReleaseCandidate) candidateEvaluation {
+	rawTitle := candidate.RawTitle
+	eval := candidateEvaluation{officialTitle: candidate.OfficialTitle, group: candidate.Group}
 
-					// 如果处理完所有条目都没有发生致命错误，发送nil表示成功处理该分页
-					pageResults <- nil
-				}() // 立即执行这个匿名函数，以便defer recover生效
-			}
-		}(w, pageJobs, pageResults)
+	// 解析原始标题，字幕组黑名单单独检查以便区分统计
+	eval.episode = parser.RawParser(rawTitle, "")
+	subject := filterSubject(candidate, eval.episode)
+	if eval.officialTitle == "" {
+		eval.officialTitle = subject.OfficialTitle
+	}
+	if eval.group == "" {
+		eval.group = subject.Group
 	}
 
-	// 发送分页任务
-	for page := pageStart; page <= pageEnd; page++ {
-		var pageURL string
-		if page == 1 {
-			pageURL = feed.URL
-		} else {
-			if strings.HasSuffix(feed.URL, "/") {
-				pageURL = fmt.Sprintf("%s%d", feed.URL, page)
-			} else {
-				pageURL = fmt.Sprintf("%s/%d", feed.URL, page)
-			}
+	// 人工处理过的标题直接按处理结果收录或忽略
+	if override, ok := cf.overrides[models.TitleHash(rawTitle)]; ok {
+		if override.Discard {
+			eval.outcome = outcomeExcluded
+			eval.reason = "命中人工忽略规则"
+			return eval
 		}
-		pageJobs <- pageURL
+		eval.override = &override
+		eval.reason = fmt.Sprintf("命中人工指定规则[番剧ID:%d]", override.BangumiID)
+		eval.outcome = outcomeCreated
+		return eval
 	}
-	close(pageJobs)
 
-	// 收集分页结果
-	var pageErrors []error
-	for i := 0; i < (pageEnd - pageStart + 1); i++ {
-		err := <-pageResults
+	// 排除规则优先级最高，标题解析失败时仍按已有信息匹配
+	if rule, ok := cf.rules.MatchExclude(subject); ok {
+		eval.outcome = outcomeExcluded
+		eval.reason = fmt.Sprintf("命中排除规则[%s]", rule)
+		return eval
+	}
+
+	if eval.episode == nil {
+		eval.outcome = outcomeParseFailed
+		eval.reason = "解析原始标题失败"
+		return eval
+	}
+	if parser.IsGroupBlacklisted(eval.episode.Group, cf.blacklist) || parser.IsGroupBlacklisted(candidate.Group, cf.blacklist) {
+		eval.outcome = outcomeBlacklisted
+		eval.reason = fmt.Sprintf("字幕组[%s]在黑名单中", eval.group)
+		return eval
+	}
+
+	if eval.officialTitle == "" {
+		eval.outcome = outcomeParseFailed
+		eval.reason = "未能从标题中提取番剧名"
+		return eval
+	}
+
+	// 检查字幕语言是否符合偏好，不符合时需要命中包含规则
+	subLang := eval.episode.SubLang
+	if subLang == "" {
+		subLang = "未知"
+	}
+	if cf.subtitles.Accepts(eval.episode.SubLang) {
+		eval.reason = fmt.Sprintf("字幕[%s](%s)符合要求", eval.episode.Sub, subLang)
+	} else if rule, ok := cf.rules.MatchInclude(subject); ok {
+		eval.reason = fmt.Sprintf("字幕[%s](%s)不符但命中包含规则[%s]", eval.episode.Sub, subLang, rule)
+	} else {
+		eval.outcome = outcomeSubMismatch
+		eval.reason = fmt.Sprintf("字幕[%s](%s)不符合要求，且未命中任何包含规则", eval.episode.Sub, subLang)
+		return eval
+	}
+
+	eval.outcome = outcomeCreated
+	return eval
+}
+
+// filterSubject 根据候选条目和标题解析结果生成规则匹配信息
+func filterSubject(candidate ReleaseCandidate, episode *parser.Episode) filter.Subject {
+	subject := filter.Subject{
+		RawTitle:      candidate.RawTitle,
+		OfficialTitle: candidate.OfficialTitle,
+		Group:         candidate.Group,
+		Source:        candidate.Source,
+	}
+	if episode == nil {
+		return subject
+	}
+	if subject.OfficialTitle == "" {
+		subject.OfficialTitle = episodeTitle(episode)
+	}
+	if subject.Group == "" {
+		subject.Group = episode.Group
+	}
+	if subject.Source == "" {
+		subject.Source = episode.Source
+	}
+	subject.Resolution = episode.Resolution
+	subject.Sub = episode.Sub
+	// 集数规则只匹配单集，合集、特别篇和剧场版不参与
+	subject.Episode = episode.EpisodeStart
+	subject.HasEpisode = episode.ReleaseKind == parser.ReleaseKindEpisode && episode.EpisodeStart > 0
+	return subject
+}
+
+// ingestCandidate 对候选条目执行筛选、番剧归类并保存，返回处理结果
+// 解析失败或置信度较低的条目会记录到待审核队列
+func ingestCandidate(ctx context.Context, db *gorm.DB, feed models.RSSFeed, feedParser FeedParser, cf candidateFilter, candidate ReleaseCandidate) (outcome ingestOutcome) {
+	defer func() {
+		if err := recover(); err != nil {
+			utils.LogError(fmt.Sprintf("RSS源[ID:%d] 处理条目 %s 失败: %v", feed.ID, candidate.Homepage, err), nil)
+			outcome = outcomeFailed
+		}
+	}()
+
+	rawTitle := candidate.RawTitle
+	eval := evaluateCandidate(cf, candidate)
+	if !eval.accepted() {
+		utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 标题 '%s' %s，跳过", feed.ID, rawTitle, eval.reason))
+		if eval.outcome == outcomeParseFailed {
+			enqueueReview(db, feed.ID, candidate, eval, reviewReasons(eval, false), nil)
+		}
+		return eval.outcome
+	}
+	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 标题 '%s' %s，继续处理", feed.ID, rawTitle, eval.reason))
+
+	// 命中人工指定规则的条目直接归入指定番剧
+	if eval.override != nil {
+		return ingestOverride(ctx, db, feed, candidate, eval)
+	}
+
+	episodeInfo := eval.episode
+	officialTitle := eval.officialTitle
+
+	// 关键词匹配成功后，再获取海报URL
+	posterURL := ""
+	if resolver, ok := feedParser.(PosterResolver); ok {
+		var err error
+		posterURL, err = resolver.ResolvePoster(ctx, candidate)
 		if err != nil {
-			pageErrors = append(pageErrors, err)
+			utils.LogError(fmt.Sprintf("RSS源[ID:%d] 获取海报URL失败: %s", feed.ID, candidate.Homepage), err)
+			// 海报URL获取失败不影响主流程
 		}
 	}
 
-	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 完成处理分页，发现 %d 个分页处理错误", feed.ID, len(pageErrors)))
+	isMikan := candidate.Source == "mikan"
 
-	// 如果有分页处理错误，记录下来但不中断整个feed的处理
-	if len(pageErrors) > 0 {
-		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 分页处理完成，发现 %d 个分页处理错误", feed.ID, len(pageErrors)), fmt.Errorf("%v", pageErrors))
+	// 按番剧名和别名匹配已有番剧，匹配到时沿用已有番剧的名称；记录是否新建了番剧以便评估置信度
+	names := bangumi.EpisodeNames(officialTitle, episodeInfo)
+	existing, err := bangumi.Resolve(db, names, episodeInfo.Season)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 查询番剧失败", feed.ID), err)
+		return outcomeFailed
+	}
+	newBangumi := existing == nil
+	if existing != nil {
+		officialTitle = existing.OfficialTitle
+	}
+
+	// 处理番剧信息
+	bangumiID, err := processOrCreateBangumi(db, officialTitle, candidate.ReleaseYear, episodeInfo.Season, isMikan, posterURL)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 处理番剧信息失败: %v", feed.ID, err), nil)
+		return outcomeFailed
+	}
+	if bangumiID == 0 {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 无效的bangumiID[0] 来自番剧:%s", feed.ID, officialTitle), nil)
+		return outcomeFailed
+	}
+	if err := bangumi.RecordAliases(db, bangumiID, names); err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 记录番剧[ID:%d]别名失败", feed.ID, bangumiID), err)
 	}
 
-	// 更新RSS源的更新时间
+	rssItem := newRSSItem(feed.ID, candidate, episodeInfo, bangumiID, officialTitle, eval.group)
+	outcome = saveRSSItem(ctx, db, feed.ID, &rssItem)
+	if outcome == outcomeCreated {
+		utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 成功添加RSS条目: %s (%s)", feed.ID, officialTitle, releaseLabel(episodeInfo)))
+		if reasons := reviewReasons(eval, newBangumi); len(reasons) > 0 {
+			enqueueReview(db, feed.ID, candidate, eval, reasons, &rssItem.ID)
+		}
+	}
+	return outcome
+}
+
+// newRSSItem 根据候选条目和解析结果生成RSS条目，episodeInfo可以为nil
+func newRSSItem(rssID uint, candidate ReleaseCandidate, episodeInfo *parser.Episode, bangumiID uint, title, group string) models.RSSItem {
+	rssItem := models.RSSItem{
+		BangumiID:   bangumiID,
+		RssID:       rssID,
+		Title:       title,
+		URL:         candidate.TorrentURL,
+		Homepage:    candidate.Homepage,
+		Downloaded:  false,
+		Group:       group,
+		ReleaseDate: candidate.ReleaseDate,
+		ReleaseKind: parser.ReleaseKindEpisode,
+		Version:     1,
+		Source:      candidate.Source,
+		RawTitle:    candidate.RawTitle,
+		Magnet:      candidate.MagnetURL,
+	}
+	if episodeInfo != nil {
+		applyEpisodeInfo(&rssItem, episodeInfo)
+	}
+	return rssItem
+}
+
+// applyEpisodeInfo 将标题解析结果写入RSS条目，字幕组和来源已有值时保留
+func applyEpisodeInfo(rssItem *models.RSSItem, episodeInfo *parser.Episode) {
+	episodeStart, episodeEnd := episodeInfo.EpisodeStart, episodeInfo.EpisodeEnd
+	rssItem.Episode = &episodeStart
+	rssItem.EpisodeEnd = &episodeEnd
+	rssItem.ReleaseKind = episodeInfo.ReleaseKind
+	rssItem.Resolution = episodeInfo.Resolution
+	rssItem.Sub = episodeInfo.Sub
+	rssItem.SubLang = episodeInfo.SubLang
+	rssItem.SubForm = episodeInfo.SubForm
+	rssItem.VideoCodec = episodeInfo.VideoCodec
+	rssItem.BitDepth = episodeInfo.BitDepth
+	rssItem.AudioCodec = episodeInfo.AudioCodec
+	rssItem.Container = episodeInfo.Container
+	rssItem.Height = episodeInfo.Height
+	rssItem.Version = episodeInfo.Version
+	if rssItem.Group == "" {
+		rssItem.Group = episodeInfo.Group
+	}
+
+	// 设置来源
+	if rssItem.Source == "" {
+		rssItem.Source = episodeInfo.Source
+	}
+}
+
+// saveRSSItem 检查重复后保存RSS条目，并关联同一发布的其他版本；ctx只用于下载种子
+func saveRSSItem(ctx context.Context, db *gorm.DB, rssID uint, rssItem *models.RSSItem) ingestOutcome {
+	if outcome, ok := checkDuplicateURL(db, rssID, rssItem); !ok {
+		return outcome
+	}
+
+	// 同一发布可能以不同URL出现在多个RSS源或镜像站，按infohash全局去重
+	fillTorrentMeta(ctx, rssItem)
+	return createRSSItem(db, rssID, rssItem)
+}
+
+// checkDuplicateURL 检查RSS源中是否已有相同种子URL的条目，可以继续保存时返回true
+func checkDuplicateURL(db *gorm.DB, rssID uint, rssItem *models.RSSItem) (ingestOutcome, bool) {
+	var existingCount int64
+	if err := db.Model(&models.RSSItem{}).Where("bangumi_id = ? AND rss_id = ? AND url = ?", rssItem.BangumiID, rssID, rssItem.URL).Count(&existingCount).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 查询RSS条目失败", rssID), err)
+		return outcomeFailed, false
+	}
+	if existingCount > 0 {
+		utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 发现重复条目[BangumiID:%d URL:%s]，跳过创建", rssID, rssItem.BangumiID, rssItem.URL))
+		return outcomeDuplicate, false
+	}
+	return outcomeCreated, true
+}
+
+// createRSSItem 按infohash去重后保存已填充种子元数据的条目，并关联同一发布的其他版本；不发起网络请求
+func createRSSItem(db *gorm.DB, rssID uint, rssItem *models.RSSItem) ingestOutcome {
+	if rssItem.InfoHash != "" {
+		var duplicate models.RSSItem
+		err := db.Select("id", "bangumi_id", "rss_id").Where("info_hash = ?", rssItem.InfoHash).Limit(1).Find(&duplicate).Error
+		if err != nil {
+			utils.LogError(fmt.Sprintf("RSS源[ID:%d] 按infohash查询RSS条目失败", rssID), err)
+			return outcomeFailed
+		}
+		if duplicate.ID != 0 {
+			utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 种子[%s]已由RSS条目[ID:%d RSS源:%d]收录，跳过创建", rssID, rssItem.InfoHash, duplicate.ID, duplicate.RssID))
+			return outcomeDuplicate
+		}
+	}
+
+	// 保存RSS条目
+	if err := db.Create(rssItem).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 保存RSS条目失败", rssID), err)
+		return outcomeFailed
+	}
+
+	// 关联同一发布的其他版本，失败不影响条目的收录
+	if err := linkSupersededVersions(db, rssItem); err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 关联RSS条目[ID:%d]的版本失败", rssID, rssItem.ID), err)
+	}
+	return outcomeCreated
+}
+
+// markFeedUpdated 记录RSS源更新成功，清零连续失败次数并记录活动
+// partialErr 为部分分页失败时的错误摘要，不计入连续失败
+func markFeedUpdated(db *gorm.DB, feed models.RSSFeed, itemsAdded int, partialErr string, activityContent string) {
 	utils.LogInfo(fmt.Sprintf("准备更新RSS源[ID:%d] 原更新时间：%s", feed.ID, feed.UpdatedAt.Format(time.RFC3339)))
 
 	// 使用事务确保更新操作的原子性
-	err = db.Transaction(func(tx *gorm.DB) error {
-		// 直接更新整个feed对象，不限制字段
-		feed.UpdatedAt = time.Now()
-		updateResult := tx.Save(&feed)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 只更新健康状态相关字段，避免覆盖处理期间管理员对订阅源的修改
+		now := time.Now()
+		updateResult := tx.Model(&models.RSSFeed{}).Where("id = ?", feed.ID).Updates(map[string]interface{}{
+			"updated_at":           now,
+			"last_checked_at":      now,
+			"last_success_at":      now,
+			"last_error":           partialErr,
+			"consecutive_failures": 0,
+			"last_items_added":     itemsAdded,
+		})
 		if updateResult.Error != nil {
 			utils.LogError("更新RSS源更新时间失败", updateResult.Error)
 			return updateResult.Error
@@ -441,7 +771,7 @@ func processMikanFeed(db *gorm.DB, feed models.RSSFeed) error {
 
 			// 记录活动
 			activityService := activity.NewActivityService(tx)
-			activityService.RecordActivity("rss", fmt.Sprintf("更新RSS源 \"%s\"，分页范围[%d-%d]，每页获取新条目", feed.Name, pageStart, pageEnd))
+			activityService.RecordActivity("rss", activityContent)
 		}
 		return nil
 	})
@@ -449,8 +779,63 @@ func processMikanFeed(db *gorm.DB, feed models.RSSFeed) error {
 	if err != nil {
 		utils.LogError("更新RSS源更新时间事务失败", err)
 	}
+}
 
-	return nil
+// markFeedFailed 记录RSS源更新失败，连续失败达到阈值时自动停用
+// 失败时不更新UpdatedAt，以便区分最近一次成功的时间
+func markFeedFailed(db *gorm.DB, feed models.RSSFeed, runErr error) {
+	failures := feed.ConsecutiveFailures + 1
+	updates := map[string]interface{}{
+		"last_checked_at":      time.Now(),
+		"last_error":           runErr.Error(),
+		"consecutive_failures": failures,
+		"last_items_added":     0,
+	}
+	disable := feed.Enabled && failures >= feedDisableThreshold
+	if disable {
+		updates["enabled"] = false
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RSSFeed{}).Where("id = ?", feed.ID).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if disable {
+			utils.LogWarning(fmt.Sprintf("RSS源[ID:%d] 连续失败%d次，已自动停用", feed.ID, failures), runErr)
+			activityService := activity.NewActivityService(tx)
+			activityService.RecordActivity("rss", fmt.Sprintf("RSS源 \"%s\" 连续失败%d次，已自动停用", feed.Name, failures))
+		}
+		return nil
+	})
+	if err != nil {
+		utils.LogError(fmt.Sprintf("记录RSS源[ID:%d]失败状态失败", feed.ID), err)
+	}
+}
+
+// releaseLabel 集数的可读描述，用于日志
+func releaseLabel(episode *parser.Episode) string {
+	switch episode.ReleaseKind {
+	case parser.ReleaseKindBatch:
+		if episode.EpisodeEnd > episode.EpisodeStart {
+			return fmt.Sprintf("第%g-%g集合集", episode.EpisodeStart, episode.EpisodeEnd)
+		}
+		return "合集"
+	case parser.ReleaseKindSpecial:
+		return "特别篇"
+	case parser.ReleaseKindMovie:
+		return "剧场版"
+	}
+	return fmt.Sprintf("第%g集", episode.EpisodeStart)
+}
+
+// episodeTitle 从解析结果中选取番剧名，优先中文名
+func episodeTitle(episode *parser.Episode) string {
+	for _, name := range []string{episode.NameZh, episode.NameEn, episode.NameJp} {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return ""
 }
 
 // processOrCreateBangumi 处理或创建番剧信息
@@ -500,7 +885,7 @@ func processOrCreateBangumi(db *gorm.DB, officialTitle string, releaseYear strin
 				needsSave = true
 				utils.LogInfo(fmt.Sprintf("番剧[ID:%d Title:%s S:%d] 海报链接更新为: %s", bangumi.ID, officialTitle, season, posterURL))
 			}
-		} else if bangumi.PosterLink != nil { // 如果传入的posterURL为空，且数据库中存在海报链接，则清空
+		} else if isMikan && bangumi.PosterLink != nil { // 如果Mikan来源的posterURL为空，且数据库中存在海报链接，则清空
 			bangumi.PosterLink = nil
 			needsSave = true
 			utils.LogInfo(fmt.Sprintf("番剧[ID:%d Title:%s S:%d] 海报链接被清空", bangumi.ID, officialTitle, season))
@@ -0,0 +1,208 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// runningStaleAfter 运行标记超过该时长未刷新时视为上次运行异常退出，允许重新运行
+	runningStaleAfter = 30 * time.Minute
+	// runningHeartbeatEvery 运行期间刷新运行标记的间隔，需远小于runningStaleAfter
+	runningHeartbeatEvery = 5 * time.Minute
+)
+
+// ErrFeedRunning RSS源正在被其他任务更新
+var ErrFeedRunning = errors.New("RSS源正在更新中")
+
+// QuietHours 每天的静默时段[Start, End)，以当天零点起的分钟数表示，End小于Start时表示跨午夜
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// ParseQuietHours 解析"HH:MM"格式的静默时段，两者都为空时返回nil表示不设静默时段
+func ParseQuietHours(start, end string) (*QuietHours, error) {
+	if start == "" && end == "" {
+		return nil, nil
+	}
+	if start == "" || end == "" {
+		return nil, errors.New("静默时段的开始和结束时间需要同时设置")
+	}
+
+	startTime, err := time.Parse("15:04", start)
+	if err != nil {
+		return nil, fmt.Errorf("静默时段开始时间格式无效，应为HH:MM: %s", start)
+	}
+	endTime, err := time.Parse("15:04", end)
+	if err != nil {
+		return nil, fmt.Errorf("静默时段结束时间格式无效，应为HH:MM: %s", end)
+	}
+
+	quiet := &QuietHours{
+		Start: startTime.Hour()*60 + startTime.Minute(),
+		End:   endTime.Hour()*60 + endTime.Minute(),
+	}
+	if quiet.Start == quiet.End {
+		return nil, errors.New("静默时段的开始和结束时间不能相同")
+	}
+	return quiet, nil
+}
+
+// Contains 判断t是否处于静默时段内
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// EndAfter 返回t之后静默时段结束的时间
+func (q *QuietHours) EndAfter(t time.Time) time.Time {
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End/60, q.End%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// NextRunTime 计算RSS源在from之后的下次运行时间：
+// 设置了cron表达式时取表达式的下次触发时间，否则按更新间隔（小时）；
+// 连续失败时不早于退避间隔；落在静默时段内的顺延到静默时段结束，最后加上[0, jitter)的随机延后，
+// 避免大量订阅源在同一时刻触发
+func NextRunTime(feed models.RSSFeed, from time.Time, quiet *QuietHours, jitter time.Duration) time.Time {
+	var next time.Time
+	if feed.CronExpr != "" {
+		if schedule, err := ParseCron(feed.CronExpr); err == nil {
+			next = schedule.Next(from)
+		} else {
+			utils.LogWarning(fmt.Sprintf("RSS源[ID:%d] cron表达式无效，改为按更新间隔运行", feed.ID), err)
+		}
+	}
+	if next.IsZero() {
+		next = from.Add(time.Duration(feed.UpdateInterval) * time.Hour)
+	}
+
+	if feed.ConsecutiveFailures > 0 {
+		if backoff := from.Add(PollInterval(feed)); backoff.After(next) {
+			next = backoff
+		}
+	}
+
+	if quiet.Contains(next) {
+		next = quiet.EndAfter(next)
+	}
+	if jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(jitter))))
+	}
+	return next
+}
+
+// scheduleSettings 从全局设置中读取静默时段和随机延后上限，读取失败时不设静默时段和随机延后
+func scheduleSettings() (*QuietHours, time.Duration) {
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		utils.LogError("获取定时更新设置失败", err)
+		return nil, 0
+	}
+	quiet, err := ParseQuietHours(settings.SchedulerQuietStart, settings.SchedulerQuietEnd)
+	if err != nil {
+		utils.LogWarning("静默时段设置无效，已忽略", err)
+	}
+	return quiet, time.Duration(settings.SchedulerJitterSeconds) * time.Second
+}
+
+// IsFeedRunning 判断RSS源是否正在更新（运行标记未过期）
+func IsFeedRunning(feed models.RSSFeed, now time.Time) bool {
+	return feed.RunningSince != nil && feed.RunningSince.After(now.Add(-runningStaleAfter))
+}
+
+// claimFeed 标记RSS源开始运行，已在运行中时返回false；
+// 通过条件更新保证同一时间只有一个任务能处理该订阅源
+func claimFeed(db *gorm.DB, feedID uint, now time.Time) (bool, error) {
+	result := db.Model(&models.RSSFeed{}).
+		Where("id = ? AND (running_since IS NULL OR running_since < ?)", feedID, now.Add(-runningStaleAfter)).
+		UpdateColumn("running_since", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// keepFeedClaimed 运行期间定时刷新运行标记，避免耗时较长的运行被判定为异常退出而被重复处理；
+// 返回的函数停止刷新，需在releaseFeed之前调用
+func keepFeedClaimed(db *gorm.DB, feedID uint) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(runningHeartbeatEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := db.Model(&models.RSSFeed{}).
+					Where("id = ? AND running_since IS NOT NULL", feedID).
+					UpdateColumn("running_since", time.Now()).Error
+				if err != nil {
+					utils.LogError(fmt.Sprintf("刷新RSS源[ID:%d]运行标记失败", feedID), err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// releaseFeed 清除运行标记，并根据本次运行后的状态计算下次运行时间
+func releaseFeed(db *gorm.DB, feedID uint) {
+	var feed models.RSSFeed
+	if err := db.First(&feed, feedID).Error; err != nil {
+		utils.LogError(fmt.Sprintf("获取RSS源[ID:%d]失败", feedID), err)
+		return
+	}
+
+	quiet, jitter := scheduleSettings()
+	next := NextRunTime(feed, time.Now(), quiet, jitter)
+	updates := map[string]interface{}{"running_since": nil, "next_run_at": next}
+	if err := db.Model(&models.RSSFeed{}).Where("id = ?", feedID).UpdateColumns(updates).Error; err != nil {
+		utils.LogError(fmt.Sprintf("更新RSS源[ID:%d]下次运行时间失败", feedID), err)
+		return
+	}
+	utils.LogInfo(fmt.Sprintf("RSS源[ID:%d] 下次运行时间: %s", feedID, next.Format("2006-01-02 15:04:05")))
+}
+
+// SchedulerSkipReason 返回定时更新当前应跳过的原因（全局暂停或处于静默时段），不需要跳过时返回空字符串
+func SchedulerSkipReason(now time.Time) string {
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		utils.LogError("获取定时更新设置失败", err)
+		return ""
+	}
+	if settings.SchedulerPaused {
+		return "定时更新已暂停"
+	}
+	quiet, err := ParseQuietHours(settings.SchedulerQuietStart, settings.SchedulerQuietEnd)
+	if err != nil {
+		utils.LogWarning("静默时段设置无效，已忽略", err)
+		return ""
+	}
+	if quiet.Contains(now) {
+		return fmt.Sprintf("处于静默时段 %s-%s", settings.SchedulerQuietStart, settings.SchedulerQuietEnd)
+	}
+	return ""
+}
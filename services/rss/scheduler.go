@@ -1,7 +1,12 @@
 package rss
 
 import (
+	"backend/models"
+	"backend/services/leader"
 	"backend/utils"
+	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,66 +15,106 @@ import (
 // RSSUpdateScheduler RSS更新调度器
 type RSSUpdateScheduler struct {
 	db           *gorm.DB
+	elector      *leader.Elector
+	mu           sync.Mutex
 	isRunning    bool
-	stopChan     chan bool
-	completeChan chan bool
+	ctx          context.Context    // 更新任务使用的上下文，停止时等待超时后取消
+	cancel       context.CancelFunc // 取消进行中的更新任务
+	stopChan     chan struct{}
+	completeChan chan struct{}
 }
 
 // NewRSSUpdateScheduler 创建新调度器
 func NewRSSUpdateScheduler(db *gorm.DB) *RSSUpdateScheduler {
 	return &RSSUpdateScheduler{
-		db:           db,
-		isRunning:    false,
-		stopChan:     make(chan bool),
-		completeChan: make(chan bool),
+		db:        db,
+		elector:   leader.NewElector(db, models.SchedulerLockRSS, leader.DefaultOwner(), leaderLeaseTTL),
+		isRunning: false,
 	}
 }
 
 // Start 启动RSS更新调度器
 func (s *RSSUpdateScheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.isRunning {
 		utils.LogInfo("RSS更新调度器已在运行中")
 		return
 	}
 
 	s.isRunning = true
-	utils.LogInfo("RSS更新调度器已启动，按订阅源配置的更新间隔运行")
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.stopChan = make(chan struct{})
+	s.completeChan = make(chan struct{})
+	// 多实例部署时只有持有租约的主节点执行定时更新和手动更新
+	setElector(s.elector)
+	s.elector.Start(leaderHeartbeatEvery)
+	utils.LogInfo(fmt.Sprintf("RSS更新调度器已启动（实例 %s），按订阅源配置的cron表达式或更新间隔运行", s.elector.Owner()))
 
-	go func() {
+	go func(ctx context.Context, stopChan <-chan struct{}, completeChan chan<- struct{}) {
+		defer close(completeChan)
 		for {
 			select {
 			case <-time.After(time.Minute):
-				s.updateRSS()
-			case <-s.stopChan:
-				utils.LogInfo("RSS更新调度器已停止")
-				s.isRunning = false
-				s.completeChan <- true
+				s.updateRSS(ctx)
+			case <-stopChan:
 				return
 			}
 		}
-	}()
+	}(s.ctx, s.stopChan, s.completeChan)
 }
 
-// Stop 停止RSS更新调度器
-func (s *RSSUpdateScheduler) Stop() {
+// Stop 停止RSS更新调度器：不再开始新一轮更新，等待进行中的更新完成；
+// ctx到期后取消进行中的更新（已收录的条目保留，未完成的运行记录为已取消），最后释放主节点租约
+func (s *RSSUpdateScheduler) Stop(ctx context.Context) {
+	s.mu.Lock()
 	if !s.isRunning {
+		s.mu.Unlock()
 		return
 	}
+	// 先标记为已停止并通知调度协程，等待时不持有锁，避免阻塞查询状态等调用
+	s.isRunning = false
+	close(s.stopChan)
+	cancel, done := s.cancel, s.completeChan
+	s.mu.Unlock()
 
-	s.stopChan <- true
-	<-s.completeChan
+	select {
+	case <-done:
+	case <-ctx.Done():
+		utils.LogWarning("等待RSS更新任务结束超时，取消进行中的更新", ctx.Err())
+		cancel()
+		<-done
+	}
+	cancel()
+	s.elector.Stop()
+	utils.LogInfo("RSS更新调度器已停止")
 }
 
 // IsRunning 检查调度器是否正在运行
 func (s *RSSUpdateScheduler) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.isRunning
 }
 
 // updateRSS 执行RSS更新
-func (s *RSSUpdateScheduler) updateRSS() {
+func (s *RSSUpdateScheduler) updateRSS(ctx context.Context) {
+	if !s.elector.IsLeader() {
+		utils.LogInfo(fmt.Sprintf("实例 %s 不是主节点，跳过本轮RSS更新任务", s.elector.Owner()))
+		return
+	}
+	runRequestedFeeds(ctx, s.db)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if reason := SchedulerSkipReason(time.Now()); reason != "" {
+		utils.LogInfo("跳过本轮RSS更新任务: " + reason)
+		return
+	}
 	utils.LogInfo("开始执行RSS更新任务")
 	utils.LogInfo("准备调用 UpdateRSSFeeds 函数")
-	err := UpdateRSSFeeds(s.db, false)
+	err := UpdateRSSFeeds(ctx, s.db, models.RunTriggerScheduler, false)
 	if err != nil {
 		utils.LogError("RSS更新任务执行失败", err)
 		return
@@ -0,0 +1,57 @@
+package rss
+
+import (
+	"backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// sameReleaseQuery 查询与条目属于同一发布（番剧、字幕组、分辨率、字幕和集数均相同）的其他条目
+func sameReleaseQuery(db *gorm.DB, item *models.RSSItem) *gorm.DB {
+	query := db.Model(&models.RSSItem{}).
+		Where("id <> ? AND bangumi_id = ? AND `group` = ? AND resolution = ? AND sub = ? AND release_kind = ?",
+			item.ID, item.BangumiID, item.Group, item.Resolution, item.Sub, item.ReleaseKind)
+	if item.Episode != nil {
+		query = query.Where("episode = ?", *item.Episode)
+	} else {
+		query = query.Where("episode IS NULL")
+	}
+	if item.EpisodeEnd != nil {
+		query = query.Where("episode_end = ?", *item.EpisodeEnd)
+	}
+	return query
+}
+
+// linkSupersededVersions 将同一发布的旧版本（如v1）标记为被新条目取代；
+// 新条目的版本低于已有条目时（如v2先于v1入库），新条目本身被标记为已取代
+func linkSupersededVersions(db *gorm.DB, item *models.RSSItem) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var latest models.RSSItem
+		err := sameReleaseQuery(tx, item).Where("superseded_by_id IS NULL").
+			Order("version DESC, id DESC").First(&latest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if latest.Version > item.Version {
+			item.SupersededByID = &latest.ID
+			return tx.Model(item).Update("superseded_by_id", latest.ID).Error
+		}
+
+		var olderIDs []uint
+		if err := sameReleaseQuery(tx, item).Where("version < ?", item.Version).Pluck("id", &olderIDs).Error; err != nil {
+			return err
+		}
+		if len(olderIDs) == 0 {
+			return nil
+		}
+		// 旧版本以及原本指向旧版本的条目都改为指向最新版本
+		return tx.Model(&models.RSSItem{}).
+			Where("id IN ? OR superseded_by_id IN ?", olderIDs, olderIDs).
+			Update("superseded_by_id", item.ID).Error
+	})
+}
@@ -0,0 +1,42 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"backend/utils/parser"
+	"context"
+	"fmt"
+)
+
+// fillTorrentMeta 下载种子或解析磁力链接，将infohash、总大小和文件列表写入条目；
+// 获取失败时尝试从种子URL中提取infohash，不影响条目的收录。
+// 订阅源未提供磁力链接时根据infohash和种子中的Tracker生成
+func fillTorrentMeta(ctx context.Context, rssItem *models.RSSItem) {
+	meta, err := parser.FetchTorrentMetaContext(ctx, rssItem.URL)
+	if err != nil {
+		utils.LogWarning(fmt.Sprintf("获取种子元数据失败: %s", rssItem.URL), err)
+		rssItem.InfoHash = urlInfoHash(rssItem.URL)
+		if rssItem.Magnet == "" && rssItem.InfoHash != "" {
+			rssItem.Magnet = parser.BuildMagnet(rssItem.InfoHash, "", 0, nil)
+		}
+		return
+	}
+
+	rssItem.InfoHash = meta.InfoHash
+	rssItem.Size = meta.Size
+	rssItem.Files = make(models.TorrentFiles, 0, len(meta.Files))
+	for _, file := range meta.Files {
+		rssItem.Files = append(rssItem.Files, models.TorrentFile{Path: file.Path, Size: file.Size})
+	}
+	if rssItem.Magnet == "" {
+		rssItem.Magnet = parser.BuildMagnet(meta.InfoHash, meta.Name, meta.Size, meta.Trackers)
+	}
+}
+
+// urlInfoHash 不下载种子，从磁力链接或种子URL中直接取得infohash，无法取得时返回空字符串
+func urlInfoHash(torrentURL string) string {
+	if meta, err := parser.ParseMagnet(torrentURL); err == nil {
+		return meta.InfoHash
+	}
+	return parser.InfoHashFromURL(torrentURL)
+}
@@ -0,0 +1,308 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 手动更新任务状态
+const (
+	JobStatusRunning   = "running"
+	JobStatusSuccess   = "success"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+const (
+	updateJobRetention     = 50              // 保留的已结束任务数
+	updateJobErrorLimit    = 20              // 任务状态中最多列出的错误数
+	updateJobProgressEvery = 2 * time.Second // 进度推送间隔
+	updateJobEventType     = "rss_update_job"
+)
+
+var (
+	// ErrUpdateJobNotFound 任务不存在或已被清理
+	ErrUpdateJobNotFound = errors.New("更新任务不存在")
+	// ErrUpdateJobFinished 任务已结束，无法取消
+	ErrUpdateJobFinished = errors.New("更新任务已结束")
+	// ErrUpdateJobRunning 同一范围已有更新任务在执行
+	ErrUpdateJobRunning = errors.New("已有相同的更新任务在执行")
+)
+
+// UpdateJobStatus 手动更新任务的进度
+type UpdateJobStatus struct {
+	ID         string     `json:"id" example:"5f1c2a9e3b7d" description:"任务ID"`
+	Trigger    string     `json:"trigger" example:"manual" description:"触发方式(manual/single)"`
+	FeedID     uint       `json:"feed_id" example:"0" description:"更新的RSS源ID，0表示全部"`
+	Status     string     `json:"status" example:"running" description:"任务状态(running/success/failed/cancelled)"`
+	FeedsTotal int        `json:"feeds_total" description:"需要处理的RSS源数"`
+	FeedsDone  int        `json:"feeds_done" description:"已处理完的RSS源数"`
+	PagesTotal int        `json:"pages_total" description:"已开始处理的RSS源的分页总数"`
+	PagesDone  int        `json:"pages_done" description:"已处理完的分页数"`
+	ItemsAdded int        `json:"items_added" description:"新增的条目数"`
+	Errors     []string   `json:"errors" description:"处理失败的RSS源及原因"`
+	StartedAt  time.Time  `json:"started_at" description:"开始时间"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" description:"结束时间"`
+}
+
+// UpdateJob 后台执行的手动更新任务，进度从各RSS源的运行统计中汇总
+type UpdateJob struct {
+	mu        sync.Mutex
+	status    UpdateJobStatus
+	feedStats []*runStats
+	cancel    context.CancelFunc
+	done      chan struct{} // 任务结束后关闭
+}
+
+// trackFeed 创建RSS源的运行统计并计入任务进度，job为nil时只返回新的统计
+func (j *UpdateJob) trackFeed() *runStats {
+	stats := &runStats{}
+	if j == nil {
+		return stats
+	}
+	j.mu.Lock()
+	j.feedStats = append(j.feedStats, stats)
+	j.mu.Unlock()
+	return stats
+}
+
+// setFeedsTotal 设置需要处理的RSS源数
+func (j *UpdateJob) setFeedsTotal(total int) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.status.FeedsTotal = total
+	j.mu.Unlock()
+}
+
+// feedDone 记录一个RSS源处理完毕（包括跳过的），err不为nil时计入错误列表
+func (j *UpdateJob) feedDone(feed models.RSSFeed, err error) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.status.FeedsDone++
+	if err != nil && len(j.status.Errors) < updateJobErrorLimit {
+		j.status.Errors = append(j.status.Errors, fmt.Sprintf("%s: %v", feed.Name, err))
+	}
+	j.mu.Unlock()
+}
+
+// snapshot 汇总当前进度
+func (j *UpdateJob) snapshot() UpdateJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := j.status
+	status.Errors = append([]string{}, j.status.Errors...)
+	for _, stats := range j.feedStats {
+		status.PagesTotal += int(atomic.LoadInt64(&stats.pagesTotal))
+		status.PagesDone += int(atomic.LoadInt64(&stats.pagesDone))
+		status.ItemsAdded += int(atomic.LoadInt64(&stats.itemsCreated))
+	}
+	return status
+}
+
+// finish 根据执行结果结束任务
+func (j *UpdateJob) finish(ctx context.Context, err error) {
+	j.mu.Lock()
+	now := time.Now()
+	j.status.FinishedAt = &now
+	switch {
+	case ctx.Err() != nil:
+		j.status.Status = JobStatusCancelled
+	case err != nil:
+		j.status.Status = JobStatusFailed
+		if len(j.status.Errors) == 0 {
+			j.status.Errors = append(j.status.Errors, err.Error())
+		}
+	default:
+		j.status.Status = JobStatusSuccess
+	}
+	j.mu.Unlock()
+}
+
+var (
+	updateJobsMu   sync.Mutex
+	updateJobs     = make(map[string]*UpdateJob)
+	updateJobOrder []string // 按创建顺序排列的任务ID
+)
+
+// StartUpdateJob 在后台执行手动更新并返回任务，feedID为0时更新所有启用的订阅源；
+// 同一范围已有任务在执行时返回该任务和ErrUpdateJobRunning
+func StartUpdateJob(db *gorm.DB, feedID uint) (UpdateJobStatus, error) {
+	updateJobsMu.Lock()
+	for _, id := range updateJobOrder {
+		job := updateJobs[id]
+		if status := job.snapshot(); status.Status == JobStatusRunning && status.FeedID == feedID {
+			updateJobsMu.Unlock()
+			return status, ErrUpdateJobRunning
+		}
+	}
+
+	trigger := models.RunTriggerManual
+	if feedID > 0 {
+		trigger = models.RunTriggerSingle
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &UpdateJob{
+		status: UpdateJobStatus{
+			ID:        newJobID(),
+			Trigger:   trigger,
+			FeedID:    feedID,
+			Status:    JobStatusRunning,
+			Errors:    []string{},
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	registerUpdateJob(job)
+	updateJobsMu.Unlock()
+
+	go runUpdateJob(ctx, db, job)
+	return job.snapshot(), nil
+}
+
+// registerUpdateJob 登记任务并清理超出保留数量的已结束任务，调用方需持有updateJobsMu
+func registerUpdateJob(job *UpdateJob) {
+	updateJobs[job.status.ID] = job
+	updateJobOrder = append(updateJobOrder, job.status.ID)
+
+	for len(updateJobOrder) > updateJobRetention {
+		removed := false
+		for i, id := range updateJobOrder {
+			if updateJobs[id].snapshot().Status != JobStatusRunning {
+				delete(updateJobs, id)
+				updateJobOrder = append(updateJobOrder[:i], updateJobOrder[i+1:]...)
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			return
+		}
+	}
+}
+
+// runUpdateJob 执行任务，执行期间定时推送进度
+func runUpdateJob(ctx context.Context, db *gorm.DB, job *UpdateJob) {
+	defer close(job.done)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(updateJobProgressEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				utils.BroadcastEvent(updateJobEventType, job.snapshot())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	status := job.snapshot()
+	utils.BroadcastEvent(updateJobEventType, status)
+
+	var err error
+	if status.FeedID > 0 {
+		job.setFeedsTotal(1)
+		err = updateSingleRSSFeed(ctx, db, status.FeedID, job)
+	} else {
+		err = updateRSSFeeds(ctx, db, models.RunTriggerManual, true, job)
+	}
+	close(done)
+	job.finish(ctx, err)
+	job.cancel()
+
+	status = job.snapshot()
+	utils.BroadcastEvent(updateJobEventType, status)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("手动更新任务[%s]结束: %s", status.ID, status.Status), err)
+	} else {
+		utils.LogInfo(fmt.Sprintf("手动更新任务[%s]完成: 处理%d个RSS源，新增%d个条目", status.ID, status.FeedsDone, status.ItemsAdded))
+	}
+}
+
+// GetUpdateJob 获取任务进度
+func GetUpdateJob(id string) (UpdateJobStatus, error) {
+	updateJobsMu.Lock()
+	job, ok := updateJobs[id]
+	updateJobsMu.Unlock()
+	if !ok {
+		return UpdateJobStatus{}, ErrUpdateJobNotFound
+	}
+	return job.snapshot(), nil
+}
+
+// ListUpdateJobs 获取最近的任务，按创建时间倒序
+func ListUpdateJobs() []UpdateJobStatus {
+	updateJobsMu.Lock()
+	defer updateJobsMu.Unlock()
+
+	jobs := make([]UpdateJobStatus, 0, len(updateJobOrder))
+	for i := len(updateJobOrder) - 1; i >= 0; i-- {
+		jobs = append(jobs, updateJobs[updateJobOrder[i]].snapshot())
+	}
+	return jobs
+}
+
+// CancelUpdateJob 取消执行中的任务，正在抓取的分页会中止，已收录的条目保留
+func CancelUpdateJob(id string) (UpdateJobStatus, error) {
+	updateJobsMu.Lock()
+	job, ok := updateJobs[id]
+	updateJobsMu.Unlock()
+	if !ok {
+		return UpdateJobStatus{}, ErrUpdateJobNotFound
+	}
+	if job.snapshot().Status != JobStatusRunning {
+		return job.snapshot(), ErrUpdateJobFinished
+	}
+
+	job.cancel()
+	utils.LogInfo(fmt.Sprintf("手动更新任务[%s]已请求取消", id))
+	return job.snapshot(), nil
+}
+
+// ShutdownUpdateJobs 等待执行中的任务结束，ctx到期后取消剩余任务并等待其退出
+func ShutdownUpdateJobs(ctx context.Context) {
+	updateJobsMu.Lock()
+	var running []*UpdateJob
+	for _, id := range updateJobOrder {
+		if job := updateJobs[id]; job.snapshot().Status == JobStatusRunning {
+			running = append(running, job)
+		}
+	}
+	updateJobsMu.Unlock()
+
+	for _, job := range running {
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			utils.LogWarning(fmt.Sprintf("等待手动更新任务[%s]结束超时，取消任务", job.status.ID), ctx.Err())
+			job.cancel()
+			<-job.done
+		}
+	}
+}
+
+// newJobID 生成随机的任务ID
+func newJobID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
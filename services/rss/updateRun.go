@@ -0,0 +1,116 @@
+package rss
+
+import (
+	"backend/models"
+	"backend/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ingestOutcome 单个候选条目的处理结果
+type ingestOutcome int
+
+const (
+	outcomeCreated     ingestOutcome = iota // 新增条目
+	outcomeExcluded                         // 命中排除关键词
+	outcomeBlacklisted                      // 字幕组在黑名单中
+	outcomeSubMismatch                      // 字幕不符且未匹配关键词
+	outcomeParseFailed                      // 标题解析失败或无法确定番剧名
+	outcomeDuplicate                        // 条目已存在
+	outcomeFailed                           // 处理或保存失败
+)
+
+// runStats 一次RSS源更新的统计，由分页工作协程并发累加
+type runStats struct {
+	pagesTotal     int64
+	pagesFetched   int64
+	pagesUnchanged int64
+	pagesFailed    int64
+	pagesDone      int64 // 已处理完（包括失败）的分页数，用于汇报进度
+
+	itemsSeen        int64
+	itemsExcluded    int64
+	itemsBlacklisted int64
+	itemsSubMismatch int64
+	itemsParseFailed int64
+	itemsDuplicate   int64
+	itemsCreated     int64
+	itemsFailed      int64
+}
+
+// record 累加条目处理结果
+func (s *runStats) record(outcome ingestOutcome) {
+	atomic.AddInt64(&s.itemsSeen, 1)
+	switch outcome {
+	case outcomeCreated:
+		atomic.AddInt64(&s.itemsCreated, 1)
+	case outcomeExcluded:
+		atomic.AddInt64(&s.itemsExcluded, 1)
+	case outcomeBlacklisted:
+		atomic.AddInt64(&s.itemsBlacklisted, 1)
+	case outcomeSubMismatch:
+		atomic.AddInt64(&s.itemsSubMismatch, 1)
+	case outcomeParseFailed:
+		atomic.AddInt64(&s.itemsParseFailed, 1)
+	case outcomeDuplicate:
+		atomic.AddInt64(&s.itemsDuplicate, 1)
+	default:
+		atomic.AddInt64(&s.itemsFailed, 1)
+	}
+}
+
+// startRun 创建运行记录，创建失败时返回nil，不影响更新流程
+func startRun(db *gorm.DB, feed models.RSSFeed, trigger string) *models.FeedUpdateRun {
+	run := &models.FeedUpdateRun{
+		RssID:     feed.ID,
+		FeedName:  feed.Name,
+		Trigger:   trigger,
+		Status:    models.RunStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := db.Create(run).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 创建更新运行记录失败", feed.ID), err)
+		return nil
+	}
+	return run
+}
+
+// finishRun 写入统计结果并结束运行记录
+func finishRun(db *gorm.DB, run *models.FeedUpdateRun, stats *runStats, runErr error) {
+	if run == nil {
+		return
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = models.RunStatusSuccess
+	if runErr != nil {
+		run.Status = models.RunStatusFailed
+		if errors.Is(runErr, context.Canceled) {
+			run.Status = models.RunStatusCancelled
+		}
+		run.Error = runErr.Error()
+	}
+
+	run.PagesTotal = int(atomic.LoadInt64(&stats.pagesTotal))
+	run.PagesFetched = int(atomic.LoadInt64(&stats.pagesFetched))
+	run.PagesUnchanged = int(atomic.LoadInt64(&stats.pagesUnchanged))
+	run.PagesFailed = int(atomic.LoadInt64(&stats.pagesFailed))
+	run.ItemsSeen = int(atomic.LoadInt64(&stats.itemsSeen))
+	run.ItemsExcluded = int(atomic.LoadInt64(&stats.itemsExcluded))
+	run.ItemsBlacklisted = int(atomic.LoadInt64(&stats.itemsBlacklisted))
+	run.ItemsSubMismatch = int(atomic.LoadInt64(&stats.itemsSubMismatch))
+	run.ItemsParseFailed = int(atomic.LoadInt64(&stats.itemsParseFailed))
+	run.ItemsDuplicate = int(atomic.LoadInt64(&stats.itemsDuplicate))
+	run.ItemsCreated = int(atomic.LoadInt64(&stats.itemsCreated))
+	run.ItemsFailed = int(atomic.LoadInt64(&stats.itemsFailed))
+
+	if err := db.Save(run).Error; err != nil {
+		utils.LogError(fmt.Sprintf("RSS源[ID:%d] 保存更新运行记录失败", run.RssID), err)
+	}
+}
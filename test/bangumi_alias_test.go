@@ -0,0 +1,71 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/bangumi"
+	"backend/utils/parser"
+	"testing"
+)
+
+// TestNormalizeTitle 测试番剧名称的归一化
+func TestNormalizeTitle(t *testing.T) {
+	testCases := []struct {
+		title    string
+		expected string
+	}{
+		{"SPY×FAMILY", "spyfamily"},
+		{"ＳＰＹ×ＦＡＭＩＬＹ", "spyfamily"},
+		{"Sousou no Frieren", "sousounofrieren"},
+		{"葬送的芙莉莲", "葬送的芙莉莲"},
+		{"葬送のフリーレン", "葬送のフリーレン"},
+		{"ﾌﾘｰﾚﾝ", "フリーレン"},
+		{"  ", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := bangumi.NormalizeTitle(tc.title); got != tc.expected {
+			t.Errorf("NormalizeTitle(%q) = %q, 期望 %q", tc.title, got, tc.expected)
+		}
+	}
+}
+
+// TestDetectLanguage 测试名称语言的识别
+func TestDetectLanguage(t *testing.T) {
+	testCases := []struct {
+		title    string
+		expected string
+	}{
+		{"葬送的芙莉莲", models.AliasLanguageZh},
+		{"葬送のフリーレン", models.AliasLanguageJa},
+		{"Sousou no Frieren", models.AliasLanguageRomaji},
+		{"Frieren: Beyond Journey's End", models.AliasLanguageEn},
+		{"86", models.AliasLanguageOther},
+	}
+
+	for _, tc := range testCases {
+		if got := bangumi.DetectLanguage(tc.title); got != tc.expected {
+			t.Errorf("DetectLanguage(%q) = %q, 期望 %q", tc.title, got, tc.expected)
+		}
+	}
+}
+
+// TestEpisodeNames 测试名称汇总时按归一化结果去重
+func TestEpisodeNames(t *testing.T) {
+	episode := &parser.Episode{
+		NameZh: "葬送的芙莉莲",
+		NameJp: "葬送のフリーレン",
+		NameEn: "Sousou no Frieren",
+	}
+
+	names := bangumi.EpisodeNames("葬送的芙莉莲", episode)
+	if len(names) != 3 {
+		t.Fatalf("期望3个名称，实际为 %d: %+v", len(names), names)
+	}
+	if names[0].Title != "葬送的芙莉莲" || names[0].Language != models.AliasLanguageZh {
+		t.Errorf("第一个名称应为番剧名，实际为 %+v", names[0])
+	}
+
+	if names := bangumi.EpisodeNames("", nil); len(names) != 0 {
+		t.Errorf("空名称应被忽略，实际为 %+v", names)
+	}
+}
@@ -0,0 +1,316 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/bangumi"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// openMergeTestDB 打开包含番剧、RSS条目、收藏、评分和播放记录等表的测试数据库
+func openMergeTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return openTestDB(t,
+		&models.User{},
+		&models.RSSFeed{},
+		&models.RSSItem{},
+		&models.Bangumi{},
+		&models.BangumiAlias{},
+		&models.BangumiMetadata{},
+		&models.BangumiMetadataTag{},
+		&models.BangumiMetadataStaff{},
+		&models.BangumiFavorite{},
+		&models.BangumiRating{},
+		&models.PlayHistory{},
+		&models.ReviewItem{},
+		&models.TitleOverride{},
+		&models.Activity{},
+	)
+}
+
+// mustCreate 创建记录，失败时终止测试
+func mustCreate(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("创建%T失败: %v", value, err)
+	}
+}
+
+// mergeFixture 合并和拆分测试使用的番剧和RSS源
+type mergeFixture struct {
+	db    *gorm.DB
+	feed  models.RSSFeed
+	users []models.User
+}
+
+// newMergeFixture 创建RSS源和n个用户
+func newMergeFixture(t *testing.T, users int) *mergeFixture {
+	t.Helper()
+	f := &mergeFixture{db: openMergeTestDB(t)}
+	f.feed = models.RSSFeed{Name: "测试订阅源", URL: "https://example.com/rss", ParserType: "generic_rss", UpdateInterval: 1}
+	mustCreate(t, f.db, &f.feed)
+	for i := 0; i < users; i++ {
+		user := models.User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i), Password: "-"}
+		mustCreate(t, f.db, &user)
+		f.users = append(f.users, user)
+	}
+	return f
+}
+
+// bangumi 创建番剧
+func (f *mergeFixture) bangumi(t *testing.T, title string, viewCount int64) models.Bangumi {
+	t.Helper()
+	b := models.Bangumi{OfficialTitle: title, Season: 1, ViewCount: viewCount}
+	mustCreate(t, f.db, &b)
+	return b
+}
+
+// item 在番剧下创建RSS条目
+func (f *mergeFixture) item(t *testing.T, b models.Bangumi, url string, episode float64) models.RSSItem {
+	t.Helper()
+	end := episode
+	item := models.RSSItem{BangumiID: b.ID, RssID: f.feed.ID, Title: b.OfficialTitle, URL: url, Episode: &episode, EpisodeEnd: &end, ReleaseKind: "episode", Version: 1}
+	mustCreate(t, f.db, &item)
+	return item
+}
+
+// aliases 返回番剧的归一化别名，按字母排序
+func aliases(t *testing.T, db *gorm.DB, bangumiID uint) []string {
+	t.Helper()
+	var normalized []string
+	if err := db.Model(&models.BangumiAlias{}).Where("bangumi_id = ?", bangumiID).Pluck("normalized", &normalized).Error; err != nil {
+		t.Fatalf("查询别名失败: %v", err)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// TestMergeBangumi 测试合并番剧：按种子URL去重后移动条目，处理收藏和评分的唯一约束冲突，
+// 移动别名、播放记录和人工审核结果，重新计算统计数据并删除被合并的番剧
+func TestMergeBangumi(t *testing.T) {
+	f := newMergeFixture(t, 4)
+	db := f.db
+	target := f.bangumi(t, "葬送的芙莉莲", 10)
+	source := f.bangumi(t, "芙莉莲", 5)
+
+	kept := f.item(t, target, "https://example.com/1.torrent", 1)
+	duplicate := f.item(t, source, "https://example.com/1.torrent", 1)
+	moved := f.item(t, source, "https://example.com/2.torrent", 2)
+
+	// 重复条目上的播放记录和待审核条目改为指向保留的条目
+	mustCreate(t, db, &models.PlayHistory{UserId: f.users[0].ID, RssItemsId: duplicate.ID})
+	duplicateReview := models.ReviewItem{RssID: f.feed.ID, RawTitle: "重复条目", TitleHash: models.TitleHash("重复条目"), RSSItemID: &duplicate.ID, Status: models.ReviewStatusPending}
+	mustCreate(t, db, &duplicateReview)
+
+	// 人工指定到source的审核结果和覆盖规则改为指向target
+	assignedReview := models.ReviewItem{RssID: f.feed.ID, RawTitle: "人工指定", TitleHash: models.TitleHash("人工指定"), BangumiID: &source.ID, Status: models.ReviewStatusAssigned}
+	mustCreate(t, db, &assignedReview)
+	override := models.TitleOverride{RawTitle: "人工指定", TitleHash: models.TitleHash("人工指定"), BangumiID: source.ID}
+	mustCreate(t, db, &override)
+
+	// user0两边都收藏；user1只收藏source；user2在target上的收藏已取消，source上仍在收藏
+	mustCreate(t, db, &models.BangumiFavorite{UserID: f.users[0].ID, BangumiID: target.ID})
+	mustCreate(t, db, &models.BangumiFavorite{UserID: f.users[0].ID, BangumiID: source.ID})
+	mustCreate(t, db, &models.BangumiFavorite{UserID: f.users[1].ID, BangumiID: source.ID})
+	cancelled := models.BangumiFavorite{UserID: f.users[2].ID, BangumiID: target.ID}
+	mustCreate(t, db, &cancelled)
+	if err := db.Delete(&cancelled).Error; err != nil {
+		t.Fatalf("取消收藏失败: %v", err)
+	}
+	mustCreate(t, db, &models.BangumiFavorite{UserID: f.users[2].ID, BangumiID: source.ID})
+
+	// user0两边都评过分，保留较新的source评分；user1只评过source；user3只评过target
+	now := time.Now()
+	mustCreate(t, db, &models.BangumiRating{UserID: f.users[0].ID, BangumiID: target.ID, Score: 6, Model: gorm.Model{UpdatedAt: now.Add(-time.Hour)}})
+	mustCreate(t, db, &models.BangumiRating{UserID: f.users[0].ID, BangumiID: source.ID, Score: 8, Model: gorm.Model{UpdatedAt: now}})
+	mustCreate(t, db, &models.BangumiRating{UserID: f.users[1].ID, BangumiID: source.ID, Score: 7})
+	mustCreate(t, db, &models.BangumiRating{UserID: f.users[3].ID, BangumiID: target.ID, Score: 9})
+
+	if err := bangumi.RecordAliases(db, target.ID, bangumi.EpisodeNames("葬送的芙莉莲", nil)); err != nil {
+		t.Fatalf("记录别名失败: %v", err)
+	}
+	mustCreate(t, db, &models.BangumiAlias{BangumiID: source.ID, Alias: "Frieren", Normalized: bangumi.NormalizeTitle("Frieren")})
+	mustCreate(t, db, &models.BangumiAlias{BangumiID: source.ID, Alias: "葬送的芙莉莲", Normalized: bangumi.NormalizeTitle("葬送的芙莉莲")})
+
+	result, err := bangumi.Merge(db, target.ID, source.ID)
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+	if result.MovedItems != 1 || result.DuplicateItems != 1 || result.MovedFavorites != 1 || result.MovedRatings != 1 {
+		t.Errorf("合并结果不匹配，实际: 移动%d个条目 %d个重复 %d个收藏 %d个评分", result.MovedItems, result.DuplicateItems, result.MovedFavorites, result.MovedRatings)
+	}
+
+	// 条目
+	var items []models.RSSItem
+	if err := db.Unscoped().Order("id").Find(&items).Error; err != nil {
+		t.Fatalf("查询条目失败: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != kept.ID || items[1].ID != moved.ID {
+		t.Fatalf("合并后应保留条目[%d %d]，重复条目被删除，实际: %d个条目", kept.ID, moved.ID, len(items))
+	}
+	for _, item := range items {
+		if item.BangumiID != target.ID || item.Title != target.OfficialTitle {
+			t.Errorf("条目[ID:%d]应归入target并使用target的名称，实际: 番剧[ID:%d] %s", item.ID, item.BangumiID, item.Title)
+		}
+	}
+	var history models.PlayHistory
+	if err := db.Where("user_id = ?", f.users[0].ID).First(&history).Error; err != nil || history.RssItemsId != kept.ID {
+		t.Errorf("播放记录应指向保留的条目[ID:%d]，实际: %d, %v", kept.ID, history.RssItemsId, err)
+	}
+	if err := db.First(&duplicateReview, duplicateReview.ID).Error; err != nil || duplicateReview.RSSItemID == nil || *duplicateReview.RSSItemID != kept.ID {
+		t.Errorf("待审核条目应指向保留的条目[ID:%d]，实际: %v, %v", kept.ID, duplicateReview.RSSItemID, err)
+	}
+
+	// 人工审核结果
+	if err := db.First(&assignedReview, assignedReview.ID).Error; err != nil || assignedReview.BangumiID == nil || *assignedReview.BangumiID != target.ID {
+		t.Errorf("人工指定的番剧应改为target，实际: %v, %v", assignedReview.BangumiID, err)
+	}
+	if err := db.First(&override, override.ID).Error; err != nil || override.BangumiID != target.ID {
+		t.Errorf("覆盖规则的番剧应改为target，实际: %d, %v", override.BangumiID, err)
+	}
+
+	// 收藏和评分
+	var favorites []models.BangumiFavorite
+	if err := db.Where("bangumi_id = ?", target.ID).Order("user_id").Find(&favorites).Error; err != nil {
+		t.Fatalf("查询收藏失败: %v", err)
+	}
+	if len(favorites) != 3 {
+		t.Errorf("target应有user0、user1和user2的3条收藏，实际: %d", len(favorites))
+	}
+	var leftover int64
+	db.Unscoped().Model(&models.BangumiFavorite{}).Where("bangumi_id = ?", source.ID).Count(&leftover)
+	if leftover != 0 {
+		t.Errorf("source的收藏应全部移走或删除，剩余: %d", leftover)
+	}
+	var rating models.BangumiRating
+	if err := db.Where("user_id = ? AND bangumi_id = ?", f.users[0].ID, target.ID).First(&rating).Error; err != nil || rating.Score != 8 {
+		t.Errorf("user0的评分应保留较新的8分，实际: %g, %v", rating.Score, err)
+	}
+	var ratingCount int64
+	db.Unscoped().Model(&models.BangumiRating{}).Where("user_id = ?", f.users[0].ID).Count(&ratingCount)
+	if ratingCount != 1 {
+		t.Errorf("user0应只剩1条评分，实际: %d", ratingCount)
+	}
+
+	// 别名：source的别名移到target，重复的别名不重复记录，source的名称成为target的别名
+	expectedAliases := []string{"frieren", "芙莉莲", "葬送的芙莉莲"}
+	if got := aliases(t, db, target.ID); fmt.Sprint(got) != fmt.Sprint(expectedAliases) {
+		t.Errorf("target的别名不匹配，期望: %v, 实际: %v", expectedAliases, got)
+	}
+
+	// 统计数据
+	merged := result.Target
+	if merged.ViewCount != 15 || merged.FavoriteCount != 3 || merged.RatingCount != 3 || math.Abs(merged.RatingAvg-8) > 0.01 {
+		t.Errorf("统计数据不匹配，期望: 点击15 收藏3 评分3人平均8，实际: 点击%d 收藏%d 评分%d人平均%g",
+			merged.ViewCount, merged.FavoriteCount, merged.RatingCount, merged.RatingAvg)
+	}
+
+	// source被彻底删除
+	var count int64
+	db.Unscoped().Model(&models.Bangumi{}).Where("id = ?", source.ID).Count(&count)
+	if count != 0 {
+		t.Error("被合并的番剧应被删除")
+	}
+}
+
+// TestMergeBangumiErrors 测试合并到自身或番剧不存在时返回错误且不修改数据
+func TestMergeBangumiErrors(t *testing.T) {
+	f := newMergeFixture(t, 0)
+	target := f.bangumi(t, "葬送的芙莉莲", 0)
+	f.item(t, target, "https://example.com/1.torrent", 1)
+
+	if _, err := bangumi.Merge(f.db, target.ID, target.ID); !errors.Is(err, bangumi.ErrSameBangumi) {
+		t.Errorf("合并到自身应返回ErrSameBangumi，实际: %v", err)
+	}
+	if _, err := bangumi.Merge(f.db, target.ID, target.ID+100); !errors.Is(err, bangumi.ErrBangumiNotFound) {
+		t.Errorf("番剧不存在时应返回ErrBangumiNotFound，实际: %v", err)
+	}
+	var count int64
+	f.db.Model(&models.Bangumi{}).Count(&count)
+	if count != 1 {
+		t.Errorf("合并失败时不应修改番剧，实际剩余: %d", count)
+	}
+}
+
+// TestSplitBangumi 测试拆分番剧只移动选中的条目，收藏和评分保留在原番剧，跨番剧的版本关系被解除
+func TestSplitBangumi(t *testing.T) {
+	f := newMergeFixture(t, 1)
+	db := f.db
+	source := f.bangumi(t, "葬送的芙莉莲", 0)
+	episode := f.item(t, source, "https://example.com/1.torrent", 1)
+	special := f.item(t, source, "https://example.com/sp.torrent", 2)
+	specialV2 := f.item(t, source, "https://example.com/sp-v2.torrent", 2)
+	// 错误归类的特别篇与正片的新版本关系在拆分后不再成立
+	if err := db.Model(&special).Update("superseded_by_id", episode.ID).Error; err != nil {
+		t.Fatalf("设置版本关系失败: %v", err)
+	}
+	review := models.ReviewItem{RssID: f.feed.ID, RawTitle: "特别篇", TitleHash: models.TitleHash("特别篇"), RSSItemID: &specialV2.ID, BangumiID: &source.ID, Status: models.ReviewStatusAssigned}
+	mustCreate(t, db, &review)
+	mustCreate(t, db, &models.BangumiFavorite{UserID: f.users[0].ID, BangumiID: source.ID})
+
+	created, err := bangumi.Split(db, source.ID, models.BangumiSplitRequest{
+		RSSItemIDs:    []uint{special.ID, specialV2.ID},
+		OfficialTitle: "葬送的芙莉莲 特别篇",
+	})
+	if err != nil {
+		t.Fatalf("拆分失败: %v", err)
+	}
+	if created.Season != source.Season {
+		t.Errorf("未指定季度时应沿用原番剧的季度，实际: %d", created.Season)
+	}
+
+	expected := map[uint]uint{episode.ID: source.ID, special.ID: created.ID, specialV2.ID: created.ID}
+	for id, bangumiID := range expected {
+		var item models.RSSItem
+		if err := db.First(&item, id).Error; err != nil {
+			t.Fatalf("查询条目失败: %v", err)
+		}
+		if item.BangumiID != bangumiID {
+			t.Errorf("条目[ID:%d]应属于番剧[ID:%d]，实际: %d", id, bangumiID, item.BangumiID)
+		}
+		if id == special.ID && item.SupersededByID != nil {
+			t.Errorf("拆分后条目[ID:%d]不应再被原番剧的条目取代", id)
+		}
+	}
+	if err := db.First(&review, review.ID).Error; err != nil || review.BangumiID == nil || *review.BangumiID != created.ID {
+		t.Errorf("移动条目的审核结果应指向新番剧，实际: %v, %v", review.BangumiID, err)
+	}
+
+	var favorites int64
+	db.Model(&models.BangumiFavorite{}).Where("bangumi_id = ?", source.ID).Count(&favorites)
+	if favorites != 1 {
+		t.Errorf("收藏应保留在原番剧，实际: %d", favorites)
+	}
+	if got := aliases(t, db, created.ID); len(got) == 0 {
+		t.Error("新番剧应记录名称别名")
+	}
+}
+
+// TestSplitBangumiRejectsForeignItems 测试选中其他番剧的条目或新番剧名称已存在时拆分失败且不移动条目
+func TestSplitBangumiRejectsForeignItems(t *testing.T) {
+	f := newMergeFixture(t, 0)
+	source := f.bangumi(t, "葬送的芙莉莲", 0)
+	other := f.bangumi(t, "间谍过家家", 0)
+	own := f.item(t, source, "https://example.com/1.torrent", 1)
+	foreign := f.item(t, other, "https://example.com/2.torrent", 1)
+
+	_, err := bangumi.Split(f.db, source.ID, models.BangumiSplitRequest{RSSItemIDs: []uint{own.ID, foreign.ID}, OfficialTitle: "新番剧"})
+	if !errors.Is(err, bangumi.ErrItemsNotInSource) {
+		t.Errorf("选中其他番剧的条目时应返回ErrItemsNotInSource，实际: %v", err)
+	}
+	_, err = bangumi.Split(f.db, source.ID, models.BangumiSplitRequest{RSSItemIDs: []uint{own.ID}, OfficialTitle: "间谍过家家"})
+	if !errors.Is(err, bangumi.ErrBangumiExists) {
+		t.Errorf("新番剧名称已存在时应返回ErrBangumiExists，实际: %v", err)
+	}
+
+	var item models.RSSItem
+	if err := f.db.First(&item, own.ID).Error; err != nil || item.BangumiID != source.ID {
+		t.Errorf("拆分失败时条目不应移动，实际: %d, %v", item.BangumiID, err)
+	}
+}
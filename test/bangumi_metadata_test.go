@@ -0,0 +1,303 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/metadata"
+	"backend/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeBgmSubjects 模拟的bgm.tv条目
+var fakeBgmSubjects = map[string]string{
+	"400602": `{"id":400602,"type":2,"name":"葬送のフリーレン","name_cn":"葬送的芙莉莲","summary":" 勇者一行击败魔王之后的故事。 ","date":"2023-09-29","eps":28,"total_episodes":28,
+		"rating":{"score":8.9,"total":12000},"tags":[{"name":"奇幻","count":3500},{"name":"治愈","count":1200}],
+		"infobox":[{"key":"中文名","value":"葬送的芙莉莲"},{"key":"别名","value":[{"v":"Frieren: Beyond Journey's End"},{"v":"Sousou no Frieren"}]},{"key":"放送星期","value":"星期五"}]}`,
+	"500100": `{"id":500100,"type":2,"name":"葬送のフリーレン 第2期","name_cn":"葬送的芙莉莲 第二季","summary":"第二季。","date":"2026-01-16","eps":0,"total_episodes":0,
+		"rating":{"score":0,"total":0},"tags":[{"name":"续作","count":10}],"infobox":[]}`,
+	"100": `{"id":100,"type":2,"name":"フリーレン","name_cn":"芙莉莲","summary":"同名的旧作。","date":"2003-04-06","eps":12,"total_episodes":12,
+		"rating":{"score":6.0,"total":100},"tags":[],"infobox":[]}`,
+}
+
+// fakeBgmPersons 模拟的条目制作人员
+var fakeBgmPersons = map[string]string{
+	"400602": `[{"id":12345,"name":"斋藤圭一郎","relation":"导演","career":["producer"],"type":1},{"id":23456,"name":"Evan Call","relation":"音乐","type":1},{"id":1,"name":"","relation":"原作","type":1}]`,
+	"500100": `[{"id":12345,"name":"斋藤圭一郎","relation":"导演","type":1}]`,
+	"100":    `[]`,
+}
+
+// newFakeBgmServer 启动模拟bgm.tv v0 API的本地服务器，记录收到的搜索关键词
+func newFakeBgmServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var keywords []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/search/subjects":
+			var body struct {
+				Keyword string `json:"keyword"`
+				Filter  struct {
+					Type []int `json:"type"`
+				} `json:"filter"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Filter.Type) != 1 || body.Filter.Type[0] != 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			keywords = append(keywords, body.Keyword)
+
+			var results []string
+			if strings.Contains(body.Keyword, "芙莉莲") || strings.Contains(body.Keyword, "フリーレン") || strings.Contains(strings.ToLower(body.Keyword), "frieren") {
+				// 按相关度排列：同名旧作和续作排在前面
+				results = []string{fakeBgmSubjects["100"], fakeBgmSubjects["500100"], fakeBgmSubjects["400602"]}
+			}
+			fmt.Fprintf(w, `{"data":[%s],"total":%d,"limit":10,"offset":0}`, strings.Join(results, ","), len(results))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v0/subjects/"):
+			rest := strings.TrimPrefix(r.URL.Path, "/v0/subjects/")
+			if id, ok := strings.CutSuffix(rest, "/persons"); ok {
+				if persons, exists := fakeBgmPersons[id]; exists {
+					fmt.Fprint(w, persons)
+					return
+				}
+			} else if subject, exists := fakeBgmSubjects[rest]; exists {
+				fmt.Fprint(w, subject)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"title":"Not Found"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &keywords
+}
+
+// newTestBgmProvider 创建指向模拟服务器的bgm来源，不限速
+func newTestBgmProvider(baseURL string) *metadata.BgmProvider {
+	client := utils.NewOutboundClient(utils.OutboundConfig{
+		UserAgent:            "AnimeBackend/test",
+		Timeout:              5 * time.Second,
+		RatePerSecond:        1000,
+		Burst:                100,
+		MaxConcurrentPerHost: 4,
+	})
+	return metadata.NewBgmProvider(baseURL, "", client)
+}
+
+// openMetadataTestDB 打开独立的内存SQLite数据库并创建番剧和元数据相关的表
+func openMetadataTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Bangumi{}, &models.BangumiAlias{}, &models.BangumiMetadata{}, &models.BangumiMetadataTag{}, &models.BangumiMetadataStaff{}); err != nil {
+		t.Fatalf("创建数据表失败: %v", err)
+	}
+	return db
+}
+
+// createTestBangumi 创建番剧和别名
+func createTestBangumi(t *testing.T, db *gorm.DB, title, year string, season int, aliases ...string) models.Bangumi {
+	t.Helper()
+	b := models.Bangumi{OfficialTitle: title, Season: season}
+	if year != "" {
+		b.Year = &year
+	}
+	if err := db.Create(&b).Error; err != nil {
+		t.Fatalf("创建番剧失败: %v", err)
+	}
+	for _, alias := range aliases {
+		if err := db.Create(&models.BangumiAlias{BangumiID: b.ID, Alias: alias, Normalized: strings.ToLower(alias)}).Error; err != nil {
+			t.Fatalf("创建别名失败: %v", err)
+		}
+	}
+	return b
+}
+
+// TestBgmProviderSubject 测试解析条目详情、信息框和制作人员
+func TestBgmProviderSubject(t *testing.T) {
+	server, _ := newFakeBgmServer(t)
+	provider := newTestBgmProvider(server.URL)
+
+	subject, err := provider.Subject(context.Background(), "400602")
+	if err != nil {
+		t.Fatalf("获取条目失败: %v", err)
+	}
+	if subject.Name != "葬送のフリーレン" || subject.NameCn != "葬送的芙莉莲" {
+		t.Errorf("名称错误: %q %q", subject.Name, subject.NameCn)
+	}
+	if subject.Summary != "勇者一行击败魔王之后的故事。" {
+		t.Errorf("简介错误: %q", subject.Summary)
+	}
+	if subject.AirDate != "2023-09-29" || subject.Weekday != 5 || subject.EpisodeCount != 28 {
+		t.Errorf("放送信息错误: %s 星期%d 共%d集", subject.AirDate, subject.Weekday, subject.EpisodeCount)
+	}
+	if subject.Score != 8.9 || subject.ScoreCount != 12000 {
+		t.Errorf("评分错误: %v (%d人)", subject.Score, subject.ScoreCount)
+	}
+	if len(subject.Tags) != 2 || subject.Tags[0].Name != "奇幻" || subject.Tags[0].Count != 3500 {
+		t.Errorf("标签错误: %+v", subject.Tags)
+	}
+	if len(subject.Aliases) != 2 || subject.Aliases[1] != "Sousou no Frieren" {
+		t.Errorf("别名错误: %+v", subject.Aliases)
+	}
+	// 没有姓名的人物被忽略
+	if len(subject.Staff) != 2 || subject.Staff[0].Role != "导演" || subject.Staff[0].Name != "斋藤圭一郎" || subject.Staff[0].ID != "12345" {
+		t.Errorf("制作人员错误: %+v", subject.Staff)
+	}
+
+	// 信息框中没有放送星期时按首播日期推算：2026-01-16为星期五
+	sequel, err := provider.Subject(context.Background(), "500100")
+	if err != nil {
+		t.Fatalf("获取条目失败: %v", err)
+	}
+	if sequel.Weekday != 5 {
+		t.Errorf("按首播日期推算的放送星期错误: %d", sequel.Weekday)
+	}
+
+	if _, err := provider.Subject(context.Background(), "999"); !errors.Is(err, metadata.ErrSubjectNotFound) {
+		t.Errorf("不存在的条目应返回ErrSubjectNotFound，实际: %v", err)
+	}
+	if _, err := provider.Subject(context.Background(), "abc"); err == nil {
+		t.Error("非数字的条目ID应返回错误")
+	}
+}
+
+// TestMetadataMatch 测试按番剧名、别名、季度和年份匹配条目
+func TestMetadataMatch(t *testing.T) {
+	server, keywords := newFakeBgmServer(t)
+	provider := newTestBgmProvider(server.URL)
+	db := openMetadataTestDB(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		bangumi models.Bangumi
+		want    string
+	}{
+		{"中文名和年份", createTestBangumi(t, db, "葬送的芙莉莲", "2023", 1), "400602"},
+		{"通过别名匹配", createTestBangumi(t, db, "芙莉莲 葬送", "2023", 1, "Sousou no Frieren"), "400602"},
+		{"第二季", createTestBangumi(t, db, "葬送的芙莉莲", "2026", 2), "500100"},
+		{"年份不一致", createTestBangumi(t, db, "葬送的芙莉莲", "2019", 1), ""},
+		{"没有年份时按名称匹配", createTestBangumi(t, db, "フリーレン", "", 1), "100"},
+		{"没有搜索结果", createTestBangumi(t, db, "不存在的番剧", "2023", 1), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := metadata.Match(ctx, db, provider, tt.bangumi)
+			if err != nil {
+				t.Fatalf("匹配失败: %v", err)
+			}
+			got := ""
+			if subject != nil {
+				got = subject.ID
+			}
+			if got != tt.want {
+				t.Errorf("匹配结果错误: 期望 %q, 实际为 %q", tt.want, got)
+			}
+		})
+	}
+
+	if len(*keywords) == 0 || (*keywords)[0] != "葬送的芙莉莲" {
+		t.Errorf("应先使用番剧名搜索: %v", *keywords)
+	}
+}
+
+// TestMetadataRefreshAndLink 测试定时刷新自动关联条目、手动关联替换标签和制作人员，以及未匹配时记录原因
+func TestMetadataRefreshAndLink(t *testing.T) {
+	server, _ := newFakeBgmServer(t)
+	provider := newTestBgmProvider(server.URL)
+	db := openMetadataTestDB(t)
+	ctx := context.Background()
+
+	frieren := createTestBangumi(t, db, "葬送的芙莉莲", "2023", 1)
+	unknown := createTestBangumi(t, db, "不存在的番剧", "2023", 1)
+
+	refreshed, err := metadata.RefreshStale(ctx, db, provider, time.Hour, 10)
+	if err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	if refreshed != 1 {
+		t.Errorf("应刷新1个番剧，实际为%d", refreshed)
+	}
+
+	result, err := metadata.Get(db, frieren.ID)
+	if err != nil || result == nil {
+		t.Fatalf("读取元数据失败: %v", err)
+	}
+	if result.SubjectID != "400602" || result.MatchedBy != models.MetadataMatchAuto || result.Provider != models.MetadataProviderBgm {
+		t.Errorf("关联信息错误: %+v", result)
+	}
+	if result.Name != "葬送のフリーレン" || result.Weekday != 5 || result.EpisodeCount != 28 || result.SyncedAt == nil {
+		t.Errorf("元数据错误: %+v", result)
+	}
+	if len(result.Tags) != 2 || result.Tags[0].Name != "奇幻" || len(result.Staff) != 2 {
+		t.Errorf("标签或制作人员错误: %+v %+v", result.Tags, result.Staff)
+	}
+
+	// 未匹配的番剧记录原因，不视为已关联
+	if missing, _ := metadata.Get(db, unknown.ID); missing != nil {
+		t.Errorf("未匹配的番剧不应返回元数据: %+v", missing)
+	}
+	var placeholder models.BangumiMetadata
+	if err := db.Where("bangumi_id = ?", unknown.ID).First(&placeholder).Error; err != nil {
+		t.Fatalf("未匹配的番剧应记录同步结果: %v", err)
+	}
+	if placeholder.SubjectID != "" || placeholder.SyncError == "" || placeholder.SyncedAt == nil {
+		t.Errorf("未匹配的记录错误: %+v", placeholder)
+	}
+	if _, err := metadata.Refresh(ctx, db, provider, unknown.ID); !errors.Is(err, metadata.ErrNoMatch) {
+		t.Errorf("未匹配时应返回ErrNoMatch，实际: %v", err)
+	}
+
+	// 刷新周期内不再重复刷新
+	if refreshed, _ := metadata.RefreshStale(ctx, db, provider, time.Hour, 10); refreshed != 0 {
+		t.Errorf("刷新周期内不应再次刷新，实际刷新%d个", refreshed)
+	}
+
+	// 手动关联到其他条目后替换标签和制作人员，之后刷新沿用手动关联的条目
+	linked, err := metadata.Link(ctx, db, provider, frieren.ID, "500100", models.MetadataMatchManual)
+	if err != nil {
+		t.Fatalf("手动关联失败: %v", err)
+	}
+	if linked.ID != result.ID || linked.SubjectID != "500100" || linked.MatchedBy != models.MetadataMatchManual {
+		t.Errorf("手动关联结果错误: %+v", linked)
+	}
+	if _, err := metadata.Refresh(ctx, db, provider, frieren.ID); err != nil {
+		t.Fatalf("刷新手动关联的番剧失败: %v", err)
+	}
+	result, _ = metadata.Get(db, frieren.ID)
+	if result.SubjectID != "500100" || result.MatchedBy != models.MetadataMatchManual {
+		t.Errorf("刷新后应保留手动关联: %+v", result)
+	}
+	if len(result.Tags) != 1 || result.Tags[0].Name != "续作" || len(result.Staff) != 1 {
+		t.Errorf("标签或制作人员未被替换: %+v %+v", result.Tags, result.Staff)
+	}
+	var tagCount int64
+	db.Model(&models.BangumiMetadataTag{}).Count(&tagCount)
+	if tagCount != 1 {
+		t.Errorf("旧的标签应被删除，实际共%d个", tagCount)
+	}
+
+	if _, err := metadata.Link(ctx, db, provider, frieren.ID, "999", models.MetadataMatchManual); !errors.Is(err, metadata.ErrSubjectNotFound) {
+		t.Errorf("关联不存在的条目应返回ErrSubjectNotFound，实际: %v", err)
+	}
+	if _, err := metadata.Link(ctx, db, nil, frieren.ID, "400602", models.MetadataMatchManual); !errors.Is(err, metadata.ErrProviderDisabled) {
+		t.Errorf("未启用来源时应返回ErrProviderDisabled，实际: %v", err)
+	}
+}
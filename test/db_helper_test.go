@@ -0,0 +1,33 @@
+package test
+
+import (
+	"backend/models"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB 打开独立的内存SQLite数据库并创建指定的表
+func openTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("创建数据表失败: %v", err)
+	}
+	return db
+}
+
+// useTestDB 将全局数据库连接替换为测试数据库，测试结束后恢复
+func useTestDB(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	previous := models.DB
+	models.SetDB(db)
+	t.Cleanup(func() { models.SetDB(previous) })
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/rss"
+	"testing"
+	"time"
+)
+
+// TestRSSFeedHealthStatus 测试RSS源健康状态计算
+func TestRSSFeedHealthStatus(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		feed     models.RSSFeed
+		expected string
+		testName string
+	}{
+		{models.RSSFeed{Enabled: true}, models.FeedHealthUnknown, "从未更新"},
+		{models.RSSFeed{Enabled: true, LastCheckedAt: &now}, models.FeedHealthHealthy, "更新成功"},
+		{models.RSSFeed{Enabled: true, LastCheckedAt: &now, LastError: "1/3个分页处理失败"}, models.FeedHealthDegraded, "部分分页失败"},
+		{models.RSSFeed{Enabled: true, LastCheckedAt: &now, ConsecutiveFailures: 1}, models.FeedHealthDegraded, "偶发失败"},
+		{models.RSSFeed{Enabled: true, LastCheckedAt: &now, ConsecutiveFailures: models.FeedFailingThreshold}, models.FeedHealthFailing, "连续失败"},
+		{models.RSSFeed{Enabled: false, LastCheckedAt: &now, ConsecutiveFailures: 10}, models.FeedHealthDisabled, "已停用"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if status := tc.feed.HealthStatus(); status != tc.expected {
+				t.Errorf("健康状态不匹配，期望: %s, 实际: %s", tc.expected, status)
+			}
+		})
+	}
+}
+
+// TestPollIntervalBackoff 测试连续失败后的指数退避
+func TestPollIntervalBackoff(t *testing.T) {
+	testCases := []struct {
+		interval int
+		failures int
+		expected time.Duration
+	}{
+		{1, 0, time.Hour},
+		{1, 1, 2 * time.Hour},
+		{1, 3, 8 * time.Hour},
+		{1, 10, 24 * time.Hour},
+		{0, 0, 0},
+		{0, 2, 40 * time.Minute},
+		{48, 2, 48 * time.Hour},
+	}
+
+	for _, tc := range testCases {
+		feed := models.RSSFeed{UpdateInterval: tc.interval, ConsecutiveFailures: tc.failures}
+		if got := rss.PollInterval(feed); got != tc.expected {
+			t.Errorf("间隔%d小时、连续失败%d次，期望: %v, 实际: %v", tc.interval, tc.failures, tc.expected, got)
+		}
+	}
+}
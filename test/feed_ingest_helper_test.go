@@ -0,0 +1,116 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/rss"
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// ingestTables RSS更新流程用到的表
+var ingestTables = []interface{}{
+	&models.User{},
+	&models.RSSFeed{},
+	&models.RSSItem{},
+	&models.RSSFeedPageCache{},
+	&models.FeedUpdateRun{},
+	&models.FilterRule{},
+	&models.ReviewItem{},
+	&models.TitleOverride{},
+	&models.Bangumi{},
+	&models.BangumiAlias{},
+	&models.Activity{},
+	&models.GlobalSettings{},
+}
+
+// openIngestTestDB 打开包含RSS更新流程所需表的测试数据库，并替换全局数据库连接
+func openIngestTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openTestDB(t, ingestTables...)
+	useTestDB(t, db)
+	return db
+}
+
+// testRelease 测试订阅源中的发布条目，磁力链接按序号生成以避免按infohash去重
+type testRelease struct {
+	title string
+	n     int
+}
+
+// magnet 条目的磁力链接
+func (r testRelease) magnet() string {
+	return fmt.Sprintf("magnet:?xt=urn:btih:%040x", r.n)
+}
+
+// testFeedServer 返回可替换条目的RSS 2.0订阅源
+type testFeedServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	releases []testRelease
+}
+
+// newTestFeedServer 启动测试订阅源服务，测试结束后关闭
+func newTestFeedServer(t *testing.T) *testFeedServer {
+	t.Helper()
+	s := &testFeedServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="utf-8"?><rss version="2.0"><channel><title>测试订阅源</title>`)
+		for _, release := range s.releases {
+			fmt.Fprintf(&b, `<item><title>%s</title><link>%s</link></item>`, html.EscapeString(release.title), html.EscapeString(release.magnet()))
+		}
+		b.WriteString(`</channel></rss>`)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(b.String()))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// setReleases 替换订阅源中的条目
+func (s *testFeedServer) setReleases(releases ...testRelease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases = releases
+}
+
+// createTestFeed 创建指向测试订阅源服务的通用RSS源
+func createTestFeed(t *testing.T, db *gorm.DB, server *testFeedServer) models.RSSFeed {
+	t.Helper()
+	feed := models.RSSFeed{Name: "测试订阅源", URL: server.URL, ParserType: "generic_rss", UpdateInterval: 1, Enabled: true}
+	if err := db.Create(&feed).Error; err != nil {
+		t.Fatalf("创建RSS源失败: %v", err)
+	}
+	return feed
+}
+
+// ingestFeed 更新一次RSS源
+func ingestFeed(t *testing.T, db *gorm.DB, feed models.RSSFeed) {
+	t.Helper()
+	if err := rss.UpdateSingleRSSFeed(context.Background(), db, feed.ID); err != nil {
+		t.Fatalf("更新RSS源失败: %v", err)
+	}
+}
+
+// findItemByURL 按种子链接查找RSS条目，不存在时返回nil
+func findItemByURL(t *testing.T, db *gorm.DB, url string) *models.RSSItem {
+	t.Helper()
+	var items []models.RSSItem
+	if err := db.Where("url = ?", url).Find(&items).Error; err != nil {
+		t.Fatalf("查询RSS条目失败: %v", err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return &items[0]
+}
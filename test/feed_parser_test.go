@@ -0,0 +1,160 @@
+package test
+
+import (
+	"backend/utils/parser"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loadFeedFixture 读取 testdata/feeds 下的订阅源样例
+func loadFeedFixture(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", "feeds", name))
+	if err != nil {
+		t.Fatalf("读取样例文件失败 %s: %v", name, err)
+	}
+	return string(content)
+}
+
+// TestParseFeedFormats 测试RSS 2.0、Atom 1.0和RSS 1.0 (RDF)格式自动识别与标准化
+func TestParseFeedFormats(t *testing.T) {
+	testCases := []struct {
+		fixture  string
+		format   string
+		title    string
+		link     string
+		count    int
+		expected parser.FeedItem
+		testName string
+	}{
+		{
+			fixture: "rss2.xml",
+			format:  parser.FeedFormatRSS2,
+			title:   "Mikan Project - 葬送的芙莉莲",
+			link:    "http://mikanani.me/RSS/Bangumi?bangumiId=3141",
+			count:   1,
+			expected: parser.FeedItem{
+				Title:         "[喵萌奶茶屋&LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 28 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+				Link:          "https://mikanani.me/Home/Episode/abc123",
+				EnclosureURL:  "https://mikanani.me/Download/20240322/abc123.torrent",
+				EnclosureType: "application/x-bittorrent",
+				ContentLength: 734003200,
+			},
+			testName: "RSS 2.0",
+		},
+		{
+			fixture: "atom.xml",
+			format:  parser.FeedFormatAtom,
+			title:   "Example Release Tracker",
+			link:    "https://releases.example.org/",
+			count:   2,
+			expected: parser.FeedItem{
+				Title:         "[动漫国字幕组&LoliHouse] THE MARGINAL SERVICE - 08 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+				Link:          "https://releases.example.org/view/1001",
+				GUID:          "https://releases.example.org/view/1001",
+				EnclosureURL:  "https://releases.example.org/download/1001.torrent",
+				EnclosureType: "application/x-bittorrent",
+				ContentLength: 524288000,
+				PubDate:       time.Date(2024, 3, 22, 15, 30, 0, 0, time.UTC),
+			},
+			testName: "Atom 1.0",
+		},
+		{
+			fixture: "rdf.xml",
+			format:  parser.FeedFormatRSS1,
+			title:   "RDF Release Feed",
+			link:    "https://rdf.example.org/",
+			count:   1,
+			expected: parser.FeedItem{
+				Title:         "[喵萌奶茶屋&LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 27 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+				Link:          "https://rdf.example.org/item/42",
+				GUID:          "https://rdf.example.org/item/42",
+				EnclosureURL:  "https://rdf.example.org/download/42.torrent",
+				EnclosureType: "application/x-bittorrent",
+				ContentLength: 700000000,
+				PubDate:       time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC),
+			},
+			testName: "RSS 1.0 (RDF)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			feed, err := parser.ParseFeed(loadFeedFixture(t, tc.fixture))
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+
+			if feed.Format != tc.format {
+				t.Errorf("格式不匹配，期望: %s, 实际: %s", tc.format, feed.Format)
+			}
+			if feed.Title != tc.title {
+				t.Errorf("订阅源标题不匹配，期望: %s, 实际: %s", tc.title, feed.Title)
+			}
+			if feed.Link != tc.link {
+				t.Errorf("订阅源链接不匹配，期望: %s, 实际: %s", tc.link, feed.Link)
+			}
+			if len(feed.Items) != tc.count {
+				t.Fatalf("条目数量不匹配，期望: %d, 实际: %d", tc.count, len(feed.Items))
+			}
+
+			item := feed.Items[0]
+			if item.Title != tc.expected.Title {
+				t.Errorf("标题不匹配，期望: %s, 实际: %s", tc.expected.Title, item.Title)
+			}
+			if item.Link != tc.expected.Link {
+				t.Errorf("链接不匹配，期望: %s, 实际: %s", tc.expected.Link, item.Link)
+			}
+			if tc.expected.GUID != "" && item.GUID != tc.expected.GUID {
+				t.Errorf("GUID不匹配，期望: %s, 实际: %s", tc.expected.GUID, item.GUID)
+			}
+			if item.EnclosureURL != tc.expected.EnclosureURL {
+				t.Errorf("种子链接不匹配，期望: %s, 实际: %s", tc.expected.EnclosureURL, item.EnclosureURL)
+			}
+			if item.EnclosureType != tc.expected.EnclosureType {
+				t.Errorf("种子类型不匹配，期望: %s, 实际: %s", tc.expected.EnclosureType, item.EnclosureType)
+			}
+			if item.ContentLength != tc.expected.ContentLength {
+				t.Errorf("文件大小不匹配，期望: %d, 实际: %d", tc.expected.ContentLength, item.ContentLength)
+			}
+			if item.PubDate.IsZero() {
+				t.Errorf("发布时间解析失败")
+			}
+			if !tc.expected.PubDate.IsZero() && !item.PubDate.Equal(tc.expected.PubDate) {
+				t.Errorf("发布时间不匹配，期望: %v, 实际: %v", tc.expected.PubDate, item.PubDate)
+			}
+		})
+	}
+}
+
+// TestParseFeedAtomFallbacks 测试Atom条目缺少published和rel属性时的回退逻辑
+func TestParseFeedAtomFallbacks(t *testing.T) {
+	feed, err := parser.ParseFeed(loadFeedFixture(t, "atom.xml"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	item := feed.Items[1]
+	if item.Link != "https://releases.example.org/view/1002" {
+		t.Errorf("未指定rel的链接应视为alternate，实际: %s", item.Link)
+	}
+	if item.EnclosureURL != "" {
+		t.Errorf("无enclosure的条目不应有种子链接，实际: %s", item.EnclosureURL)
+	}
+	expected := time.Date(2024, 3, 21, 4, 0, 0, 0, time.UTC)
+	if !item.PubDate.Equal(expected) {
+		t.Errorf("缺少published时应使用updated，期望: %v, 实际: %v", expected, item.PubDate)
+	}
+}
+
+// TestParseFeedUnsupported 测试不支持的格式和无效XML
+func TestParseFeedUnsupported(t *testing.T) {
+	if _, err := parser.ParseFeed(`<?xml version="1.0"?><html><body></body></html>`); err == nil {
+		t.Errorf("非订阅源文档应返回错误")
+	}
+	if _, err := parser.ParseFeed("not xml at all"); err == nil {
+		t.Errorf("无效XML应返回错误")
+	}
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"backend/services/filter"
+	"testing"
+)
+
+// TestFilterEngineMatch 测试各匹配字段和匹配方式
+func TestFilterEngineMatch(t *testing.T) {
+	subject := filter.Subject{
+		RawTitle:      "[喵萌奶茶屋&LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 28 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+		OfficialTitle: "葬送的芙莉莲",
+		Group:         "喵萌奶茶屋&LoliHouse",
+		Resolution:    "1080p",
+		Sub:           "简繁内封字幕",
+		Source:        "WebRip",
+		Episode:       28,
+		HasEpisode:    true,
+	}
+
+	testCases := []struct {
+		rule     filter.Rule
+		expected bool
+		testName string
+	}{
+		{filter.Rule{Field: filter.FieldTitle, Mode: filter.ModeSubstring, Pattern: "芙莉莲"}, true, "标题子串"},
+		{filter.Rule{Field: filter.FieldRawTitle, Mode: filter.ModeSubstring, Pattern: "sousou NO frieren"}, true, "子串不区分大小写"},
+		{filter.Rule{Field: filter.FieldOfficialTitle, Mode: filter.ModeSubstring, Pattern: "Frieren"}, false, "番剧名不含英文名"},
+		{filter.Rule{Field: filter.FieldRawTitle, Mode: filter.ModeRegex, Pattern: `- \d{2} \[`}, true, "正则"},
+		{filter.Rule{Field: filter.FieldRawTitle, Mode: filter.ModeRegex, Pattern: `(?i)\bpv\b`}, false, "正则未命中"},
+		{filter.Rule{Field: filter.FieldGroup, Mode: filter.ModeGlob, Pattern: "喵萌*"}, true, "通配符前缀"},
+		{filter.Rule{Field: filter.FieldGroup, Mode: filter.ModeGlob, Pattern: "LoliHouse"}, false, "通配符整串匹配"},
+		{filter.Rule{Field: filter.FieldResolution, Mode: filter.ModeGlob, Pattern: "????P"}, true, "通配符单字符且不区分大小写"},
+		{filter.Rule{Field: filter.FieldSub, Mode: filter.ModeSubstring, Pattern: "繁"}, true, "字幕"},
+		{filter.Rule{Field: filter.FieldSource, Mode: filter.ModeSubstring, Pattern: "Baha"}, false, "来源未命中"},
+		{filter.Rule{Field: filter.FieldEpisode, Pattern: "28"}, true, "单集"},
+		{filter.Rule{Field: filter.FieldEpisode, Pattern: "1-12"}, false, "集数范围外"},
+		{filter.Rule{Field: filter.FieldEpisode, Pattern: "25-"}, true, "集数无上限"},
+		{filter.Rule{Field: filter.FieldEpisode, Pattern: "-28"}, true, "集数无下限"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			tc.rule.Type = filter.TypeExclude
+			engine, err := filter.NewEngine([]filter.Rule{tc.rule})
+			if err != nil {
+				t.Fatalf("创建引擎失败: %v", err)
+			}
+			if _, ok := engine.MatchExclude(subject); ok != tc.expected {
+				t.Errorf("规则 %s 匹配结果不符，期望: %t, 实际: %t", tc.rule, tc.expected, ok)
+			}
+		})
+	}
+}
+
+// TestFilterEngineTypes 测试包含和排除规则分别匹配，以及旧关键词转换
+func TestFilterEngineTypes(t *testing.T) {
+	rules := filter.KeywordRules(filter.TypeExclude, "预告, PV", "")
+	rules = append(rules, filter.KeywordRules(filter.TypeInclude, "", "葬送的芙莉莲")...)
+	rules = append(rules, filter.Rule{ID: 7, Type: filter.TypeExclude, Field: filter.FieldEpisode, Pattern: "0"})
+
+	engine, err := filter.NewEngine(rules)
+	if err != nil {
+		t.Fatalf("创建引擎失败: %v", err)
+	}
+
+	pv := filter.Subject{RawTitle: "[某字幕组] 葬送的芙莉莲 PV"}
+	if rule, ok := engine.MatchExclude(pv); !ok || rule.Pattern != "PV" {
+		t.Errorf("应命中排除关键词PV，实际: %v %t", rule, ok)
+	}
+
+	normal := filter.Subject{RawTitle: "[某字幕组] Sousou no Frieren - 01", OfficialTitle: "葬送的芙莉莲", Episode: 1, HasEpisode: true}
+	if _, ok := engine.MatchExclude(normal); ok {
+		t.Errorf("普通条目不应命中排除规则")
+	}
+	if _, ok := engine.MatchInclude(normal); !ok {
+		t.Errorf("番剧名应命中包含关键词")
+	}
+
+	special := filter.Subject{RawTitle: "[某字幕组] Sousou no Frieren - 00", Episode: 0, HasEpisode: true}
+	if rule, ok := engine.MatchExclude(special); !ok || rule.ID != 7 {
+		t.Errorf("第0集应命中集数排除规则，实际: %v %t", rule, ok)
+	}
+
+	unknown := filter.Subject{RawTitle: "[某字幕组] Sousou no Frieren 合集"}
+	if _, ok := engine.MatchExclude(unknown); ok {
+		t.Errorf("未解析出集数时集数规则不应命中")
+	}
+}
+
+// TestFilterRuleValidate 测试规则校验
+func TestFilterRuleValidate(t *testing.T) {
+	invalid := []filter.Rule{
+		{Type: "maybe", Field: filter.FieldTitle, Mode: filter.ModeSubstring, Pattern: "a"},
+		{Type: filter.TypeInclude, Field: "bitrate", Mode: filter.ModeSubstring, Pattern: "a"},
+		{Type: filter.TypeInclude, Field: filter.FieldTitle, Mode: "fuzzy", Pattern: "a"},
+		{Type: filter.TypeInclude, Field: filter.FieldTitle, Mode: filter.ModeRegex, Pattern: "("},
+		{Type: filter.TypeInclude, Field: filter.FieldTitle, Mode: filter.ModeSubstring, Pattern: " "},
+		{Type: filter.TypeInclude, Field: filter.FieldEpisode, Pattern: "12-1"},
+		{Type: filter.TypeInclude, Field: filter.FieldEpisode, Pattern: "abc"},
+	}
+	for _, rule := range invalid {
+		if err := filter.Validate(rule); err == nil {
+			t.Errorf("规则 %+v 应校验失败", rule)
+		}
+	}
+
+	if err := filter.Validate(filter.Rule{Type: filter.TypeExclude, Field: filter.FieldEpisode, Pattern: "1-12"}); err != nil {
+		t.Errorf("集数规则不需要匹配方式: %v", err)
+	}
+}
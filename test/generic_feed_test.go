@@ -0,0 +1,96 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/rss"
+	"context"
+	"testing"
+)
+
+// TestGenericFeedParsePage 测试通用RSS解析器生成的候选条目：优先使用enclosure，缺少时回退到link，
+// link为磁力链接时同时作为磁力链接保存，缺少标题或链接的条目被跳过
+func TestGenericFeedParsePage(t *testing.T) {
+	feedParser, ok := rss.GetFeedParser("generic_rss")
+	if !ok {
+		t.Fatal("通用RSS解析器未注册")
+	}
+
+	testCases := []struct {
+		fixture  string
+		expected []rss.ReleaseCandidate
+		testName string
+	}{
+		{
+			fixture: "rss2.xml",
+			expected: []rss.ReleaseCandidate{{
+				RawTitle:    "[喵萌奶茶屋&LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 28 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+				Homepage:    "https://mikanani.me/Home/Episode/abc123",
+				TorrentURL:  "https://mikanani.me/Download/20240322/abc123.torrent",
+				ReleaseYear: "2024",
+			}},
+			testName: "RSS 2.0 enclosure",
+		},
+		{
+			fixture: "atom.xml",
+			expected: []rss.ReleaseCandidate{
+				{
+					RawTitle:    "[动漫国字幕组&LoliHouse] THE MARGINAL SERVICE - 08 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+					Homepage:    "https://releases.example.org/view/1001",
+					TorrentURL:  "https://releases.example.org/download/1001.torrent",
+					ReleaseYear: "2024",
+				},
+				{
+					RawTitle:    "[桜都字幕组] 我推的孩子 / Oshi no Ko [第二季][10][1080p][简繁内封]",
+					Homepage:    "https://releases.example.org/view/1002",
+					TorrentURL:  "https://releases.example.org/view/1002",
+					ReleaseYear: "2024",
+				},
+			},
+			testName: "Atom enclosure和link回退",
+		},
+		{
+			fixture: "magnet.xml",
+			expected: []rss.ReleaseCandidate{{
+				RawTitle:    "[LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 27 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+				Homepage:    "https://tracker.example.org/view/2001",
+				TorrentURL:  "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=Frieren-27",
+				MagnetURL:   "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=Frieren-27",
+				ReleaseYear: "2024",
+			}},
+			testName: "link中的磁力链接",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			feed := models.RSSFeed{ParserType: "generic_rss"}
+			feed.ID = 1
+			content := loadFeedFixture(t, tc.fixture)
+			candidates, err := feedParser.ParsePage(context.Background(), feed, feed.URL, []byte(content))
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if len(candidates) != len(tc.expected) {
+				t.Fatalf("候选条目数量不匹配，期望: %d, 实际: %d", len(tc.expected), len(candidates))
+			}
+			for i, expected := range tc.expected {
+				got := candidates[i]
+				if got.RawTitle != expected.RawTitle {
+					t.Errorf("第%d条标题不匹配，期望: %s, 实际: %s", i, expected.RawTitle, got.RawTitle)
+				}
+				if got.Homepage != expected.Homepage {
+					t.Errorf("第%d条主页不匹配，期望: %s, 实际: %s", i, expected.Homepage, got.Homepage)
+				}
+				if got.TorrentURL != expected.TorrentURL {
+					t.Errorf("第%d条种子链接不匹配，期望: %s, 实际: %s", i, expected.TorrentURL, got.TorrentURL)
+				}
+				if got.MagnetURL != expected.MagnetURL {
+					t.Errorf("第%d条磁力链接不匹配，期望: %s, 实际: %s", i, expected.MagnetURL, got.MagnetURL)
+				}
+				if got.ReleaseYear != expected.ReleaseYear {
+					t.Errorf("第%d条发布年份不匹配，期望: %s, 实际: %s", i, expected.ReleaseYear, got.ReleaseYear)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"backend/utils"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestFetchURLConditional 测试条件请求在内容未修改时返回304结果
+func TestFetchURLConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Fri, 22 Mar 2024 15:30:00 GMT"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte("<rss></rss>"))
+	}))
+	defer server.Close()
+
+	first, err := utils.FetchURLConditionalWithRetry(server.URL, "", "", 1, 0)
+	if err != nil {
+		t.Fatalf("首次请求失败: %v", err)
+	}
+	if first.NotModified || string(first.Body) != "<rss></rss>" {
+		t.Errorf("首次请求应返回完整内容，实际: %+v", first)
+	}
+	if first.ETag != etag || first.LastModified != lastModified {
+		t.Errorf("验证头不匹配，ETag: %s, Last-Modified: %s", first.ETag, first.LastModified)
+	}
+
+	second, err := utils.FetchURLConditionalWithRetry(server.URL, first.ETag, first.LastModified, 1, 0)
+	if err != nil {
+		t.Fatalf("条件请求失败: %v", err)
+	}
+	if !second.NotModified || len(second.Body) != 0 {
+		t.Errorf("条件请求应返回304，实际: %+v", second)
+	}
+	if second.ETag != etag || second.LastModified != lastModified {
+		t.Errorf("304响应应沿用原验证头，ETag: %s, Last-Modified: %s", second.ETag, second.LastModified)
+	}
+}
+
+// TestFetchURLConditionalCancel 测试取消后立即中止请求，不再重试
+func TestFetchURLConditionalCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := utils.FetchURLConditionalWithRetryContext(ctx, server.URL, "", "", 3, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("取消后应返回context.Canceled，实际: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("取消后应立即返回，实际耗时 %s", elapsed)
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"backend/utils/parser"
+	"reflect"
+	"testing"
+)
+
+// TestRawParserMediaTags 测试原始标题中编码、色深、封装等结构化信息的解析
+func TestRawParserMediaTags(t *testing.T) {
+	testCases := []struct {
+		rawTitle string
+		expected parser.Episode
+		testName string
+	}{
+		{
+			rawTitle: "[喵萌奶茶屋&LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 28 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]",
+			expected: parser.Episode{
+				SubLanguages: []string{"zh-Hans", "zh-Hant"}, SubForm: parser.SubFormSoftsub,
+				VideoCodec: "hevc", BitDepth: 10, AudioCodec: "aac", Height: 1080, Version: 1,
+			},
+			testName: "内封HEVC",
+		},
+		{
+			rawTitle: "[某字幕组] 葬送的芙莉莲 / Sousou no Frieren [08v2][简日内嵌][1920x1080][AVC AACx2][MP4]",
+			expected: parser.Episode{
+				SubLanguages: []string{"zh-Hans", "ja"}, SubForm: parser.SubFormHardsub,
+				VideoCodec: "avc", AudioCodec: "aac", Container: "mp4", Height: 1080, Version: 2,
+			},
+			testName: "内嵌修正版",
+		},
+		{
+			rawTitle: "[某字幕组] 葬送的芙莉莲 / Sousou no Frieren - 08v3 [CHT][720P][x265 Ma10p FLAC][MKV]",
+			expected: parser.Episode{
+				SubLanguages: []string{"zh-Hant"},
+				VideoCodec:   "hevc", BitDepth: 10, AudioCodec: "flac", Container: "mkv", Height: 720, Version: 3,
+			},
+			testName: "繁体MKV",
+		},
+		{
+			rawTitle: "[某字幕组] 葬送的芙莉莲 / Sousou no Frieren - 08 [4K][AV1 OPUS]",
+			expected: parser.Episode{
+				SubLanguages: []string{},
+				VideoCodec:   "av1", AudioCodec: "opus", Height: 2160, Version: 1,
+			},
+			testName: "4K无字幕信息",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			result := parser.RawParser(tc.rawTitle, "")
+			if result == nil {
+				t.Fatalf("解析失败，结果为nil，原始标题: %s", tc.rawTitle)
+			}
+
+			if !reflect.DeepEqual(result.SubLanguages, tc.expected.SubLanguages) {
+				t.Errorf("字幕语言不匹配，期望: %v, 实际: %v", tc.expected.SubLanguages, result.SubLanguages)
+			}
+			if result.SubForm != tc.expected.SubForm {
+				t.Errorf("字幕形式不匹配，期望: %s, 实际: %s", tc.expected.SubForm, result.SubForm)
+			}
+			if result.VideoCodec != tc.expected.VideoCodec {
+				t.Errorf("视频编码不匹配，期望: %s, 实际: %s", tc.expected.VideoCodec, result.VideoCodec)
+			}
+			if result.BitDepth != tc.expected.BitDepth {
+				t.Errorf("色深不匹配，期望: %d, 实际: %d", tc.expected.BitDepth, result.BitDepth)
+			}
+			if result.AudioCodec != tc.expected.AudioCodec {
+				t.Errorf("音频编码不匹配，期望: %s, 实际: %s", tc.expected.AudioCodec, result.AudioCodec)
+			}
+			if result.Container != tc.expected.Container {
+				t.Errorf("封装格式不匹配，期望: %s, 实际: %s", tc.expected.Container, result.Container)
+			}
+			if result.Height != tc.expected.Height {
+				t.Errorf("视频高度不匹配，期望: %d, 实际: %d", tc.expected.Height, result.Height)
+			}
+			if result.Version != tc.expected.Version {
+				t.Errorf("发布版本不匹配，期望: %d, 实际: %d", tc.expected.Version, result.Version)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"backend/migrations"
+	"backend/models"
+	"testing"
+	"time"
+)
+
+// TestConvertFeedUpdateInterval 测试以秒为单位的旧更新间隔只转换一次，之后以小时保存的值不再被改写
+func TestConvertFeedUpdateInterval(t *testing.T) {
+	db := openTestDB(t, &models.RSSFeed{}, &models.GlobalSettings{})
+	useTestDB(t, db)
+
+	nextRun := time.Now().Add(time.Hour)
+	feeds := []models.RSSFeed{
+		{Name: "默认间隔", URL: "https://example.com/1", UpdateInterval: 3600, NextRunAt: &nextRun},
+		{Name: "半小时", URL: "https://example.com/2", UpdateInterval: 1800},
+		{Name: "一天", URL: "https://example.com/3", UpdateInterval: 86400},
+		{Name: "六秒", URL: "https://example.com/4", UpdateInterval: 6, NextRunAt: &nextRun},
+		{Name: "超过上限", URL: "https://example.com/5", UpdateInterval: 3000000},
+	}
+	if err := db.Create(&feeds).Error; err != nil {
+		t.Fatalf("创建RSS源失败: %v", err)
+	}
+
+	migrations.ConvertFeedUpdateInterval()
+
+	expected := []int{1, 1, 24, 1, models.MaxFeedUpdateInterval}
+	for i, feed := range feeds {
+		var got models.RSSFeed
+		if err := db.First(&got, feed.ID).Error; err != nil {
+			t.Fatalf("读取RSS源失败: %v", err)
+		}
+		if got.UpdateInterval != expected[i] {
+			t.Errorf("%s: 更新间隔期望%d小时，实际%d", feed.Name, expected[i], got.UpdateInterval)
+		}
+		if got.NextRunAt != nil {
+			t.Errorf("%s: 转换后应清除下次运行时间", feed.Name)
+		}
+	}
+
+	settings, err := models.GetGlobalSettings()
+	if err != nil {
+		t.Fatalf("获取全局设置失败: %v", err)
+	}
+	if !settings.FeedIntervalInHours {
+		t.Fatal("转换后应在全局设置中记录")
+	}
+
+	// 转换完成后再次执行不会改写按小时保存的值，也不会清除下次运行时间
+	weekly := models.RSSFeed{Name: "一周", URL: "https://example.com/6", UpdateInterval: 168, NextRunAt: &nextRun}
+	if err := db.Create(&weekly).Error; err != nil {
+		t.Fatalf("创建RSS源失败: %v", err)
+	}
+	migrations.ConvertFeedUpdateInterval()
+
+	var got models.RSSFeed
+	if err := db.First(&got, weekly.ID).Error; err != nil {
+		t.Fatalf("读取RSS源失败: %v", err)
+	}
+	if got.UpdateInterval != 168 || got.NextRunAt == nil {
+		t.Errorf("再次执行不应改写RSS源，实际更新间隔%d，下次运行时间%v", got.UpdateInterval, got.NextRunAt)
+	}
+	var first models.RSSFeed
+	if err := db.First(&first, feeds[0].ID).Error; err != nil {
+		t.Fatalf("读取RSS源失败: %v", err)
+	}
+	if first.UpdateInterval != 1 {
+		t.Errorf("再次执行不应改写已转换的RSS源，实际更新间隔%d", first.UpdateInterval)
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"backend/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestOutboundClientRateLimit 测试同一主机的请求按令牌桶速率发出
+func TestOutboundClientRateLimit(t *testing.T) {
+	var userAgent atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.Header.Get("User-Agent"))
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := utils.NewOutboundClient(utils.OutboundConfig{
+		UserAgent:            "test-agent",
+		RatePerSecond:        10,
+		Burst:                1,
+		MaxConcurrentPerHost: 4,
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	// 容量为1、每秒10个令牌，4个请求至少需要约300ms
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("请求未被限速，耗时: %v", elapsed)
+	}
+	if ua, _ := userAgent.Load().(string); ua != "test-agent" {
+		t.Errorf("User-Agent不匹配，期望: test-agent, 实际: %s", ua)
+	}
+}
+
+// TestOutboundClientConcurrency 测试同一主机的并发请求数不超过上限
+func TestOutboundClientConcurrency(t *testing.T) {
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+	}))
+	defer server.Close()
+
+	client := utils.NewOutboundClient(utils.OutboundConfig{
+		RatePerSecond:        1000,
+		Burst:                100,
+		MaxConcurrentPerHost: 2,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("请求失败: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("并发数超过上限，期望: <=2, 实际: %d", peak)
+	}
+}
+
+// TestOutboundClientRetryAfter 测试收到429后按Retry-After暂停对该主机的请求
+func TestOutboundClientRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := utils.NewOutboundClient(utils.OutboundConfig{
+		RatePerSecond:        100,
+		Burst:                10,
+		MaxConcurrentPerHost: 1,
+	})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("状态码不匹配，期望: 429, 实际: %d", resp.StatusCode)
+	}
+
+	start := time.Now()
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("未遵守Retry-After，等待时间: %v", elapsed)
+	}
+}
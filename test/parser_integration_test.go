@@ -18,7 +18,7 @@ func TestMikanAndRawParserIntegration(t *testing.T) {
 		t.Fatalf("未获取到原始标题")
 	}
 
-	episode := parser.RawParser(originalTitle)
+	episode := parser.RawParser(originalTitle, "")
 	if episode == nil {
 		t.Fatalf("RawParser 解析失败")
 	}
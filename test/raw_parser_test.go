@@ -24,6 +24,7 @@ func TestRawParser(t *testing.T) {
 				SeasonRaw:  "",
 				Episode:    8,
 				Sub:        "简繁内封字幕",
+				SubLang:    "zh-Hans+zh-Hant",
 				Group:      "动漫国字幕组&LoliHouse",
 				Resolution: "1080p",
 				Source:     "WebRip",
@@ -40,6 +41,7 @@ func TestRawParser(t *testing.T) {
 				SeasonRaw:  "",
 				Episode:    28,
 				Sub:        "简繁内封字幕",
+				SubLang:    "zh-Hans+zh-Hant",
 				Group:      "喵萌奶茶屋&LoliHouse",
 				Resolution: "1080p",
 				Source:     "WebRip",
@@ -56,6 +58,7 @@ func TestRawParser(t *testing.T) {
 				SeasonRaw:  "第二季",
 				Episode:    10,
 				Sub:        "简繁内封",
+				SubLang:    "zh-Hans+zh-Hant",
 				Group:      "桜都字幕组",
 				Resolution: "1080p",
 				Source:     "",
@@ -67,7 +70,7 @@ func TestRawParser(t *testing.T) {
 	// 运行测试用例
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			result := parser.RawParser(tc.rawTitle)
+			result := parser.RawParser(tc.rawTitle, "")
 
 			// 检查解析结果是否为nil
 			if result == nil {
@@ -94,6 +97,9 @@ func TestRawParser(t *testing.T) {
 			if result.Episode != tc.expected.Episode {
 				t.Errorf("集数不匹配，期望: %d, 实际: %d", tc.expected.Episode, result.Episode)
 			}
+			if result.SubLang != tc.expected.SubLang {
+				t.Errorf("字幕语言不匹配，期望: %s, 实际: %s", tc.expected.SubLang, result.SubLang)
+			}
 			if result.Group != tc.expected.Group {
 				t.Errorf("字幕组不匹配，期望: %s, 实际: %s", tc.expected.Group, result.Group)
 			}
@@ -103,12 +109,12 @@ func TestRawParser(t *testing.T) {
 
 // ExampleRawParser 展示RawParser的使用示例
 func ExampleRawParser() {
-	title := "[喵萌奶茶屋&LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 28 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]"
-	episode := parser.RawParser(title)
+	title := "[动漫国字幕组&LoliHouse] THE MARGINAL SERVICE - 08 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]"
+	episode := parser.RawParser(title, "")
 
-	fmt.Printf("英文名称: %s\n", episode.NameEn)
-	fmt.Printf("中文名称: %s\n", episode.NameZh)
-	fmt.Printf("日文名称: %s\n", episode.NameJp)
+	fmt.Printf("英文名称: %q\n", episode.NameEn)
+	fmt.Printf("中文名称: %q\n", episode.NameZh)
+	fmt.Printf("日文名称: %q\n", episode.NameJp)
 	fmt.Printf("季度: %d\n", episode.Season)
 	fmt.Printf("集数: %d\n", episode.Episode)
 	fmt.Printf("字幕组: %s\n", episode.Group)
@@ -116,9 +122,9 @@ func ExampleRawParser() {
 	fmt.Printf("分辨率: %s\n", episode.Resolution)
 	fmt.Printf("来源: %s\n", episode.Source)
 	// Output:
-	// 英文名称: THE MARGINAL SERVICE
-	// 中文名称:
-	// 日文名称:
+	// 英文名称: "THE MARGINAL SERVICE"
+	// 中文名称: ""
+	// 日文名称: ""
 	// 季度: 1
 	// 集数: 8
 	// 字幕组: 动漫国字幕组&LoliHouse
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"backend/utils/parser"
+	"testing"
+)
+
+// TestRawParserRelease 测试合集、特别篇、半集和剧场版的识别
+func TestRawParserRelease(t *testing.T) {
+	testCases := []struct {
+		rawTitle string
+		kind     string
+		start    float64
+		end      float64
+		nameZh   string
+		nameEn   string
+		testName string
+	}{
+		{"[喵萌奶茶屋&LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 28 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]", parser.ReleaseKindEpisode, 28, 28, "葬送的芙莉莲", "Sousou no Frieren", "单集"},
+		{"[某字幕组] 葬送的芙莉莲 / Sousou no Frieren [01-28 合集][1080p][简日双语]", parser.ReleaseKindBatch, 1, 28, "葬送的芙莉莲", "Sousou no Frieren", "方括号合集"},
+		{"[某字幕组] 葬送的芙莉莲 / Sousou no Frieren - 01~12 [1080p][CHS]", parser.ReleaseKindBatch, 1, 12, "葬送的芙莉莲", "Sousou no Frieren", "集数范围"},
+		{"[某字幕组] 葬送的芙莉莲 / Sousou no Frieren [Fin][BDRip 1080p][CHS]", parser.ReleaseKindBatch, 0, 0, "葬送的芙莉莲", "Sousou no Frieren", "完结合集"},
+		{"[某字幕组] 葬送的芙莉莲 / Sousou no Frieren [12.5][1080p][CHS]", parser.ReleaseKindEpisode, 12.5, 12.5, "葬送的芙莉莲", "Sousou no Frieren", "半集"},
+		{"[某字幕组] 葬送的芙莉莲 / Sousou no Frieren - 12.5 [1080p][CHS]", parser.ReleaseKindEpisode, 12.5, 12.5, "葬送的芙莉莲", "Sousou no Frieren", "横线半集"},
+		{"[某字幕组] 葬送的芙莉莲 OVA / Sousou no Frieren OVA - 02 [1080p][CHS]", parser.ReleaseKindSpecial, 2, 2, "葬送的芙莉莲", "Sousou no Frieren", "OVA"},
+		{"[某字幕组] 间谍过家家 / SPY×FAMILY - 05 [1080p][CHS]", parser.ReleaseKindEpisode, 5, 5, "间谍过家家", "SPY×FAMILY", "SPY不是特别篇"},
+		{"[某字幕组] 剧场版 紫罗兰永恒花园 / Violet Evergarden the Movie [1080p][简体]", parser.ReleaseKindMovie, 0, 0, "紫罗兰永恒花园", "Violet Evergarden", "剧场版"},
+		{"[LoliHouse] 剧场版 铃芽之旅 / Suzume no Tojimari [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]", parser.ReleaseKindMovie, 0, 0, "铃芽之旅", "Suzume no Tojimari", "剧场版在名称前"},
+		{"[SweetSub] 剧场版 孤独摇滚 Re: [WebRip][1080P]", parser.ReleaseKindMovie, 0, 0, "孤独摇滚", "", "只有剧场版标记和中文名"},
+		{"[某字幕组] 葬送的芙莉莲 SP1 / Sousou no Frieren SP1 [1080p][CHS]", parser.ReleaseKindSpecial, 1, 1, "葬送的芙莉莲", "Sousou no Frieren", "SP编号"},
+		{"[某字幕组] 葬送的芙莉莲 合集 / Sousou no Frieren [1080p][CHS]", parser.ReleaseKindBatch, 0, 0, "葬送的芙莉莲", "Sousou no Frieren", "名称中的合集"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			result := parser.RawParser(tc.rawTitle, "")
+			if result == nil {
+				t.Fatalf("解析失败，结果为nil，原始标题: %s", tc.rawTitle)
+			}
+			if result.ReleaseKind != tc.kind {
+				t.Errorf("发布类型不匹配，期望: %s, 实际: %s", tc.kind, result.ReleaseKind)
+			}
+			if result.EpisodeStart != tc.start || result.EpisodeEnd != tc.end {
+				t.Errorf("集数范围不匹配，期望: %g-%g, 实际: %g-%g", tc.start, tc.end, result.EpisodeStart, result.EpisodeEnd)
+			}
+			if result.NameZh != tc.nameZh {
+				t.Errorf("中文名称不匹配，期望: %s, 实际: %s", tc.nameZh, result.NameZh)
+			}
+			if result.NameEn != tc.nameEn {
+				t.Errorf("英文名称不匹配，期望: %s, 实际: %s", tc.nameEn, result.NameEn)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/rss"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStartReparsePreview 测试预览在后台执行且不写入数据库，服务关闭时等待任务结束
+func TestStartReparsePreview(t *testing.T) {
+	f := newMergeFixture(t, 0)
+	b := f.bangumi(t, "葬送的芙莉莲", 0)
+	stale := f.item(t, b, "https://example.com/1.torrent", 1)
+	if err := f.db.Model(&stale).Update("raw_title", "[LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 05 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]").Error; err != nil {
+		t.Fatalf("设置原始标题失败: %v", err)
+	}
+
+	if err := rss.StartReparse(f.db, rss.ReparseOptions{BatchSize: 1}); err != nil {
+		t.Fatalf("启动重新解析失败: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rss.ShutdownReparse(ctx)
+
+	status := rss.GetReparseStatus()
+	if status.Running || status.FinishedAt == nil {
+		t.Fatal("ShutdownReparse返回后任务应已结束")
+	}
+	if status.Error != "" {
+		t.Fatalf("重新解析失败: %s", status.Error)
+	}
+	if status.Summary == nil || status.Summary.Applied || status.Summary.Changed != 1 || status.Summary.EpisodeChanges != 1 {
+		t.Fatalf("预览摘要不匹配: %+v", status.Summary)
+	}
+
+	var item models.RSSItem
+	if err := f.db.First(&item, stale.ID).Error; err != nil {
+		t.Fatalf("查询条目失败: %v", err)
+	}
+	if item.Episode == nil || *item.Episode != 1 {
+		t.Errorf("预览不应写入数据库，集数应保持1，实际: %v", item.Episode)
+	}
+}
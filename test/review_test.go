@@ -0,0 +1,199 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/rss"
+	"math"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// findReview 按原始标题查找待审核条目，不存在时返回nil
+func findReview(t *testing.T, db *gorm.DB, rawTitle string) *models.ReviewItem {
+	t.Helper()
+	var reviews []models.ReviewItem
+	if err := db.Where("title_hash = ?", models.TitleHash(rawTitle)).Find(&reviews).Error; err != nil {
+		t.Fatalf("查询待审核条目失败: %v", err)
+	}
+	if len(reviews) > 1 {
+		t.Fatalf("标题 '%s' 记录了%d个待审核条目，应只记录一次", rawTitle, len(reviews))
+	}
+	if len(reviews) == 0 {
+		return nil
+	}
+	return &reviews[0]
+}
+
+// TestReviewReasonsAndConfidence 测试收录时按低置信度原因加入待审核队列并计算置信度
+func TestReviewReasonsAndConfidence(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	newBangumi := frierenRelease(1, "LoliHouse", "05", "1080p", "简繁内封字幕")
+	known := frierenRelease(2, "LoliHouse", "06", "1080p", "简繁内封字幕")
+	noEpisode := frierenRelease(3, "LoliHouse", "00", "1080p", "简繁内封字幕")
+	seasonUnclear := testRelease{title: "[LoliHouse] 葬送的芙莉莲 Part 2 / Sousou no Frieren Part 2 - 05 [WebRip 1080p HEVC-10bit AAC][简繁内封字幕]", n: 4}
+	parseFailed := testRelease{title: "葬送的芙莉莲 第5话 简体", n: 5}
+	server.setReleases(newBangumi, known, noEpisode, seasonUnclear, parseFailed)
+	ingestFeed(t, db, feed)
+
+	testCases := []struct {
+		release    testRelease
+		reasons    string
+		confidence float64
+		ingested   bool
+		testName   string
+	}{
+		{newBangumi, models.ReviewReasonNewBangumi, 0.8, true, "新建番剧"},
+		{noEpisode, models.ReviewReasonNoEpisode, 0.6, true, "未识别集数"},
+		{seasonUnclear, models.ReviewReasonSeasonUnclear + "," + models.ReviewReasonNewBangumi, 0.5, true, "季度不明确"},
+		{parseFailed, models.ReviewReasonParseFailed, 0, false, "解析失败"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			review := findReview(t, db, tc.release.title)
+			if review == nil {
+				t.Fatalf("标题 '%s' 应加入待审核队列", tc.release.title)
+			}
+			if review.Reasons != tc.reasons {
+				t.Errorf("低置信度原因不匹配，期望: %s, 实际: %s", tc.reasons, review.Reasons)
+			}
+			if math.Abs(review.Confidence-tc.confidence) > 1e-9 {
+				t.Errorf("置信度不匹配，期望: %g, 实际: %g", tc.confidence, review.Confidence)
+			}
+			if review.Status != models.ReviewStatusPending {
+				t.Errorf("状态应为pending，实际: %s", review.Status)
+			}
+
+			item := findItemByURL(t, db, tc.release.magnet())
+			if tc.ingested != (item != nil) {
+				t.Fatalf("条目是否自动入库不匹配，期望: %t", tc.ingested)
+			}
+			if item != nil && (review.RSSItemID == nil || *review.RSSItemID != item.ID) {
+				t.Errorf("待审核条目应关联已入库的条目[ID:%d]，实际: %v", item.ID, review.RSSItemID)
+			}
+		})
+	}
+
+	if review := findReview(t, db, known.title); review != nil {
+		t.Errorf("匹配到已有番剧的条目不应加入待审核队列，实际原因: %s", review.Reasons)
+	}
+}
+
+// TestAssignReviewItem 测试人工指定番剧和集数后，条目入库并生成覆盖规则，之后相同标题的条目按规则收录
+func TestAssignReviewItem(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	known := frierenRelease(1, "LoliHouse", "05", "1080p", "简繁内封字幕")
+	parseFailed := testRelease{title: "葬送的芙莉莲 第6话 简体", n: 2}
+	server.setReleases(known, parseFailed)
+	ingestFeed(t, db, feed)
+
+	bangumiID := requireItem(t, db, known).BangumiID
+	review := findReview(t, db, parseFailed.title)
+	if review == nil {
+		t.Fatal("解析失败的标题应加入待审核队列")
+	}
+	episode := 6.0
+	if err := rss.AssignReviewItem(db, review, bangumiID, &episode); err != nil {
+		t.Fatalf("人工指定失败: %v", err)
+	}
+
+	assigned := requireItem(t, db, parseFailed)
+	if assigned.BangumiID != bangumiID || assigned.Episode == nil || *assigned.Episode != episode {
+		t.Errorf("条目应归入番剧[ID:%d]第%g集，实际: 番剧[ID:%d] 集数%v", bangumiID, episode, assigned.BangumiID, assigned.Episode)
+	}
+	if review = findReview(t, db, parseFailed.title); review.Status != models.ReviewStatusAssigned || review.RSSItemID == nil || *review.RSSItemID != assigned.ID {
+		t.Errorf("待审核条目应标记为已指定并关联条目[ID:%d]，实际: %s %v", assigned.ID, review.Status, review.RSSItemID)
+	}
+
+	var override models.TitleOverride
+	if err := db.Where("title_hash = ?", models.TitleHash(parseFailed.title)).First(&override).Error; err != nil {
+		t.Fatalf("应生成覆盖规则: %v", err)
+	}
+	if override.Discard || override.BangumiID != bangumiID || override.Episode == nil || *override.Episode != episode {
+		t.Errorf("覆盖规则不匹配: %+v", override)
+	}
+
+	// 相同标题重新发布（如换了种子）时直接按覆盖规则收录，不再加入待审核队列
+	republished := testRelease{title: parseFailed.title, n: 3}
+	server.setReleases(republished)
+	ingestFeed(t, db, feed)
+	item := requireItem(t, db, republished)
+	if item.BangumiID != bangumiID || item.Episode == nil || *item.Episode != episode {
+		t.Errorf("重新发布的条目应按覆盖规则归入番剧[ID:%d]第%g集，实际: 番剧[ID:%d] 集数%v", bangumiID, episode, item.BangumiID, item.Episode)
+	}
+	findReview(t, db, parseFailed.title)
+}
+
+// TestDiscardReviewItem 测试忽略待审核条目后删除已入库的条目并生成忽略规则，之后相同标题的条目不再收录
+func TestDiscardReviewItem(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	release := frierenRelease(1, "LoliHouse", "00", "1080p", "简繁内封字幕")
+	server.setReleases(release)
+	ingestFeed(t, db, feed)
+
+	review := findReview(t, db, release.title)
+	if review == nil || review.RSSItemID == nil {
+		t.Fatal("自动入库的低置信度条目应加入待审核队列并关联条目")
+	}
+	if err := rss.DiscardReviewItem(db, review); err != nil {
+		t.Fatalf("忽略失败: %v", err)
+	}
+
+	if item := findItemByURL(t, db, release.magnet()); item != nil {
+		t.Errorf("忽略后应删除已入库的条目[ID:%d]", item.ID)
+	}
+	if review = findReview(t, db, release.title); review.Status != models.ReviewStatusDiscarded {
+		t.Errorf("待审核条目应标记为已忽略，实际: %s", review.Status)
+	}
+	var override models.TitleOverride
+	if err := db.Where("title_hash = ?", models.TitleHash(release.title)).First(&override).Error; err != nil {
+		t.Fatalf("应生成忽略规则: %v", err)
+	}
+	if !override.Discard {
+		t.Errorf("覆盖规则应为忽略: %+v", override)
+	}
+
+	// 再次更新时相同标题命中忽略规则，不再收录也不再加入待审核队列
+	ingestFeed(t, db, feed)
+	if item := findItemByURL(t, db, release.magnet()); item != nil {
+		t.Errorf("命中忽略规则的标题不应再次收录，实际条目[ID:%d]", item.ID)
+	}
+	findReview(t, db, release.title)
+}
+
+// TestDiscardReviewItemRelinksVersions 测试忽略新版本后，被它取代的旧版本重新关联仍存在的最新版本
+func TestDiscardReviewItemRelinksVersions(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	v1 := frierenRelease(1, "LoliHouse", "00", "1080p", "简繁内封字幕")
+	v2 := frierenRelease(2, "LoliHouse", "00v2", "1080p", "简繁内封字幕")
+	v3 := frierenRelease(3, "LoliHouse", "00v3", "1080p", "简繁内封字幕")
+	for _, release := range []testRelease{v1, v2, v3} {
+		server.setReleases(release)
+		ingestFeed(t, db, feed)
+	}
+	assertSupersededBy(t, requireItem(t, db, v2), requireItem(t, db, v3))
+
+	review := findReview(t, db, v3.title)
+	if review == nil || review.RSSItemID == nil {
+		t.Fatal("自动入库的低置信度条目应加入待审核队列并关联条目")
+	}
+	if err := rss.DiscardReviewItem(db, review); err != nil {
+		t.Fatalf("忽略失败: %v", err)
+	}
+
+	v2Item := requireItem(t, db, v2)
+	assertSupersededBy(t, v2Item, nil)
+	assertSupersededBy(t, requireItem(t, db, v1), v2Item)
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/leader"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openLockTestDB 打开独立的内存SQLite数据库并创建锁表
+func openLockTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(&models.SchedulerLock{}); err != nil {
+		t.Fatalf("创建锁表失败: %v", err)
+	}
+	return db
+}
+
+// TestElectorSingleLeader 测试同一时间只有一个实例持有租约，过期后由其他实例接管
+func TestElectorSingleLeader(t *testing.T) {
+	db := openLockTestDB(t)
+	ttl := time.Minute
+	a := leader.NewElector(db, models.SchedulerLockRSS, "instance-a", ttl)
+	b := leader.NewElector(db, models.SchedulerLockRSS, "instance-b", ttl)
+	now := time.Date(2024, 3, 10, 10, 0, 0, 0, time.Local)
+
+	if ok, err := a.TryAcquire(now); err != nil || !ok {
+		t.Fatalf("实例A应获得租约: %t, %v", ok, err)
+	}
+	if ok, err := b.TryAcquire(now.Add(10 * time.Second)); err != nil || ok {
+		t.Fatalf("租约有效期内实例B不应获得租约: %t, %v", ok, err)
+	}
+
+	// 实例A续约后，原到期时间之后实例B仍不能接管
+	if ok, err := a.TryAcquire(now.Add(30 * time.Second)); err != nil || !ok {
+		t.Fatalf("实例A应续约成功: %t, %v", ok, err)
+	}
+	if ok, _ := b.TryAcquire(now.Add(70 * time.Second)); ok {
+		t.Fatal("续约后的有效期内实例B不应获得租约")
+	}
+
+	// 实例A停止续约，租约过期后实例B接管
+	if ok, err := b.TryAcquire(now.Add(2 * time.Minute)); err != nil || !ok {
+		t.Fatalf("租约过期后实例B应接管: %t, %v", ok, err)
+	}
+	if ok, _ := a.TryAcquire(now.Add(2*time.Minute + time.Second)); ok {
+		t.Fatal("被接管后实例A不应再是主节点")
+	}
+	if a.IsLeader() || !b.IsLeader() {
+		t.Errorf("主节点状态错误: A=%t, B=%t", a.IsLeader(), b.IsLeader())
+	}
+
+	lock, err := leader.Current(db, models.SchedulerLockRSS)
+	if err != nil || lock == nil {
+		t.Fatalf("读取锁记录失败: %v", err)
+	}
+	if lock.Owner != "instance-b" {
+		t.Errorf("持有者错误: 期望 instance-b, 实际为 %s", lock.Owner)
+	}
+	if !lock.AcquiredAt.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("接管时间错误: %s", lock.AcquiredAt)
+	}
+}
+
+// TestElectorRelease 测试主动释放租约后其他实例可以立即接管
+func TestElectorRelease(t *testing.T) {
+	db := openLockTestDB(t)
+	a := leader.NewElector(db, models.SchedulerLockRSS, "instance-a", time.Minute)
+	b := leader.NewElector(db, models.SchedulerLockRSS, "instance-b", time.Minute)
+	now := time.Now()
+
+	if ok, _ := a.TryAcquire(now); !ok {
+		t.Fatal("实例A应获得租约")
+	}
+	if err := a.Release(); err != nil {
+		t.Fatalf("释放租约失败: %v", err)
+	}
+	if a.IsLeader() {
+		t.Error("释放后实例A不应再是主节点")
+	}
+	if ok, err := b.TryAcquire(now.Add(time.Second)); err != nil || !ok {
+		t.Fatalf("释放后实例B应立即获得租约: %t, %v", ok, err)
+	}
+
+	// 释放其他实例持有的租约不产生影响
+	if err := a.Release(); err != nil {
+		t.Fatalf("释放租约失败: %v", err)
+	}
+	if lock, _ := leader.Current(db, models.SchedulerLockRSS); lock == nil || lock.Owner != "instance-b" {
+		t.Errorf("实例A不应释放实例B的租约: %+v", lock)
+	}
+}
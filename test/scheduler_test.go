@@ -0,0 +1,145 @@
+package test
+
+import (
+	"backend/models"
+	"backend/services/rss"
+	"testing"
+	"time"
+)
+
+// TestCronNext 测试cron表达式的下次触发时间
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, 1, 31, 10, 17, 30, 0, time.Local) // 周三
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 30, 0, 0, time.Local)},
+		{"0 * * * *", time.Date(2024, 1, 31, 11, 0, 0, 0, time.Local)},
+		{"@daily", time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"30 9 * * 1-5", time.Date(2024, 2, 1, 9, 30, 0, 0, time.Local)},
+		{"0 8 * * 0", time.Date(2024, 2, 4, 8, 0, 0, 0, time.Local)},
+		{"0 8 * * 7", time.Date(2024, 2, 4, 8, 0, 0, 0, time.Local)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)},
+		{"5,45 10 * * *", time.Date(2024, 1, 31, 10, 45, 0, 0, time.Local)},
+		// 日期和星期同时指定时满足其一即可：1日（周四）早于周五
+		{"0 0 1 * 5", time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := rss.ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", tt.expr, err)
+			continue
+		}
+		if next := schedule.Next(from); !next.Equal(tt.expected) {
+			t.Errorf("%q 的下次触发时间错误: 期望 %s, 实际为 %s", tt.expr, tt.expected, next)
+		}
+	}
+}
+
+// TestCronNeverFires 测试永远不会触发的表达式返回零值
+func TestCronNeverFires(t *testing.T) {
+	schedule, err := rss.ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("2月30日不应触发，实际为 %s", next)
+	}
+}
+
+// TestParseCronInvalid 测试无效的cron表达式
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := rss.ParseCron(expr); err == nil {
+			t.Errorf("%q 应解析失败", expr)
+		}
+	}
+}
+
+// TestQuietHours 测试静默时段的判断和结束时间，包括跨午夜的情况
+func TestQuietHours(t *testing.T) {
+	quiet, err := rss.ParseQuietHours("23:00", "06:30")
+	if err != nil {
+		t.Fatalf("解析静默时段失败: %v", err)
+	}
+
+	day := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 10, hour, minute, 0, 0, time.Local)
+	}
+	for _, tt := range []struct {
+		t        time.Time
+		expected bool
+	}{
+		{day(22, 59), false},
+		{day(23, 0), true},
+		{day(2, 0), true},
+		{day(6, 29), true},
+		{day(6, 30), false},
+		{day(12, 0), false},
+	} {
+		if got := quiet.Contains(tt.t); got != tt.expected {
+			t.Errorf("%s 是否处于静默时段: 期望 %t, 实际为 %t", tt.t.Format("15:04"), tt.expected, got)
+		}
+	}
+
+	if end := quiet.EndAfter(day(23, 30)); !end.Equal(time.Date(2024, 3, 11, 6, 30, 0, 0, time.Local)) {
+		t.Errorf("23:30之后的静默结束时间错误: %s", end)
+	}
+	if end := quiet.EndAfter(day(1, 0)); !end.Equal(day(6, 30)) {
+		t.Errorf("01:00之后的静默结束时间错误: %s", end)
+	}
+
+	if quiet, err := rss.ParseQuietHours("", ""); err != nil || quiet != nil {
+		t.Errorf("未设置静默时段时应返回nil: %v, %v", quiet, err)
+	}
+	for _, pair := range [][2]string{{"23:00", ""}, {"25:00", "06:00"}, {"06:00", "06:00"}} {
+		if _, err := rss.ParseQuietHours(pair[0], pair[1]); err == nil {
+			t.Errorf("静默时段 %s-%s 应解析失败", pair[0], pair[1])
+		}
+	}
+}
+
+// TestNextRunTime 测试下次运行时间：间隔、cron、失败退避、静默时段和随机延后
+func TestNextRunTime(t *testing.T) {
+	from := time.Date(2024, 3, 10, 10, 0, 0, 0, time.Local)
+
+	feed := models.RSSFeed{UpdateInterval: 2}
+	if next := rss.NextRunTime(feed, from, nil, 0); !next.Equal(from.Add(2 * time.Hour)) {
+		t.Errorf("按间隔计算的下次运行时间错误: %s", next)
+	}
+
+	feed.CronExpr = "*/30 * * * *"
+	if next := rss.NextRunTime(feed, from, nil, 0); !next.Equal(from.Add(30 * time.Minute)) {
+		t.Errorf("按cron计算的下次运行时间错误: %s", next)
+	}
+
+	// 无效的表达式按间隔运行
+	feed.CronExpr = "invalid"
+	if next := rss.NextRunTime(feed, from, nil, 0); !next.Equal(from.Add(2 * time.Hour)) {
+		t.Errorf("无效cron表达式应按间隔运行: %s", next)
+	}
+
+	// 连续失败时退避间隔晚于cron时间
+	feed.CronExpr = "*/30 * * * *"
+	feed.ConsecutiveFailures = 2
+	if next := rss.NextRunTime(feed, from, nil, 0); !next.Equal(from.Add(rss.PollInterval(feed))) {
+		t.Errorf("连续失败时应按退避间隔运行: %s", next)
+	}
+
+	// 落在静默时段内顺延到结束时间
+	feed.ConsecutiveFailures = 0
+	quiet, _ := rss.ParseQuietHours("10:15", "12:00")
+	if next := rss.NextRunTime(feed, from, quiet, 0); !next.Equal(from.Add(2 * time.Hour)) {
+		t.Errorf("静默时段内应顺延到结束时间: %s", next)
+	}
+
+	jitter := time.Minute
+	for i := 0; i < 20; i++ {
+		next := rss.NextRunTime(feed, from, nil, jitter)
+		if next.Before(from.Add(30*time.Minute)) || !next.Before(from.Add(30*time.Minute+jitter)) {
+			t.Fatalf("随机延后超出范围: %s", next)
+		}
+	}
+}
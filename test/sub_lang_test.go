@@ -0,0 +1,94 @@
+package test
+
+import (
+	"backend/utils/parser"
+	"testing"
+)
+
+// TestDetectSubLanguages 测试字幕标签的语言识别
+func TestDetectSubLanguages(t *testing.T) {
+	testCases := []struct {
+		tags     []string
+		expected string
+	}{
+		{[]string{"简繁内封字幕"}, "zh-Hans+zh-Hant"},
+		{[]string{"简体"}, "zh-Hans"},
+		{[]string{"CHS"}, "zh-Hans"},
+		{[]string{"CHT"}, "zh-Hant"},
+		{[]string{"BIG5"}, "zh-Hant"},
+		{[]string{"繁日双语"}, "zh-Hant+ja"},
+		{[]string{"简日内嵌"}, "zh-Hans+ja"},
+		{[]string{"JPSC"}, "zh-Hans+ja"},
+		{[]string{"JPTC"}, "zh-Hant+ja"},
+		{[]string{"GB", "JP"}, "zh-Hans+ja"},
+		{[]string{"ENG"}, "en"},
+		{[]string{"中字"}, "zh"},
+		{[]string{"简中"}, "zh-Hans"},
+		{[]string{"内封字幕"}, ""},
+	}
+
+	for _, tc := range testCases {
+		if got := parser.DetectSubLanguages(tc.tags...); got != tc.expected {
+			t.Errorf("%v 的字幕语言不匹配，期望: %s, 实际: %s", tc.tags, tc.expected, got)
+		}
+	}
+}
+
+// TestRawParserSubLanguage 测试原始标题解析出的字幕语言
+func TestRawParserSubLanguage(t *testing.T) {
+	testCases := []struct {
+		rawTitle string
+		expected string
+	}{
+		{"[LoliHouse] 葬送的芙莉莲 / Sousou no Frieren - 28 [WebRip 1080p HEVC-10bit AAC][CHT]", "zh-Hant"},
+		{"[某字幕组] 葬送的芙莉莲 / Sousou no Frieren - 28 [1080p][繁日双语]", "zh-Hant+ja"},
+		{"[某字幕组] 葬送的芙莉莲 / Sousou no Frieren - 28 [1080p][JPSC]", "zh-Hans+ja"},
+		{"[ANi] 葬送的芙莉莲 / Sousou no Frieren - 28 [1080P][Bilibili][WEB-DL][AAC AVC]", "zh-Hans"},
+	}
+
+	for _, tc := range testCases {
+		episode := parser.RawParser(tc.rawTitle, "")
+		if episode == nil {
+			t.Fatalf("解析失败，原始标题: %s", tc.rawTitle)
+		}
+		if episode.SubLang != tc.expected {
+			t.Errorf("字幕语言不匹配，原始标题: %s, 期望: %s, 实际: %s", tc.rawTitle, tc.expected, episode.SubLang)
+		}
+	}
+}
+
+// TestSubtitlePreference 测试字幕语言偏好的解析和匹配
+func TestSubtitlePreference(t *testing.T) {
+	testCases := []struct {
+		preference string
+		subLang    string
+		expected   bool
+	}{
+		{"zh-Hans", "zh-Hans", true},
+		{"zh-Hans", "zh-Hans+zh-Hant", true},
+		{"zh-Hans", "zh-Hant", false},
+		{"zh-Hans", "", false},
+		{"zh-Hans, zh-Hant", "zh-Hant+ja", true},
+		{"ja", "zh-Hant+ja", true},
+		{"zh-Hans+ja", "zh-Hans", false},
+		{"zh-Hans+ja", "zh-Hans+ja", true},
+		{"ZH-HANT", "zh-Hant", true},
+		{"any", "", true},
+	}
+
+	for _, tc := range testCases {
+		pref, err := parser.ParseSubtitlePreference(tc.preference)
+		if err != nil {
+			t.Fatalf("解析字幕偏好 %q 失败: %v", tc.preference, err)
+		}
+		if got := pref.Accepts(tc.subLang); got != tc.expected {
+			t.Errorf("偏好 %q 对字幕语言 %q 的结果不符，期望: %t, 实际: %t", tc.preference, tc.subLang, tc.expected, got)
+		}
+	}
+
+	for _, invalid := range []string{"", " , ", "zh-CN", "zh-Hans+klingon"} {
+		if _, err := parser.ParseSubtitlePreference(invalid); err == nil {
+			t.Errorf("字幕偏好 %q 应解析失败", invalid)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"backend/controllers"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const frierenTitle = "[%s] 葬送的芙莉莲 / Sousou no Frieren - %s [WebRip %s HEVC-10bit AAC][%s]"
+
+// frierenRelease 生成葬送的芙莉莲的发布标题
+func frierenRelease(n int, group, episode, resolution, sub string) testRelease {
+	return testRelease{title: fmt.Sprintf(frierenTitle, group, episode, resolution, sub), n: n}
+}
+
+// requireItem 按发布条目查找已收录的RSS条目，不存在时终止测试
+func requireItem(t *testing.T, db *gorm.DB, release testRelease) *models.RSSItem {
+	t.Helper()
+	item := findItemByURL(t, db, release.magnet())
+	if item == nil {
+		t.Fatalf("条目未收录: %s", release.title)
+	}
+	return item
+}
+
+// assertSupersededBy 检查条目被指定条目取代，by为nil时检查条目未被取代
+func assertSupersededBy(t *testing.T, item *models.RSSItem, by *models.RSSItem) {
+	t.Helper()
+	switch {
+	case by == nil && item.SupersededByID != nil:
+		t.Errorf("条目[%s v%d]不应被取代，实际被条目[ID:%d]取代", item.RawTitle, item.Version, *item.SupersededByID)
+	case by != nil && (item.SupersededByID == nil || *item.SupersededByID != by.ID):
+		t.Errorf("条目[%s v%d]应被条目[ID:%d]取代，实际: %v", item.RawTitle, item.Version, by.ID, item.SupersededByID)
+	}
+}
+
+// TestSupersedeNewVersion 测试v2取代同一发布的v1，字幕组、分辨率或字幕不同的条目不受影响
+func TestSupersedeNewVersion(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	v1 := frierenRelease(1, "LoliHouse", "05", "1080p", "简繁内封字幕")
+	otherResolution := frierenRelease(2, "LoliHouse", "05", "720p", "简繁内封字幕")
+	otherGroup := frierenRelease(3, "喵萌奶茶屋", "05", "1080p", "简繁内封字幕")
+	otherSub := frierenRelease(4, "LoliHouse", "05", "1080p", "简体内嵌")
+	otherEpisode := frierenRelease(5, "LoliHouse", "06", "1080p", "简繁内封字幕")
+	server.setReleases(v1, otherResolution, otherGroup, otherSub, otherEpisode)
+	ingestFeed(t, db, feed)
+
+	v2 := frierenRelease(6, "LoliHouse", "05v2", "1080p", "简繁内封字幕")
+	server.setReleases(v2)
+	ingestFeed(t, db, feed)
+
+	v2Item := requireItem(t, db, v2)
+	if v2Item.Version != 2 {
+		t.Fatalf("v2条目版本应为2，实际: %d", v2Item.Version)
+	}
+	assertSupersededBy(t, requireItem(t, db, v1), v2Item)
+	assertSupersededBy(t, v2Item, nil)
+	for _, release := range []testRelease{otherResolution, otherGroup, otherSub, otherEpisode} {
+		assertSupersededBy(t, requireItem(t, db, release), nil)
+	}
+
+	// v3入库后v1和v2都指向v3
+	v3 := frierenRelease(7, "LoliHouse", "05v3", "1080p", "简繁内封字幕")
+	server.setReleases(v3)
+	ingestFeed(t, db, feed)
+	v3Item := requireItem(t, db, v3)
+	assertSupersededBy(t, requireItem(t, db, v1), v3Item)
+	assertSupersededBy(t, requireItem(t, db, v2), v3Item)
+}
+
+// TestSupersedeOlderVersionArrivesLater 测试v2先于v1入库时，后入库的v1被标记为已取代
+func TestSupersedeOlderVersionArrivesLater(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	v2 := frierenRelease(1, "LoliHouse", "05v2", "1080p", "简繁内封字幕")
+	server.setReleases(v2)
+	ingestFeed(t, db, feed)
+
+	v1 := frierenRelease(2, "LoliHouse", "05", "1080p", "简繁内封字幕")
+	server.setReleases(v1)
+	ingestFeed(t, db, feed)
+
+	v2Item := requireItem(t, db, v2)
+	assertSupersededBy(t, requireItem(t, db, v1), v2Item)
+	assertSupersededBy(t, v2Item, nil)
+}
+
+// TestSupersedeBatchByEpisodeEnd 测试合集按起止集数区分发布，只有范围相同的旧版本被取代
+func TestSupersedeBatchByEpisodeEnd(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	batch := testRelease{title: "[LoliHouse] 葬送的芙莉莲 / Sousou no Frieren [01-12 合集][WebRip 1080p HEVC-10bit AAC][简繁内封字幕]", n: 1}
+	longerBatch := testRelease{title: "[LoliHouse] 葬送的芙莉莲 / Sousou no Frieren [01-13 合集][WebRip 1080p HEVC-10bit AAC][简繁内封字幕]", n: 2}
+	server.setReleases(batch, longerBatch)
+	ingestFeed(t, db, feed)
+
+	batchV2 := testRelease{title: "[LoliHouse] 葬送的芙莉莲 / Sousou no Frieren [01-12v2 合集][WebRip 1080p HEVC-10bit AAC][简繁内封字幕]", n: 3}
+	server.setReleases(batchV2)
+	ingestFeed(t, db, feed)
+
+	batchV2Item := requireItem(t, db, batchV2)
+	if batchV2Item.ReleaseKind != "batch" || batchV2Item.EpisodeEnd == nil || *batchV2Item.EpisodeEnd != 12 {
+		t.Fatalf("v2合集应识别为1-12集合集，实际: %s %v", batchV2Item.ReleaseKind, batchV2Item.EpisodeEnd)
+	}
+	assertSupersededBy(t, requireItem(t, db, batch), batchV2Item)
+	assertSupersededBy(t, requireItem(t, db, longerBatch), nil)
+}
+
+// TestIncludeSupersededFilter 测试番剧条目列表默认隐藏已取代的条目，include_superseded=true时返回全部
+func TestIncludeSupersededFilter(t *testing.T) {
+	db := openIngestTestDB(t)
+	server := newTestFeedServer(t)
+	feed := createTestFeed(t, db, server)
+
+	v1 := frierenRelease(1, "LoliHouse", "05", "1080p", "简繁内封字幕")
+	v2 := frierenRelease(2, "LoliHouse", "05v2", "1080p", "简繁内封字幕")
+	server.setReleases(v1, v2)
+	ingestFeed(t, db, feed)
+	v2Item := requireItem(t, db, v2)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/bangumi/items/:id", controllers.GetBangumiRSSItems)
+
+	testCases := []struct {
+		query    string
+		total    int64
+		testName string
+	}{
+		{"", 1, "默认隐藏"},
+		{"?include_superseded=false", 1, "显式隐藏"},
+		{"?include_superseded=true", 2, "包含已取代"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/bangumi/items/%d%s", v2Item.BangumiID, tc.query), nil)
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("请求失败，状态码: %d, 响应: %s", w.Code, w.Body.String())
+			}
+
+			var resp struct {
+				Data  []models.RSSItem `json:"data"`
+				Total int64            `json:"total"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+			if resp.Total != tc.total || int64(len(resp.Data)) != tc.total {
+				t.Errorf("条目数量不匹配，期望: %d, 实际: total=%d data=%d", tc.total, resp.Total, len(resp.Data))
+			}
+			if tc.total == 1 && resp.Data[0].ID != v2Item.ID {
+				t.Errorf("应只返回v2条目[ID:%d]，实际: %d", v2Item.ID, resp.Data[0].ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"backend/utils/parser"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// bencodeString 生成bencode字符串
+func bencodeString(s string) string {
+	return fmt.Sprintf("%d:%s", len(s), s)
+}
+
+// TestParseTorrentSingleFile 测试单文件种子的infohash、大小和文件列表
+func TestParseTorrentSingleFile(t *testing.T) {
+	info := "d6:lengthi734003200e4:name" + bencodeString("[ANi] Frieren - 01 [1080P].mp4") + "12:piece lengthi262144e6:pieces20:aaaaaaaaaaaaaaaaaaaae"
+	data := []byte("d8:announce30:http://tracker.example.com/ann4:info" + info + "e")
+
+	meta, err := parser.ParseTorrent(data)
+	if err != nil {
+		t.Fatalf("解析种子失败: %v", err)
+	}
+
+	sum := sha1.Sum([]byte(info))
+	if expected := hex.EncodeToString(sum[:]); meta.InfoHash != expected {
+		t.Errorf("infohash错误: 期望 %s, 实际为 %s", expected, meta.InfoHash)
+	}
+	if meta.Size != 734003200 {
+		t.Errorf("总大小错误: 期望 734003200, 实际为 %d", meta.Size)
+	}
+	if len(meta.Files) != 1 || meta.Files[0].Path != "[ANi] Frieren - 01 [1080P].mp4" {
+		t.Errorf("文件列表错误: %+v", meta.Files)
+	}
+}
+
+// TestParseTorrentMultiFile 测试多文件种子，优先使用path.utf-8
+func TestParseTorrentMultiFile(t *testing.T) {
+	info := "d5:filesld6:lengthi100e4:pathl2:SP6:01.mkveed6:lengthi200e4:pathl6:02.mkve10:path.utf-8l" + bencodeString("第02话.mkv") + "eee" +
+		"4:name5:Batch12:piece lengthi262144e6:pieces20:bbbbbbbbbbbbbbbbbbbbe"
+	data := []byte("d4:info" + info + "e")
+
+	meta, err := parser.ParseTorrent(data)
+	if err != nil {
+		t.Fatalf("解析种子失败: %v", err)
+	}
+
+	sum := sha1.Sum([]byte(info))
+	if expected := hex.EncodeToString(sum[:]); meta.InfoHash != expected {
+		t.Errorf("infohash错误: 期望 %s, 实际为 %s", expected, meta.InfoHash)
+	}
+	if meta.Name != "Batch" || meta.Size != 300 {
+		t.Errorf("名称或总大小错误: %s %d", meta.Name, meta.Size)
+	}
+	if len(meta.Files) != 2 || meta.Files[0].Path != "SP/01.mkv" || meta.Files[1].Path != "第02话.mkv" {
+		t.Errorf("文件列表错误: %+v", meta.Files)
+	}
+}
+
+// TestParseTorrentInvalid 测试格式错误的种子
+func TestParseTorrentInvalid(t *testing.T) {
+	testCases := []string{
+		"",
+		"d4:info",
+		"d4:infod4:name1:aee",
+		"li1ee",
+		"d4:infod6:lengthi1e4:name1:aeextra",
+		"d4:infod6:lengthi1e4:name99:aee",
+	}
+
+	for _, tc := range testCases {
+		if _, err := parser.ParseTorrent([]byte(tc)); err == nil {
+			t.Errorf("期望 %q 解析失败", tc)
+		}
+	}
+}
+
+// TestParseMagnet 测试磁力链接的十六进制和Base32 infohash
+func TestParseMagnet(t *testing.T) {
+	testCases := []struct {
+		uri      string
+		hash     string
+		name     string
+		size     int64
+		hasError bool
+	}{
+		{
+			uri:  "magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A&dn=Frieren+01&xl=1024",
+			hash: "c12fe1c06bba254a9dc9f519b335aa7c1367a88a",
+			name: "Frieren 01",
+			size: 1024,
+		},
+		{
+			uri:  "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK",
+			hash: "c12fe1c06bba254a9dc9f519b335aa7c1367a88a",
+		},
+		{uri: "magnet:?dn=nohash", hasError: true},
+		{uri: "magnet:?xt=urn:btih:1234", hasError: true},
+		{uri: "https://example.com/a.torrent", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		meta, err := parser.ParseMagnet(tc.uri)
+		if tc.hasError {
+			if err == nil {
+				t.Errorf("期望 %s 解析失败", tc.uri)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("解析 %s 失败: %v", tc.uri, err)
+			continue
+		}
+		if meta.InfoHash != tc.hash || meta.Name != tc.name || meta.Size != tc.size {
+			t.Errorf("解析 %s 结果错误: %+v", tc.uri, meta)
+		}
+	}
+}
+
+// TestInfoHashFromURL 测试从种子URL中提取infohash
+func TestInfoHashFromURL(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://mikanani.me/Download/20240112/C12FE1C06BBA254A9DC9F519B335AA7C1367A88A.torrent", "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"},
+		{"https://mikanime.tv/Download/20240112/c12fe1c06bba254a9dc9f519b335aa7c1367a88a.torrent", "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"},
+		{"https://example.com/download/12345.torrent", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := parser.InfoHashFromURL(tc.url); got != tc.expected {
+			t.Errorf("InfoHashFromURL(%s) = %s, 期望 %s", tc.url, got, tc.expected)
+		}
+	}
+}
+
+// TestBuildMagnet 测试根据种子元数据生成磁力链接
+func TestBuildMagnet(t *testing.T) {
+	info := "d6:lengthi1024e4:name" + bencodeString("[ANi] Frieren - 01.mp4") + "12:piece lengthi262144e6:pieces20:cccccccccccccccccccce"
+	data := []byte("d8:announce" + bencodeString("http://a.example.com/announce") +
+		"13:announce-listll" + bencodeString("http://a.example.com/announce") + "el" + bencodeString("udp://b.example.com:80") + "ee" +
+		"4:info" + info + "e")
+
+	meta, err := parser.ParseTorrent(data)
+	if err != nil {
+		t.Fatalf("解析种子失败: %v", err)
+	}
+	if len(meta.Trackers) != 2 {
+		t.Fatalf("Tracker应去重后为2个，实际为 %v", meta.Trackers)
+	}
+
+	magnet := parser.BuildMagnet(meta.InfoHash, meta.Name, meta.Size, meta.Trackers)
+	parsed, err := parser.ParseMagnet(magnet)
+	if err != nil {
+		t.Fatalf("解析生成的磁力链接失败: %v", err)
+	}
+	if parsed.InfoHash != meta.InfoHash || parsed.Name != meta.Name || parsed.Size != meta.Size {
+		t.Errorf("磁力链接信息不一致: %+v", parsed)
+	}
+	if len(parsed.Trackers) != 2 || parsed.Trackers[1] != "udp://b.example.com:80" {
+		t.Errorf("磁力链接Tracker错误: %v", parsed.Trackers)
+	}
+
+	if got := parser.BuildMagnet("C12FE1C06BBA254A9DC9F519B335AA7C1367A88A", "", 0, nil); got != "magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a" {
+		t.Errorf("只有infohash时生成的磁力链接错误: %s", got)
+	}
+}
+
+// TestFetchTorrentMetaCancel 测试取消后中止种子下载
+func TestFetchTorrentMetaCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := parser.FetchTorrentMetaContext(ctx, server.URL+"/a.torrent")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("取消后应返回context.Canceled，实际: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("取消后应立即返回，实际耗时 %s", elapsed)
+	}
+}
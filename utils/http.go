@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,8 +10,7 @@ import (
 
 // FetchURLContent 获取指定URL的内容
 func FetchURLContent(url string) (string, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := OutboundGet(url)
 	if err != nil {
 		return "", err
 	}
@@ -26,36 +26,117 @@ func FetchURLContent(url string) (string, error) {
 // FetchURLContentWithRetry fetches content from a URL with retry logic.
 // It attempts to fetch the content up to maxRetries times with a delay between attempts.
 func FetchURLContentWithRetry(url string, maxRetries int, delay time.Duration) ([]byte, error) {
+	return FetchURLContentWithRetryContext(context.Background(), url, maxRetries, delay)
+}
+
+// FetchURLContentWithRetryContext 同FetchURLContentWithRetry，ctx取消时中止请求和重试等待
+func FetchURLContentWithRetryContext(ctx context.Context, url string, maxRetries int, delay time.Duration) ([]byte, error) {
 	var lastErr error
 
 	for i := 0; i < maxRetries; i++ {
-		resp, err := http.Get(url)
+		result, err := fetchURLConditional(ctx, url, "", "")
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			lastErr = fmt.Errorf("attempt %d failed: %w", i+1, err)
 			LogWarning(fmt.Sprintf("Failed to fetch URL %s (attempt %d/%d): %v", url, i+1, maxRetries, err), nil)
-			time.Sleep(delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
 			continue
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("attempt %d failed with status code %d", i+1, resp.StatusCode)
-			LogWarning(fmt.Sprintf("Failed to fetch URL %s (attempt %d/%d) with status code: %d", url, i+1, maxRetries, resp.StatusCode), nil)
-			time.Sleep(delay)
-			continue
+		LogInfo(fmt.Sprintf("Successfully fetched URL %s after %d attempts", url, i+1))
+		return result.Body, nil
+	}
+
+	return nil, fmt.Errorf("failed to fetch URL %s after %d attempts: %w", url, maxRetries, lastErr)
+}
+
+// ConditionalFetchResult 条件请求的结果
+type ConditionalFetchResult struct {
+	Body         []byte // 响应内容，NotModified为true时为空
+	ETag         string // 响应头ETag
+	LastModified string // 响应头Last-Modified
+	NotModified  bool   // 服务器返回304，内容未变化
+}
+
+// FetchURLConditionalWithRetry 携带If-None-Match/If-Modified-Since发起请求，失败时重试
+// etag和lastModified为空时等同于普通请求
+func FetchURLConditionalWithRetry(url string, etag string, lastModified string, maxRetries int, delay time.Duration) (*ConditionalFetchResult, error) {
+	return FetchURLConditionalWithRetryContext(context.Background(), url, etag, lastModified, maxRetries, delay)
+}
+
+// FetchURLConditionalWithRetryContext 同FetchURLConditionalWithRetry，ctx取消时中止请求和重试等待
+func FetchURLConditionalWithRetryContext(ctx context.Context, url string, etag string, lastModified string, maxRetries int, delay time.Duration) (*ConditionalFetchResult, error) {
+	var lastErr error
+
+	for i := 0; i < maxRetries; i++ {
+		result, err := fetchURLConditional(ctx, url, etag, lastModified)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = fmt.Errorf("attempt %d failed: %w", i+1, err)
+		LogWarning(fmt.Sprintf("Failed to fetch URL %s (attempt %d/%d): %v", url, i+1, maxRetries, err), nil)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
+	}
+
+	return nil, fmt.Errorf("failed to fetch URL %s after %d attempts: %w", url, maxRetries, lastErr)
+}
+
+// fetchURLConditional 发起单次条件请求
+func fetchURLConditional(ctx context.Context, url string, etag string, lastModified string) (*ConditionalFetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := Outbound().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ConditionalFetchResult{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
 
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		// 304响应可能不携带验证头，沿用请求时的值
+		if result.ETag == "" {
+			result.ETag = etag
+		}
+		if result.LastModified == "" {
+			result.LastModified = lastModified
+		}
+		result.NotModified = true
+		return result, nil
+	case http.StatusOK:
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			lastErr = fmt.Errorf("attempt %d failed to read body: %w", i+1, err)
-			LogError(fmt.Sprintf("Failed to read response body for URL %s (attempt %d/%d): %v", url, i+1, maxRetries, err), err)
-			time.Sleep(delay)
-			continue
+			return nil, fmt.Errorf("read body: %w", err)
 		}
-
-		LogInfo(fmt.Sprintf("Successfully fetched URL %s after %d attempts", url, i+1))
-		return body, nil
+		result.Body = body
+		return result, nil
+	default:
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
 	}
-
-	return nil, fmt.Errorf("failed to fetch URL %s after %d attempts: %w", url, maxRetries, lastErr)
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -151,6 +152,15 @@ func BroadcastLog(message string) {
 	}
 }
 
+// BroadcastEvent 向所有连接的WebSocket客户端广播JSON事件，格式与认证消息一致：{"type": ..., "data": ...}
+func BroadcastEvent(eventType string, data interface{}) {
+	message, err := json.Marshal(map[string]interface{}{"type": eventType, "data": data})
+	if err != nil {
+		return
+	}
+	BroadcastLog(string(message))
+}
+
 // AddClient 添加新的WebSocket客户端
 func AddClient(conn *websocket.Conn) {
 	clientsMux.Lock()
@@ -164,3 +174,34 @@ func RemoveClient(conn *websocket.Conn) {
 	delete(clients, conn)
 	clientsMux.Unlock()
 }
+
+// CloseClients 向所有WebSocket客户端发送关闭帧并断开连接，用于服务关闭
+func CloseClients() {
+	clientsMux.Lock()
+	defer clientsMux.Unlock()
+
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "服务正在关闭")
+	for client := range clients {
+		client.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+		client.Close()
+		delete(clients, client)
+	}
+}
+
+// CloseLogger 将日志写入磁盘并关闭日志文件，之后的日志只输出到控制台
+func CloseLogger() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if logFile == nil {
+		return nil
+	}
+	syncErr := logFile.Sync()
+	closeErr := logFile.Close()
+	logFile = nil
+	logger = log.New(os.Stdout, "", 0)
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
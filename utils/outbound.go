@@ -0,0 +1,275 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 出站请求默认配置，可通过环境变量覆盖
+const (
+	defaultOutboundUserAgent     = "Mozilla/5.0 (compatible; AnimeBackend/1.2)"
+	defaultOutboundTimeout       = 30 * time.Second
+	defaultOutboundRatePerSecond = 1.0
+	defaultOutboundBurst         = 3
+	defaultOutboundMaxConcurrent = 2
+	maxRetryAfter                = 10 * time.Minute // Retry-After的最长等待时间
+)
+
+// OutboundConfig 出站HTTP客户端配置
+type OutboundConfig struct {
+	UserAgent            string        // 请求头User-Agent
+	Timeout              time.Duration // 单次请求超时时间
+	RatePerSecond        float64       // 每个主机每秒允许的请求数（令牌桶填充速率）
+	Burst                int           // 每个主机允许的突发请求数（令牌桶容量）
+	MaxConcurrentPerHost int           // 每个主机的最大并发请求数
+}
+
+// LoadOutboundConfig 从环境变量读取出站客户端配置
+// OUTBOUND_USER_AGENT、OUTBOUND_TIMEOUT_SECONDS、OUTBOUND_RATE_PER_SECOND、OUTBOUND_BURST、OUTBOUND_MAX_CONCURRENT_PER_HOST
+func LoadOutboundConfig() OutboundConfig {
+	cfg := OutboundConfig{
+		UserAgent:            defaultOutboundUserAgent,
+		Timeout:              defaultOutboundTimeout,
+		RatePerSecond:        defaultOutboundRatePerSecond,
+		Burst:                defaultOutboundBurst,
+		MaxConcurrentPerHost: defaultOutboundMaxConcurrent,
+	}
+	if v := os.Getenv("OUTBOUND_USER_AGENT"); v != "" {
+		cfg.UserAgent = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OUTBOUND_TIMEOUT_SECONDS")); err == nil && v > 0 {
+		cfg.Timeout = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("OUTBOUND_RATE_PER_SECOND"), 64); err == nil && v > 0 {
+		cfg.RatePerSecond = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OUTBOUND_BURST")); err == nil && v > 0 {
+		cfg.Burst = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OUTBOUND_MAX_CONCURRENT_PER_HOST")); err == nil && v > 0 {
+		cfg.MaxConcurrentPerHost = v
+	}
+	return cfg
+}
+
+// OutboundClient 共享的出站HTTP客户端，按主机限速、限制并发并遵守Retry-After
+type OutboundClient struct {
+	cfg    OutboundConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+// hostLimiter 单个主机的令牌桶和并发信号量
+type hostLimiter struct {
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time // 收到429/503 Retry-After后暂停请求的截止时间
+	sem          chan struct{}
+}
+
+// NewOutboundClient 创建出站HTTP客户端
+func NewOutboundClient(cfg OutboundConfig) *OutboundClient {
+	if cfg.RatePerSecond <= 0 {
+		cfg.RatePerSecond = defaultOutboundRatePerSecond
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.MaxConcurrentPerHost <= 0 {
+		cfg.MaxConcurrentPerHost = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultOutboundTimeout
+	}
+	return &OutboundClient{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		hosts:  make(map[string]*hostLimiter),
+	}
+}
+
+var (
+	outboundClient     *OutboundClient
+	outboundClientOnce sync.Once
+)
+
+// Outbound 返回全局共享的出站客户端，首次调用时根据环境变量初始化
+func Outbound() *OutboundClient {
+	outboundClientOnce.Do(func() {
+		outboundClient = NewOutboundClient(LoadOutboundConfig())
+	})
+	return outboundClient
+}
+
+// OutboundGet 使用全局出站客户端发起GET请求
+func OutboundGet(url string) (*http.Response, error) {
+	return Outbound().Get(url)
+}
+
+// OutboundGetContext 使用全局出站客户端发起GET请求，ctx取消时中止排队等待和请求
+func OutboundGetContext(ctx context.Context, url string) (*http.Response, error) {
+	return Outbound().GetContext(ctx, url)
+}
+
+// Get 发起GET请求
+func (c *OutboundClient) Get(url string) (*http.Response, error) {
+	return c.GetContext(context.Background(), url)
+}
+
+// GetContext 发起可取消的GET请求
+func (c *OutboundClient) GetContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do 在主机限速和并发限制下发送请求
+// 并发名额在响应体关闭时释放，调用方必须关闭resp.Body
+func (c *OutboundClient) Do(req *http.Request) (*http.Response, error) {
+	limiter := c.limiter(req.URL.Host)
+
+	select {
+	case limiter.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := func() { <-limiter.sem }
+
+	if err := limiter.wait(req, c.cfg.RatePerSecond, c.cfg.Burst); err != nil {
+		release()
+		return nil, err
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		delay := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if delay <= 0 && resp.StatusCode == http.StatusTooManyRequests {
+			delay = time.Duration(float64(time.Second) / c.cfg.RatePerSecond * float64(c.cfg.Burst))
+		}
+		if delay > 0 {
+			limiter.block(delay)
+			LogWarning(fmt.Sprintf("主机 %s 返回状态码 %d，暂停请求 %s", req.URL.Host, resp.StatusCode, delay), nil)
+		}
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// limiter 获取或创建主机限速器
+func (c *OutboundClient) limiter(host string) *hostLimiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limiter, ok := c.hosts[host]
+	if !ok {
+		limiter = &hostLimiter{
+			tokens: float64(c.cfg.Burst),
+			last:   time.Now(),
+			sem:    make(chan struct{}, c.cfg.MaxConcurrentPerHost),
+		}
+		c.hosts[host] = limiter
+	}
+	return limiter
+}
+
+// wait 等待令牌桶中有可用令牌，同时遵守Retry-After暂停期
+func (h *hostLimiter) wait(req *http.Request, rate float64, burst int) error {
+	for {
+		h.mu.Lock()
+		now := time.Now()
+
+		var delay time.Duration
+		if now.Before(h.blockedUntil) {
+			delay = h.blockedUntil.Sub(now)
+		} else {
+			h.tokens += now.Sub(h.last).Seconds() * rate
+			if h.tokens > float64(burst) {
+				h.tokens = float64(burst)
+			}
+			h.last = now
+			if h.tokens >= 1 {
+				h.tokens--
+				h.mu.Unlock()
+				return nil
+			}
+			delay = time.Duration((1 - h.tokens) / rate * float64(time.Second))
+		}
+		h.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return req.Context().Err()
+		}
+	}
+}
+
+// block 在指定时长内暂停对该主机的请求
+func (h *hostLimiter) block(delay time.Duration) {
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	until := time.Now().Add(delay)
+	if until.After(h.blockedUntil) {
+		h.blockedUntil = until
+	}
+	// 暂停结束后从空桶开始，避免恢复时集中突发请求
+	h.tokens = 0
+	h.last = until
+}
+
+// parseRetryAfter 解析Retry-After响应头，支持秒数和HTTP日期两种格式
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// releasingBody 关闭响应体时释放主机并发名额
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Atom 1.0 结构定义
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Links     []atomLink `xml:"link"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+// parseAtom 解析Atom 1.0格式
+func parseAtom(content string) (*Feed, error) {
+	var atom atomFeed
+	if err := xml.Unmarshal([]byte(content), &atom); err != nil {
+		return nil, fmt.Errorf("XML解析失败: %v", err)
+	}
+
+	feed := &Feed{
+		Format: FeedFormatAtom,
+		Title:  strings.TrimSpace(atom.Title),
+	}
+	if link := atomAlternate(atom.Links); link != nil {
+		feed.Link = strings.TrimSpace(link.Href)
+	}
+
+	for _, entry := range atom.Entries {
+		item := FeedItem{
+			Title:   strings.TrimSpace(entry.Title),
+			GUID:    strings.TrimSpace(entry.ID),
+			PubDate: ParseFeedDate(entry.Published),
+		}
+		if item.PubDate.IsZero() {
+			item.PubDate = ParseFeedDate(entry.Updated)
+		}
+		if link := atomAlternate(entry.Links); link != nil {
+			item.Link = strings.TrimSpace(link.Href)
+		}
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" {
+				item.EnclosureURL = strings.TrimSpace(link.Href)
+				item.EnclosureType = strings.TrimSpace(link.Type)
+				item.ContentLength = link.Length
+				break
+			}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// atomAlternate 返回rel为alternate（或未指定rel）的链接
+func atomAlternate(links []atomLink) *atomLink {
+	for i := range links {
+		if links[i].Rel == "" || links[i].Rel == "alternate" {
+			return &links[i]
+		}
+	}
+	return nil
+}
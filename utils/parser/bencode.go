@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// maxBencodeDepth 列表和字典的最大嵌套层数，防止恶意数据导致栈溢出
+const maxBencodeDepth = 64
+
+var errBencodeEOF = errors.New("bencode: 数据意外结束")
+
+// bencodeDecoder 解码bencode数据，字符串解码为string，整数为int64，
+// 列表为[]interface{}，字典为map[string]interface{}
+type bencodeDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+
+	// 顶层字典中info字段的原始字节范围，用于计算infohash
+	infoStart, infoEnd int
+}
+
+// decodeBencode 解码完整的bencode数据，返回解码结果和info字段的原始字节
+func decodeBencode(data []byte) (interface{}, []byte, error) {
+	d := &bencodeDecoder{data: data, infoStart: -1}
+	value, err := d.decode()
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, nil, fmt.Errorf("bencode: 位置%d之后存在多余数据", d.pos)
+	}
+
+	var info []byte
+	if d.infoStart >= 0 {
+		info = d.data[d.infoStart:d.infoEnd]
+	}
+	return value, info, nil
+}
+
+func (d *bencodeDecoder) decode() (interface{}, error) {
+	if d.pos >= len(d.data) {
+		return nil, errBencodeEOF
+	}
+
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		return d.decodeInt()
+	case c == 'l':
+		return d.decodeList()
+	case c == 'd':
+		return d.decodeDict()
+	case c >= '0' && c <= '9':
+		return d.decodeString()
+	default:
+		return nil, fmt.Errorf("bencode: 位置%d存在无效字符 %q", d.pos, c)
+	}
+}
+
+func (d *bencodeDecoder) decodeInt() (int64, error) {
+	end := d.indexFrom('e', d.pos+1)
+	if end < 0 {
+		return 0, errBencodeEOF
+	}
+	value, err := strconv.ParseInt(string(d.data[d.pos+1:end]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bencode: 位置%d的整数无效: %w", d.pos, err)
+	}
+	d.pos = end + 1
+	return value, nil
+}
+
+func (d *bencodeDecoder) decodeString() (string, error) {
+	colon := d.indexFrom(':', d.pos)
+	if colon < 0 {
+		return "", errBencodeEOF
+	}
+	length, err := strconv.Atoi(string(d.data[d.pos:colon]))
+	if err != nil || length < 0 {
+		return "", fmt.Errorf("bencode: 位置%d的字符串长度无效", d.pos)
+	}
+	start := colon + 1
+	if length > len(d.data)-start {
+		return "", errBencodeEOF
+	}
+	d.pos = start + length
+	return string(d.data[start:d.pos]), nil
+}
+
+func (d *bencodeDecoder) decodeList() ([]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	d.pos++
+	list := []interface{}{}
+	for {
+		if d.pos >= len(d.data) {
+			return nil, errBencodeEOF
+		}
+		if d.data[d.pos] == 'e' {
+			d.pos++
+			return list, nil
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+}
+
+func (d *bencodeDecoder) decodeDict() (map[string]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	topLevel := d.depth == 1
+	d.pos++
+	dict := make(map[string]interface{})
+	for {
+		if d.pos >= len(d.data) {
+			return nil, errBencodeEOF
+		}
+		if d.data[d.pos] == 'e' {
+			d.pos++
+			return dict, nil
+		}
+		key, err := d.decodeString()
+		if err != nil {
+			return nil, err
+		}
+		start := d.pos
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if topLevel && key == "info" {
+			d.infoStart, d.infoEnd = start, d.pos
+		}
+		dict[key] = value
+	}
+}
+
+func (d *bencodeDecoder) enter() error {
+	d.depth++
+	if d.depth > maxBencodeDepth {
+		return fmt.Errorf("bencode: 嵌套超过%d层", maxBencodeDepth)
+	}
+	return nil
+}
+
+func (d *bencodeDecoder) leave() {
+	d.depth--
+}
+
+func (d *bencodeDecoder) indexFrom(b byte, from int) int {
+	for i := from; i < len(d.data); i++ {
+		if d.data[i] == b {
+			return i
+		}
+	}
+	return -1
+}
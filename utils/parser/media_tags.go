@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 字幕形式
+const (
+	SubFormHardsub = "hardsub" // 内嵌字幕
+	SubFormSoftsub = "softsub" // 内封或外挂字幕
+)
+
+var (
+	mediaTokenSplitRE = regexp.MustCompile(`[\s\[\]()（）【】_\-+]+`)
+	bitDepthRE        = regexp.MustCompile(`^(?:HI|MA)?(\d{1,2})(?:BIT|P)$`)
+	heightRE          = regexp.MustCompile(`^(?:\d{3,4}X)?(\d{3,4})[PI]?$`)
+	versionRE         = regexp.MustCompile(`(?i)\d\s*v(\d)\b`)
+	versionTokenRE    = regexp.MustCompile(`^V(\d)$`)
+)
+
+// videoCodecTokens 视频编码标签
+var videoCodecTokens = map[string]string{
+	"HEVC": "hevc", "H265": "hevc", "H.265": "hevc", "X265": "hevc",
+	"AVC": "avc", "H264": "avc", "H.264": "avc", "X264": "avc",
+	"AV1": "av1",
+}
+
+// audioCodecPrefixes 音频编码标签，按前缀匹配以兼容"AACx2"、"FLACx3"之类的写法
+var audioCodecPrefixes = []struct {
+	prefix string
+	codec  string
+}{
+	{"EAC3", "eac3"},
+	{"AAC", "aac"},
+	{"FLAC", "flac"},
+	{"AC3", "ac3"},
+	{"DDP", "eac3"},
+	{"TRUEHD", "truehd"},
+	{"DTS", "dts"},
+	{"OPUS", "opus"},
+	{"MP3", "mp3"},
+}
+
+// standardHeights 单独出现时可视为分辨率的数字，避免把集数误判为分辨率
+var standardHeights = map[int]bool{480: true, 540: true, 576: true, 720: true, 1080: true, 1440: true, 2160: true}
+
+// mediaTags 标题中的编码、封装等技术标签
+type mediaTags struct {
+	subForm    string
+	videoCodec string
+	bitDepth   int
+	audioCodec string
+	container  string
+	height     int
+	version    int
+}
+
+// findMediaTags 从集数信息和其他标签中提取字幕形式、编码、色深、封装、分辨率高度和发布版本
+func findMediaTags(episodeInfo, other string) mediaTags {
+	tags := mediaTags{version: 1}
+
+	switch {
+	case strings.Contains(other, "内嵌") || strings.Contains(other, "硬字幕") || strings.Contains(strings.ToLower(other), "hardsub"):
+		tags.subForm = SubFormHardsub
+	case strings.Contains(other, "内封") || strings.Contains(other, "外挂") || strings.Contains(other, "软字幕") || strings.Contains(strings.ToLower(other), "softsub"):
+		tags.subForm = SubFormSoftsub
+	}
+
+	// 版本号通常紧跟在集数后面，如"[08v2]"、"- 08v2"
+	if m := versionRE.FindStringSubmatch(episodeInfo + strings.SplitN(other, " ", 2)[0]); m != nil {
+		tags.version, _ = strconv.Atoi(m[1])
+	}
+
+	for _, token := range mediaTokenSplitRE.Split(strings.ToUpper(other), -1) {
+		if token == "" {
+			continue
+		}
+
+		if codec, ok := videoCodecTokens[token]; ok {
+			if tags.videoCodec == "" {
+				tags.videoCodec = codec
+			}
+			continue
+		}
+		if token == "MKV" || token == "MP4" {
+			if tags.container == "" {
+				tags.container = strings.ToLower(token)
+			}
+			continue
+		}
+		if token == "4K" {
+			tags.height = 2160
+			continue
+		}
+		if m := versionTokenRE.FindStringSubmatch(token); m != nil {
+			tags.version, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if m := heightRE.FindStringSubmatch(token); m != nil {
+			height, _ := strconv.Atoi(m[1])
+			// 带p/i后缀或宽x高写法的直接采用，单独的数字只接受常见分辨率
+			if token != m[1] || standardHeights[height] {
+				if tags.height == 0 {
+					tags.height = height
+				}
+				continue
+			}
+		}
+		if m := bitDepthRE.FindStringSubmatch(token); m != nil {
+			depth, _ := strconv.Atoi(m[1])
+			if depth == 8 || depth == 10 || depth == 12 {
+				tags.bitDepth = depth
+			}
+			continue
+		}
+		if tags.audioCodec == "" {
+			for _, audio := range audioCodecPrefixes {
+				if strings.HasPrefix(token, audio.prefix) {
+					tags.audioCodec = audio.codec
+					break
+				}
+			}
+		}
+	}
+
+	return tags
+}
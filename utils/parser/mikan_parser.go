@@ -2,6 +2,7 @@ package parser
 
 import (
 	"backend/utils"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -41,64 +42,90 @@ func formatDate(t time.Time) (string, string, string, string) {
 	return releaseDate, releaseYear, releaseMonth, releaseDay
 }
 
-// GetMikanBasicInfo 获取Mikan页面的基本信息（不包含海报）
-func GetMikanBasicInfo(homepage string) (string, string, string, string, string, string, string, string, string, string, error) {
-	// 解析URL获取主机名
-	parsedURL, err := url.Parse(homepage)
-	if err != nil {
-		utils.LogError("解析URL失败", err)
-		return "", "", "", "", "", "", "", "", "", "", err
-	}
-	rootPath := parsedURL.Host
+// MikanEpisodeInfo Mikan条目页面中的信息
+type MikanEpisodeInfo struct {
+	OfficialTitle string // 番剧官方名称（已移除"第X季"）
+	SubGroup      string // 字幕组
+	OriginalTitle string // 原始发布标题
+	ReleaseDate   string // 发布日期
+	ReleaseYear   string // 发布年
+	ReleaseMonth  string // 发布月
+	ReleaseDay    string // 发布日
+	TorrentLink   string // 种子链接
+	MagnetLink    string // 磁力链接
+	BangumiURL    string // 番剧主页
+	PosterURL     string // 海报路径
+}
 
+// fetchMikanDocument 请求Mikan页面并解析为HTML文档
+func fetchMikanDocument(ctx context.Context, homepage string) (*goquery.Document, error) {
 	// 发送HTTP请求获取页面内容
-	resp, err := http.Get(homepage)
+	resp, err := utils.OutboundGetContext(ctx, homepage)
 	if err != nil {
 		utils.LogError("请求页面失败", err)
-		return "", "", "", "", "", "", "", "", "", "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		err := errors.New(fmt.Sprintf("请求失败，状态码：%d", resp.StatusCode))
 		utils.LogError("请求页面失败", err)
-		return "", "", "", "", "", "", "", "", "", "", err
+		return nil, err
 	}
 
 	// 使用goquery解析HTML
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
 		utils.LogError("解析HTML失败", err)
-		return "", "", "", "", "", "", "", "", "", "", err
+		return nil, err
+	}
+	return doc, nil
+}
+
+// GetMikanEpisodeInfo 通过一次请求获取Mikan条目页面的全部信息（包含海报）
+func GetMikanEpisodeInfo(homepage string) (*MikanEpisodeInfo, error) {
+	return GetMikanEpisodeInfoContext(context.Background(), homepage)
+}
+
+// GetMikanEpisodeInfoContext 与GetMikanEpisodeInfo相同，ctx取消时中止请求
+func GetMikanEpisodeInfoContext(ctx context.Context, homepage string) (*MikanEpisodeInfo, error) {
+	// 解析URL获取主机名
+	parsedURL, err := url.Parse(homepage)
+	if err != nil {
+		utils.LogError("解析URL失败", err)
+		return nil, err
 	}
+	rootPath := parsedURL.Host
 
-	// 提取官方标题
-	officialTitle := ""
+	doc, err := fetchMikanDocument(ctx, homepage)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &MikanEpisodeInfo{}
+
+	// 提取官方标题和番剧主页
 	doc.Find("p.bangumi-title a[href^='/Home/Bangumi/']").Each(func(i int, s *goquery.Selection) {
-		officialTitle = strings.TrimSpace(s.Text())
+		info.OfficialTitle = strings.TrimSpace(s.Text())
+		if href, exists := s.Attr("href"); exists {
+			info.BangumiURL = fmt.Sprintf("https://%s%s", rootPath, href)
+		}
 	})
 
-	// 使用正则表达式移除"第X季"的文本
-	re := regexp.MustCompile(`第.*季`)
-	officialTitle = strings.TrimSpace(re.ReplaceAllString(officialTitle, ""))
+	// 移除"第X季"的文本
+	info.OfficialTitle = TrimMikanSeason(info.OfficialTitle)
 
 	// 提取字幕组信息
-	subGroup := ""
 	doc.Find("p.bangumi-info a.magnet-link-wrap[href^='/Home/PublishGroup/']").Each(func(i int, s *goquery.Selection) {
-		subGroup = strings.TrimSpace(s.Text())
+		info.SubGroup = strings.TrimSpace(s.Text())
 	})
 
 	// 提取原始标题
-	originalTitle := ""
 	doc.Find("div.central-container div.episode-header p.episode-title").Each(func(i int, s *goquery.Selection) {
-		originalTitle = strings.TrimSpace(s.Text())
+		info.OriginalTitle = strings.TrimSpace(s.Text())
 	})
 
 	// 提取发布时间
-	releaseDate := ""
-	releaseYear := ""
-	releaseMonth := ""
-	releaseDay := ""
 	doc.Find("p.bangumi-info").Each(func(i int, s *goquery.Selection) {
 		text := strings.TrimSpace(s.Text())
 		if strings.Contains(text, "发布日期：") {
@@ -108,20 +135,20 @@ func GetMikanBasicInfo(homepage string) (string, string, string, string, string,
 
 			// 处理相对日期（昨天、今天等）
 			if strings.Contains(dateText, "天") {
-				releaseDate, releaseYear, releaseMonth, releaseDay = parseRelativeDate(dateText)
-				utils.LogInfo(fmt.Sprintf("解析相对日期: %s -> %s/%s/%s", dateText, releaseYear, releaseMonth, releaseDay))
+				info.ReleaseDate, info.ReleaseYear, info.ReleaseMonth, info.ReleaseDay = parseRelativeDate(dateText)
+				utils.LogInfo(fmt.Sprintf("解析相对日期: %s -> %s/%s/%s", dateText, info.ReleaseYear, info.ReleaseMonth, info.ReleaseDay))
 				return
 			}
 
 			// 处理标准日期格式
 			parts := strings.Split(dateText, "/")
 			if len(parts) >= 3 {
-				releaseDate = dateText
-				releaseYear = parts[0]
-				releaseMonth = parts[1]
+				info.ReleaseDate = dateText
+				info.ReleaseYear = parts[0]
+				info.ReleaseMonth = parts[1]
 				dayTime := strings.Split(parts[2], " ")
-				releaseDay = dayTime[0]
-				utils.LogInfo(fmt.Sprintf("解析标准日期: %s -> %s/%s/%s", dateText, releaseYear, releaseMonth, releaseDay))
+				info.ReleaseDay = dayTime[0]
+				utils.LogInfo(fmt.Sprintf("解析标准日期: %s -> %s/%s/%s", dateText, info.ReleaseYear, info.ReleaseMonth, info.ReleaseDay))
 				return
 			}
 
@@ -130,60 +157,90 @@ func GetMikanBasicInfo(homepage string) (string, string, string, string, string,
 	})
 
 	// 提取种子链接
-	torrentLink := ""
 	doc.Find("div.leftbar-nav a.episode-btn[href$='.torrent']").Each(func(i int, s *goquery.Selection) {
 		href, exists := s.Attr("href")
 		if exists {
-			torrentLink = fmt.Sprintf("https://%s%s", rootPath, href)
+			info.TorrentLink = fmt.Sprintf("https://%s%s", rootPath, href)
 		}
 	})
 
 	// 提取磁力链接
-	magnetLink := ""
 	doc.Find("div.leftbar-nav a.episode-btn[href^='magnet:']").Each(func(i int, s *goquery.Selection) {
 		href, exists := s.Attr("href")
 		if exists {
-			magnetLink = href
+			info.MagnetLink = href
 		}
 	})
 
+	info.PosterURL = extractMikanPoster(doc)
+
+	return info, nil
+}
+
+// GetMikanBasicInfo 获取Mikan页面的基本信息（不包含海报）
+func GetMikanBasicInfo(homepage string) (string, string, string, string, string, string, string, string, string, string, error) {
+	info, err := GetMikanEpisodeInfo(homepage)
+	if err != nil {
+		return "", "", "", "", "", "", "", "", "", "", err
+	}
+
 	// 返回值顺序应该是：
 	// officialTitle, subGroup, originalTitle, releaseDate, releaseYear, releaseMonth, releaseDay, torrentLink, magnetLink, nil
-	return officialTitle, subGroup, originalTitle, releaseDate, releaseYear, releaseMonth, releaseDay, torrentLink, magnetLink, "", nil
+	return info.OfficialTitle, info.SubGroup, info.OriginalTitle, info.ReleaseDate, info.ReleaseYear, info.ReleaseMonth, info.ReleaseDay, info.TorrentLink, info.MagnetLink, "", nil
+}
+
+// MikanParser 解析Mikan条目页面，返回海报路径和基本信息
+func MikanParser(homepage string) (string, string, string, string, string, string, string, string, string, string, error) {
+	info, err := GetMikanEpisodeInfo(homepage)
+	if err != nil {
+		return "", "", "", "", "", "", "", "", "", "", err
+	}
+	return info.PosterURL, info.OfficialTitle, info.SubGroup, info.OriginalTitle, info.ReleaseDate, info.ReleaseYear, info.ReleaseMonth, info.ReleaseDay, info.TorrentLink, info.MagnetLink, nil
 }
 
 // GetMikanPosterURL 获取Mikan页面的海报URL
 func GetMikanPosterURL(homepage string) (string, error) {
+	return GetMikanPosterURLContext(context.Background(), homepage)
+}
+
+// GetMikanPosterURLContext 与GetMikanPosterURL相同，ctx取消时中止请求
+func GetMikanPosterURLContext(ctx context.Context, homepage string) (string, error) {
 	// 获取海报URL
-	resp, err := http.Get(homepage)
+	doc, err := fetchMikanDocument(ctx, homepage)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return "", err
-	}
+	// 返回海报的路径
+	return extractMikanPoster(doc), nil
+}
 
+// extractMikanPoster 从条目页面或番剧页面中提取海报路径
+func extractMikanPoster(doc *goquery.Document) string {
 	// 提取海报链接
-	var posterPath string
 	posterDiv := doc.Find("div.bangumi-poster").AttrOr("style", "")
 	if posterDiv == "" {
-		return "", nil
+		return ""
 	}
 
-	re := regexp.MustCompile(`url\('([^']+)'\)`)
-	matches := re.FindStringSubmatch(posterDiv)
+	matches := posterRE.FindStringSubmatch(posterDiv)
 	if len(matches) <= 1 {
-		return "", nil
+		return ""
 	}
 
-	posterPath = matches[1]
+	posterPath := matches[1]
 	if strings.Contains(posterPath, "?") {
 		posterPath = strings.Split(posterPath, "?")[0]
 	}
+	return posterPath
+}
 
-	// 返回海报的路径
-	return posterPath, nil
+var (
+	seasonSuffixRE = regexp.MustCompile(`第.*季`)
+	posterRE       = regexp.MustCompile(`url\('([^']+)'\)`)
+)
+
+// TrimMikanSeason 移除Mikan番剧名中的"第X季"
+func TrimMikanSeason(title string) string {
+	return strings.TrimSpace(seasonSuffixRE.ReplaceAllString(title, ""))
 }
@@ -10,21 +10,32 @@ import (
 
 // Episode 表示一个动画剧集的信息
 type Episode struct {
-	NameEn     string // 英文名称
-	NameZh     string // 中文名称
-	NameJp     string // 日文名称
-	Season     int    // 季度数字
-	SeasonRaw  string // 原始季度信息
-	Episode    int    // 集数
-	Sub        string // 字幕信息
-	Group      string // 字幕组
-	Resolution string // 分辨率
-	Source     string // 来源
+	NameEn       string   `json:"name_en"`       // 英文名称
+	NameZh       string   `json:"name_zh"`       // 中文名称
+	NameJp       string   `json:"name_jp"`       // 日文名称
+	Season       int      `json:"season"`        // 季度数字
+	SeasonRaw    string   `json:"season_raw"`    // 原始季度信息
+	Episode      int      `json:"episode"`       // 集数，合集为起始集数
+	EpisodeStart float64  `json:"episode_start"` // 起始集数，半集为小数，如12.5
+	EpisodeEnd   float64  `json:"episode_end"`   // 结束集数，单集与起始集数相同
+	ReleaseKind  string   `json:"release_kind"`  // 发布类型（episode/batch/special/movie）
+	Sub          string   `json:"sub"`           // 字幕信息
+	SubLang      string   `json:"sub_lang"`      // 标准化的字幕语言，如"zh-Hans"、"zh-Hans+ja"
+	SubLanguages []string `json:"sub_languages"` // 字幕语言列表
+	SubForm      string   `json:"sub_form"`      // 字幕形式（hardsub/softsub）
+	Group        string   `json:"group"`         // 字幕组
+	Resolution   string   `json:"resolution"`    // 分辨率
+	Height       int      `json:"height"`        // 视频高度，如1080
+	Source       string   `json:"source"`        // 来源
+	VideoCodec   string   `json:"video_codec"`   // 视频编码（hevc/avc/av1）
+	BitDepth     int      `json:"bit_depth"`     // 色深，如10
+	AudioCodec   string   `json:"audio_codec"`   // 音频编码（aac/flac等）
+	Container    string   `json:"container"`     // 封装格式（mkv/mp4）
+	Version      int      `json:"version"`       // 发布版本，v2、v3等修正版大于1
 }
 
 // 定义正则表达式
 var (
-	episodeRE    = regexp.MustCompile(`\d+`)
 	titleRE      = regexp.MustCompile(`(.*|\[.*])( -? \d+|\[\d+]|\[\d+.?[vV]\d]|第\d+[话話集]|\[第?\d+[话話集]]|\[\d+.?END]|[Ee][Pp]?\d+)(.*)`)
 	resolutionRE = regexp.MustCompile(`1080|720|2160|4K`)
 	sourceRE     = regexp.MustCompile(`B-Global|[Bb]aha|[Bb]ilibili|AT-X|Web`)
@@ -32,10 +43,6 @@ var (
 	prefixRE     = regexp.MustCompile(`[^\w\s\p{Han}\p{Hiragana}\p{Katakana}-]`)
 )
 
-// selectionPrioritySubKeywords defines the order of preference for selecting Chinese subtitle tags.
-// Higher priority (lower index) keywords are preferred.
-var selectionPrioritySubKeywords = []string{"简体", "简日", "简", "CHS", "GB", "简日繁", "简中", "bibili", "Bilibili"}
-
 // 中文数字映射
 var chineseNumberMap = map[string]int{
 	"一": 1,
@@ -231,67 +238,37 @@ func nameProcess(name string) (string, string, string) {
 	return nameEn, nameZh, nameJp
 }
 
-// findTags 查找标签信息（字幕、分辨率、来源）
-func findTags(other string) (string, string, string) {
+// findTags 查找标签信息（字幕、字幕语言、分辨率、来源）
+func findTags(other string) (string, string, string, string) {
 	elements := strings.Split(regexp.MustCompile(`[\[\]()（）]`).ReplaceAllString(other, " "), " ")
 
 	var sub, resolution, source string
-	var bestSubMatch string
-	highestPriority := -1 // Lower index in selectionPrioritySubKeywords means higher priority
-
-	// First pass: check for prioritized Chinese subs based on selectionPrioritySubKeywords
+	var subTags []string
 	for _, element := range elements {
 		element = strings.TrimSpace(element)
 		if element == "" {
 			continue
 		}
-		for i, keyword := range selectionPrioritySubKeywords {
-			// Check if the element (which is a potential tag) contains the keyword.
-			// This allows matching for elements like "CHS字幕" with keyword "CHS".
-			if strings.Contains(element, keyword) {
-				if highestPriority == -1 || i < highestPriority {
-					bestSubMatch = keyword // Assign the keyword itself as the sub tag
-					highestPriority = i
-				}
-			}
-		}
-		// Concurrently check for resolution and source from the same element
 		if resolutionRE.MatchString(element) {
 			resolution = element
 		} else if sourceRE.MatchString(element) {
 			source = element
+		} else if subRE.MatchString(element) {
+			// 字幕信息保留第一个字幕标签，字幕语言综合所有字幕标签
+			if sub == "" {
+				sub = element
+			}
+			subTags = append(subTags, element)
 		}
 	}
 
-	// Second pass: if no prioritized Chinese sub was found, check for any other sub using the general subRE
-	if bestSubMatch == "" {
-		for _, element := range elements {
-			element = strings.TrimSpace(element)
-			if element == "" {
-				continue
-			}
-			// Check if this element is a general subtitle tag according to subRE
-			if subRE.MatchString(element) {
-				// Ensure it's not one of the selectionPrioritySubKeywords that might have been missed
-				// or to prevent complex interactions if subRE is broad.
-				isAlreadyPrioritizedCandidate := false
-				for _, prioKeyword := range selectionPrioritySubKeywords {
-					if strings.Contains(element, prioKeyword) {
-						isAlreadyPrioritizedCandidate = true
-						break
-					}
-				}
-				if !isAlreadyPrioritizedCandidate {
-					// Use the element itself if it matches subRE, as subRE is designed to match whole tags.
-					bestSubMatch = element
-					break // Found a general sub, stop this loop
-				}
-			}
-		}
+	subLang := DetectSubLanguages(subTags...)
+	// B站来源的番剧未标注字幕时默认为简体中文
+	if subLang == "" && strings.Contains(strings.ToLower(source), "bilibili") {
+		subLang = SubLangHans
 	}
 
-	sub = bestSubMatch
-	return cleanSub(sub), resolution, source
+	return cleanSub(sub), subLang, resolution, source
 }
 
 // cleanSub 清理字幕信息
@@ -302,8 +279,8 @@ func cleanSub(sub string) string {
 	return regexp.MustCompile(`_MP4|_MKV`).ReplaceAllString(sub, "")
 }
 
-// process 处理原始标题
-func process(rawTitle string) (string, string, string, int, string, int, string, string, string, string) {
+// process 处理原始标题，名称均为空时表示解析失败
+func process(rawTitle string) Episode {
 	// 预处理标题
 	rawTitle = strings.TrimSpace(rawTitle)
 	rawTitle = strings.ReplaceAll(rawTitle, "\n", " ")
@@ -312,23 +289,22 @@ func process(rawTitle string) (string, string, string, int, string, int, string,
 	// 获取字幕组名称
 	group := getGroup(contentTitle)
 
-	// 匹配标题结构
-	matchObj := titleRE.FindStringSubmatch(contentTitle)
-	if len(matchObj) < 4 {
+	// 匹配标题结构，提取季度信息、集数信息和其他信息
+	seasonInfo, episodeInfo, other, ok := splitTitle(contentTitle)
+	if !ok {
 		utils.LogError("解析标题失败", nil)
-		return "", "", "", 0, "", 0, "", "", "", group
+		return Episode{Group: group}
 	}
-
-	// 提取季度信息、集数信息和其他信息
-	seasonInfo := strings.TrimSpace(matchObj[1])
-	episodeInfo := strings.TrimSpace(matchObj[2])
-	other := strings.TrimSpace(matchObj[3])
+	seasonInfo = strings.TrimSpace(seasonInfo)
+	episodeInfo = strings.TrimSpace(episodeInfo)
+	other = strings.TrimSpace(other)
 
 	// 处理前缀
 	processRaw := prefixProcess(seasonInfo, group)
 
 	// 处理季度
 	rawName, seasonRaw, season := seasonProcess(processRaw)
+	rawName = stripReleaseMarkers(rawName)
 
 	// 处理名称
 	nameEn, nameZh, nameJp := "", "", ""
@@ -337,20 +313,37 @@ func process(rawTitle string) (string, string, string, int, string, int, string,
 	}
 	try()
 
-	// 处理集数
-	episode := 0
-	rawEpisode := episodeRE.FindString(episodeInfo)
-	if rawEpisode != "" {
-		episodeNum, err := strconv.Atoi(rawEpisode)
-		if err == nil {
-			episode = episodeNum
-		}
-	}
+	// 处理集数和发布类型
+	release := findRelease(seasonInfo, episodeInfo, other)
 
 	// 处理其他标签
-	sub, resolution, source := findTags(other)
-
-	return nameEn, nameZh, nameJp, season, seasonRaw, episode, sub, resolution, source, group
+	sub, subLang, resolution, source := findTags(other)
+	media := findMediaTags(episodeInfo, other)
+
+	return Episode{
+		NameEn:       nameEn,
+		NameZh:       nameZh,
+		NameJp:       nameJp,
+		Season:       season,
+		SeasonRaw:    seasonRaw,
+		Episode:      int(release.start),
+		EpisodeStart: release.start,
+		EpisodeEnd:   release.end,
+		ReleaseKind:  release.kind,
+		Sub:          sub,
+		SubLang:      subLang,
+		SubLanguages: SplitSubLanguages(subLang),
+		SubForm:      media.subForm,
+		Group:        group,
+		Resolution:   resolution,
+		Height:       media.height,
+		Source:       source,
+		VideoCodec:   media.videoCodec,
+		BitDepth:     media.bitDepth,
+		AudioCodec:   media.audioCodec,
+		Container:    media.container,
+		Version:      media.version,
+	}
 }
 
 // IsGroupBlacklisted 检查字幕组是否在黑名单中
@@ -370,31 +363,19 @@ func IsGroupBlacklisted(group string, blacklist string) bool {
 
 // RawParser 解析原始标题并返回Episode对象
 func RawParser(raw string, blacklist string) *Episode {
-	nameEn, nameZh, nameJp, season, seasonRaw, episode, sub, resolution, source, group := process(raw)
+	episode := process(raw)
 
 	// 如果解析失败，记录错误并返回nil
-	if nameEn == "" && nameZh == "" && nameJp == "" {
+	if episode.NameEn == "" && episode.NameZh == "" && episode.NameJp == "" {
 		utils.LogError("解析器无法解析标题", nil)
 		return nil
 	}
 
 	// 检查字幕组是否在黑名单中
-	if IsGroupBlacklisted(group, blacklist) {
-		utils.LogInfo(fmt.Sprintf("字幕组 %s 在黑名单中，跳过处理", group))
+	if IsGroupBlacklisted(episode.Group, blacklist) {
+		utils.LogInfo(fmt.Sprintf("字幕组 %s 在黑名单中，跳过处理", episode.Group))
 		return nil
 	}
 
-	// 创建并返回Episode对象
-	return &Episode{
-		NameEn:     nameEn,
-		NameZh:     nameZh,
-		NameJp:     nameJp,
-		Season:     season,
-		SeasonRaw:  seasonRaw,
-		Episode:    episode,
-		Sub:        sub,
-		Group:      group,
-		Resolution: resolution,
-		Source:     source,
-	}
+	return &episode
 }
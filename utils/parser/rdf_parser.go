@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// RSS 1.0 (RDF) 结构定义
+type rdfFeed struct {
+	XMLName xml.Name   `xml:"RDF"`
+	Channel rdfChannel `xml:"channel"`
+	Items   []rdfItem  `xml:"item"`
+}
+
+type rdfChannel struct {
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type rdfItem struct {
+	About     string       `xml:"about,attr"`
+	Title     string       `xml:"title"`
+	Link      string       `xml:"link"`
+	Date      string       `xml:"date"`
+	Enclosure rdfEnclosure `xml:"enclosure"`
+}
+
+// rdfEnclosure mod_enclosure扩展（<enc:enclosure rdf:resource="..." enc:type="..." enc:length="..."/>）
+type rdfEnclosure struct {
+	Resource string `xml:"resource,attr"`
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Length   int64  `xml:"length,attr"`
+}
+
+// parseRDF 解析RSS 1.0 (RDF)格式
+func parseRDF(content string) (*Feed, error) {
+	var rdf rdfFeed
+	if err := xml.Unmarshal([]byte(content), &rdf); err != nil {
+		return nil, fmt.Errorf("XML解析失败: %v", err)
+	}
+
+	feed := &Feed{
+		Format: FeedFormatRSS1,
+		Title:  strings.TrimSpace(rdf.Channel.Title),
+		Link:   strings.TrimSpace(rdf.Channel.Link),
+	}
+	for _, item := range rdf.Items {
+		enclosureURL := item.Enclosure.Resource
+		if enclosureURL == "" {
+			enclosureURL = item.Enclosure.URL
+		}
+		feed.Items = append(feed.Items, FeedItem{
+			Title:         strings.TrimSpace(item.Title),
+			Link:          strings.TrimSpace(item.Link),
+			GUID:          strings.TrimSpace(item.About),
+			EnclosureURL:  strings.TrimSpace(enclosureURL),
+			EnclosureType: strings.TrimSpace(item.Enclosure.Type),
+			ContentLength: item.Enclosure.Length,
+			PubDate:       ParseFeedDate(item.Date),
+		})
+	}
+	return feed, nil
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 发布类型
+const (
+	ReleaseKindEpisode = "episode" // 单集（含12.5之类的半集）
+	ReleaseKindBatch   = "batch"   // 合集，如"01-12"、"[合集]"
+	ReleaseKindSpecial = "special" // 特别篇，如SP、OVA、OAD、总集篇
+	ReleaseKindMovie   = "movie"   // 剧场版
+)
+
+var (
+	// rangeTitleRE 集数范围，如" - 01-12"、"[01-24 合集]"、"[01~12 Fin]"
+	rangeTitleRE = regexp.MustCompile(`(.*)( -? \d+(?:\.\d+)?\s*[-~～]\s*\d+(?:\.\d+)?|\[\d+(?:\.\d+)?\s*[-~～]\s*\d+(?:\.\d+)?[^\]]*])(.*)`)
+	// halfTitleRE 半集，如" - 12.5"、"[12.5]"
+	halfTitleRE = regexp.MustCompile(`(.*)( -? \d+\.\d+|\[\d+\.\d+(?:[vV]\d)?])(.*)`)
+	// markerTitleRE 没有集数时以第一个标签为界拆分标题，只用于带有合集、特别篇或剧场版标记的标题
+	markerTitleRE = regexp.MustCompile(`^(\[[^\]]*]\s*[^\[]+?)()\s*(\[.*)$`)
+
+	episodeRangeRE  = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*[-~～]\s*(\d+(?:\.\d+)?)`)
+	episodeNumberRE = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+	batchMarkerRE   = regexp.MustCompile(`(?i)合集|全集|\bcomplete\b|\bbatch\b|\[fin]`)
+	specialMarkerRE = regexp.MustCompile(`(?i)(?:^|[^a-z])(?:SP|OVA|OAD)\s*(\d*)(?:[^a-z]|$)|总集篇|總集篇|特别篇|特別篇`)
+	movieMarkerRE   = regexp.MustCompile(`(?i)剧场版|劇場版|\bthe movie\b|\bmovie\b`)
+
+	// nameMarkerRE 名称中的剧场版、特别篇和合集标记，识别发布类型后从名称中去除
+	nameMarkerRE = regexp.MustCompile(`(?i)\s*(?:剧场版|劇場版|\b(?:the )?movie\b|\b(?:SP|OVA|OAD)\s*\d*\b|合集|全集)`)
+	emptyParenRE = regexp.MustCompile(`\s*[(（]\s*[)）]`)
+)
+
+// releaseInfo 集数范围和发布类型
+type releaseInfo struct {
+	kind       string
+	start, end float64
+}
+
+// splitTitle 将标题拆分为名称部分、集数部分和其他标签，无法拆分时返回false
+func splitTitle(contentTitle string) (string, string, string, bool) {
+	// 范围和半集需要在普通集数之前匹配，否则" - 01-12"只会识别出第1集
+	for _, re := range []*regexp.Regexp{rangeTitleRE, halfTitleRE, titleRE} {
+		if m := re.FindStringSubmatch(contentTitle); len(m) >= 4 {
+			if re == rangeTitleRE && !isAscendingRange(m[2]) {
+				continue
+			}
+			return m[1], m[2], m[3], true
+		}
+	}
+
+	// 没有集数的合集、特别篇和剧场版
+	if batchMarkerRE.MatchString(contentTitle) || specialMarkerRE.MatchString(contentTitle) || movieMarkerRE.MatchString(contentTitle) {
+		if m := markerTitleRE.FindStringSubmatch(contentTitle); len(m) >= 4 {
+			return m[1], m[2], m[3], true
+		}
+	}
+	return "", "", "", false
+}
+
+// isAscendingRange 检查集数范围的结束集数是否大于起始集数，用于排除日期之类的误判
+func isAscendingRange(episodeInfo string) bool {
+	m := episodeRangeRE.FindStringSubmatch(episodeInfo)
+	if m == nil {
+		return false
+	}
+	start, _ := strconv.ParseFloat(m[1], 64)
+	end, _ := strconv.ParseFloat(m[2], 64)
+	return end > start
+}
+
+// stripReleaseMarkers 去除名称中的剧场版、特别篇和合集标记，避免不同作品的剧场版被识别为同一个名称
+func stripReleaseMarkers(name string) string {
+	name = nameMarkerRE.ReplaceAllString(name, "")
+	return emptyParenRE.ReplaceAllString(name, "")
+}
+
+// findRelease 根据集数部分和标题中的标记判断发布类型和集数范围
+func findRelease(nameInfo, episodeInfo, other string) releaseInfo {
+	release := releaseInfo{kind: ReleaseKindEpisode}
+
+	if m := episodeRangeRE.FindStringSubmatch(episodeInfo); m != nil && isAscendingRange(episodeInfo) {
+		release.start, _ = strconv.ParseFloat(m[1], 64)
+		release.end, _ = strconv.ParseFloat(m[2], 64)
+		release.kind = ReleaseKindBatch
+		return release
+	}
+	if n := episodeNumberRE.FindString(episodeInfo); n != "" {
+		release.start, _ = strconv.ParseFloat(n, 64)
+		release.end = release.start
+	}
+
+	// 集数之外的标记：剧场版和特别篇看名称部分，合集看全部标签
+	all := strings.Join([]string{nameInfo, episodeInfo, other}, " ")
+	switch {
+	case movieMarkerRE.MatchString(nameInfo) || (episodeInfo == "" && movieMarkerRE.MatchString(all)):
+		release.kind = ReleaseKindMovie
+	case specialMarkerRE.MatchString(nameInfo + " " + episodeInfo):
+		release.kind = ReleaseKindSpecial
+		if episodeInfo == "" {
+			if m := specialMarkerRE.FindStringSubmatch(nameInfo); m != nil && m[1] != "" {
+				release.start, _ = strconv.ParseFloat(m[1], 64)
+				release.end = release.start
+			}
+		}
+	case episodeInfo == "" && batchMarkerRE.MatchString(all):
+		release.kind = ReleaseKindBatch
+	case episodeInfo == "" && specialMarkerRE.MatchString(other):
+		release.kind = ReleaseKindSpecial
+	}
+	return release
+}
@@ -3,6 +3,8 @@ package parser
 import (
 	"encoding/xml"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // RSS结构定义
@@ -12,24 +14,179 @@ type RSS struct {
 }
 
 type Channel struct {
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
 	Items []Item `xml:"item"`
 }
 
 type Item struct {
-	Link string `xml:"link"`
+	Title     string    `xml:"title"`
+	Link      string    `xml:"link"`
+	GUID      string    `xml:"guid"`
+	PubDate   string    `xml:"pubDate"`
+	Date      string    `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Torrent   Torrent   `xml:"torrent"`
+	Enclosure Enclosure `xml:"enclosure"`
 }
 
-// ParseRSS 解析RSS内容并返回条目链接
-func ParseRSS(content string) ([]string, error) {
+// Torrent Mikan扩展的种子信息（<torrent xmlns="https://mikanani.me/0.1/">）
+type Torrent struct {
+	Link          string `xml:"link"`
+	ContentLength int64  `xml:"contentLength"`
+	PubDate       string `xml:"pubDate"`
+}
+
+// Enclosure 条目附件（种子或磁力链接）
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// Feed 标准化的订阅源内容
+type Feed struct {
+	Format string     // 订阅源格式，见FeedFormat常量
+	Title  string     // 订阅源标题
+	Link   string     // 订阅源主页
+	Items  []FeedItem // 订阅条目
+}
+
+// FeedItem 标准化的订阅条目
+type FeedItem struct {
+	Title         string    // 条目标题，通常为原始发布标题
+	Link          string    // 条目主页
+	GUID          string    // 条目唯一标识
+	EnclosureURL  string    // 种子或磁力链接
+	EnclosureType string    // 附件MIME类型
+	ContentLength int64     // 附件大小（字节）
+	PubDate       time.Time // 发布时间，未知时为零值
+}
+
+// feedDateLayouts 订阅源中常见的日期格式
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC3339Nano,
+	time.RFC3339,
+}
+
+// localDateLayouts 不带时区的日期格式（如Mikan的<torrent><pubDate>），按本地时区解析
+var localDateLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// ParseFeedDate 解析订阅源中的日期，无法解析时返回零值
+func ParseFeedDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	for _, layout := range localDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// 支持的订阅源格式
+const (
+	FeedFormatRSS2 = "rss2.0"  // RSS 2.0
+	FeedFormatAtom = "atom1.0" // Atom 1.0
+	FeedFormatRSS1 = "rss1.0"  // RSS 1.0 (RDF)
+)
+
+// ParseFeed 自动识别RSS 2.0、Atom 1.0和RSS 1.0 (RDF)格式，返回订阅源信息和标准化条目
+func ParseFeed(content string) (*Feed, error) {
+	root, err := detectRootElement(content)
+	if err != nil {
+		return nil, fmt.Errorf("XML解析失败: %v", err)
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS2(content)
+	case "feed":
+		return parseAtom(content)
+	case "RDF":
+		return parseRDF(content)
+	default:
+		return nil, fmt.Errorf("不支持的订阅源格式: <%s>", root)
+	}
+}
+
+// detectRootElement 返回XML文档根元素的本地名称
+func detectRootElement(content string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// parseRSS2 解析RSS 2.0格式
+func parseRSS2(content string) (*Feed, error) {
 	var rss RSS
 	err := xml.Unmarshal([]byte(content), &rss)
 	if err != nil {
 		return nil, fmt.Errorf("XML解析失败: %v", err)
 	}
 
-	var links []string
+	feed := &Feed{
+		Format: FeedFormatRSS2,
+		Title:  strings.TrimSpace(rss.Channel.Title),
+		Link:   strings.TrimSpace(rss.Channel.Link),
+	}
 	for _, item := range rss.Channel.Items {
-		links = append(links, item.Link)
+		feed.Items = append(feed.Items, item.normalize())
 	}
-	return links, nil
-}
\ No newline at end of file
+	return feed, nil
+}
+
+// ParseRSS 解析RSS内容并返回标准化条目
+func ParseRSS(content string) ([]FeedItem, error) {
+	feed, err := ParseFeed(content)
+	if err != nil {
+		return nil, err
+	}
+	return feed.Items, nil
+}
+
+// normalize 将RSS 2.0条目转换为标准化条目，Mikan扩展字段优先
+func (item Item) normalize() FeedItem {
+	normalized := FeedItem{
+		Title:         strings.TrimSpace(item.Title),
+		Link:          strings.TrimSpace(item.Link),
+		GUID:          strings.TrimSpace(item.GUID),
+		EnclosureURL:  strings.TrimSpace(item.Enclosure.URL),
+		EnclosureType: strings.TrimSpace(item.Enclosure.Type),
+		ContentLength: item.Enclosure.Length,
+		PubDate:       ParseFeedDate(item.PubDate),
+	}
+	if normalized.Link == "" {
+		normalized.Link = strings.TrimSpace(item.Torrent.Link)
+	}
+	if normalized.ContentLength == 0 {
+		normalized.ContentLength = item.Torrent.ContentLength
+	}
+	if normalized.PubDate.IsZero() {
+		normalized.PubDate = ParseFeedDate(item.Torrent.PubDate)
+	}
+	if normalized.PubDate.IsZero() {
+		normalized.PubDate = ParseFeedDate(item.Date)
+	}
+	return normalized
+}
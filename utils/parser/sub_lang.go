@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 标准化字幕语言代码，多语言字幕按固定顺序用"+"连接，如"zh-Hans+zh-Hant"、"zh-Hans+ja"
+const (
+	SubLangHans = "zh-Hans" // 简体中文
+	SubLangHant = "zh-Hant" // 繁体中文
+	SubLangZh   = "zh"      // 未注明简繁的中文
+	SubLangJa   = "ja"      // 日语
+	SubLangEn   = "en"      // 英语
+
+	// SubLangAny 字幕偏好中表示接受任何条目（包括未标注字幕的条目）
+	SubLangAny = "any"
+	// DefaultSubtitleLanguages 未配置字幕偏好时的默认值
+	DefaultSubtitleLanguages = SubLangHans
+)
+
+// subLangOrder 多语言组合中各语言的排列顺序
+var subLangOrder = map[string]int{SubLangHans: 0, SubLangHant: 1, SubLangZh: 2, SubLangJa: 3, SubLangEn: 4}
+
+// subLangTokens 字幕标签中的关键字及其对应的语言，较长的关键字优先匹配
+var subLangTokens = []struct {
+	token string
+	langs []string
+}{
+	{"JPSC", []string{SubLangJa, SubLangHans}},
+	{"JPTC", []string{SubLangJa, SubLangHant}},
+	{"BIG5", []string{SubLangHant}},
+	{"CHS", []string{SubLangHans}},
+	{"CHT", []string{SubLangHant}},
+	{"GB", []string{SubLangHans}},
+	{"SC", []string{SubLangHans}},
+	{"TC", []string{SubLangHant}},
+	{"JPN", []string{SubLangJa}},
+	{"JP", []string{SubLangJa}},
+	{"ENG", []string{SubLangEn}},
+	{"简", []string{SubLangHans}},
+	{"繁", []string{SubLangHant}},
+	{"日", []string{SubLangJa}},
+	{"英", []string{SubLangEn}},
+	{"中", []string{SubLangZh}},
+}
+
+// DetectSubLanguages 从字幕标签中识别字幕语言，返回标准化的语言组合，无法识别时返回空字符串
+func DetectSubLanguages(tags ...string) string {
+	found := make(map[string]bool)
+	for _, tag := range tags {
+		rest := strings.ToUpper(tag)
+		for _, t := range subLangTokens {
+			if strings.Contains(rest, t.token) {
+				for _, lang := range t.langs {
+					found[lang] = true
+				}
+				// 去掉已匹配的关键字，避免"CHS"中的"SC"之类被重复识别
+				rest = strings.ReplaceAll(rest, t.token, " ")
+			}
+		}
+	}
+
+	// 已注明简繁时，不再保留泛指的中文
+	if found[SubLangHans] || found[SubLangHant] {
+		delete(found, SubLangZh)
+	}
+
+	langs := make([]string, 0, len(found))
+	for lang := range found {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return subLangOrder[langs[i]] < subLangOrder[langs[j]] })
+	return strings.Join(langs, "+")
+}
+
+// SplitSubLanguages 将语言组合拆分为语言列表
+func SplitSubLanguages(subLang string) []string {
+	langs := make([]string, 0)
+	for _, lang := range strings.Split(subLang, "+") {
+		if lang != "" {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// SubtitlePreference 字幕语言偏好：条目的字幕语言包含任一可接受组合中的全部语言即可收录
+type SubtitlePreference struct {
+	any    bool
+	combos [][]string
+}
+
+// ParseSubtitlePreference 解析逗号分隔的字幕语言偏好，如"zh-Hans,zh-Hant,ja"、"zh-Hans+ja"、"any"
+func ParseSubtitlePreference(value string) (SubtitlePreference, error) {
+	var pref SubtitlePreference
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.EqualFold(entry, SubLangAny) {
+			pref.any = true
+			continue
+		}
+
+		var combo []string
+		for _, lang := range strings.Split(entry, "+") {
+			normalized, ok := normalizeSubLang(lang)
+			if !ok {
+				return SubtitlePreference{}, fmt.Errorf("不支持的字幕语言: %s", strings.TrimSpace(lang))
+			}
+			combo = append(combo, normalized)
+		}
+		pref.combos = append(pref.combos, combo)
+	}
+
+	if !pref.any && len(pref.combos) == 0 {
+		return SubtitlePreference{}, fmt.Errorf("字幕语言偏好不能为空")
+	}
+	return pref, nil
+}
+
+// Accepts 检查条目的字幕语言组合（DetectSubLanguages的结果）是否符合偏好
+func (p SubtitlePreference) Accepts(subLang string) bool {
+	if p.any {
+		return true
+	}
+
+	have := make(map[string]bool)
+	for _, lang := range SplitSubLanguages(subLang) {
+		have[lang] = true
+	}
+
+	for _, combo := range p.combos {
+		matched := true
+		for _, lang := range combo {
+			if !have[lang] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSubLang 将配置中的语言代码规范为标准写法，不区分大小写
+func normalizeSubLang(lang string) (string, bool) {
+	lang = strings.TrimSpace(lang)
+	for code := range subLangOrder {
+		if strings.EqualFold(lang, code) {
+			return code, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"backend/utils"
+	"context"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxTorrentSize 下载种子文件的大小上限
+const maxTorrentSize = 10 << 20
+
+// torrentURLHashRE 种子URL中的infohash，如蜜柑的/Download/20240112/<infohash>.torrent
+var torrentURLHashRE = regexp.MustCompile(`(?i)(?:^|/)([0-9a-f]{40})\.torrent(?:$|\?)`)
+
+// TorrentFile 种子中的单个文件
+type TorrentFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// TorrentMeta 种子元数据
+type TorrentMeta struct {
+	InfoHash string        // 小写十六进制的v1 infohash
+	Name     string        // 种子名称
+	Size     int64         // 文件总大小，磁力链接未提供xl参数时为0
+	Files    []TorrentFile // 文件列表，磁力链接为空
+	Trackers []string      // Tracker地址，按种子中的顺序去重
+}
+
+// ParseTorrent 解码.torrent文件，计算infohash并提取文件列表和总大小
+func ParseTorrent(data []byte) (*TorrentMeta, error) {
+	value, rawInfo, err := decodeBencode(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("种子文件格式无效: 顶层不是字典")
+	}
+	info, ok := root["info"].(map[string]interface{})
+	if !ok || rawInfo == nil {
+		return nil, errors.New("种子文件格式无效: 缺少info字段")
+	}
+
+	sum := sha1.Sum(rawInfo)
+	meta := &TorrentMeta{
+		InfoHash: hex.EncodeToString(sum[:]),
+		Name:     utf8Field(info, "name"),
+		Trackers: torrentTrackers(root),
+	}
+
+	if length, ok := info["length"].(int64); ok {
+		// 单文件种子
+		meta.Files = []TorrentFile{{Path: meta.Name, Size: length}}
+		meta.Size = length
+		return meta, nil
+	}
+
+	files, ok := info["files"].([]interface{})
+	if !ok {
+		return nil, errors.New("种子文件格式无效: 缺少length或files字段")
+	}
+	for i, f := range files {
+		file, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("种子文件格式无效: 第%d个文件不是字典", i+1)
+		}
+		length, ok := file["length"].(int64)
+		if !ok || length < 0 {
+			return nil, fmt.Errorf("种子文件格式无效: 第%d个文件缺少length", i+1)
+		}
+
+		var parts []string
+		path, ok := file["path.utf-8"].([]interface{})
+		if !ok {
+			path, _ = file["path"].([]interface{})
+		}
+		for _, part := range path {
+			if s, ok := part.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		if len(parts) == 0 {
+			return nil, fmt.Errorf("种子文件格式无效: 第%d个文件缺少path", i+1)
+		}
+
+		meta.Files = append(meta.Files, TorrentFile{Path: strings.Join(parts, "/"), Size: length})
+		meta.Size += length
+	}
+	return meta, nil
+}
+
+// ParseMagnet 解析磁力链接中的infohash(xt)、名称(dn)和大小(xl)，支持十六进制和Base32编码的infohash
+func ParseMagnet(uri string) (*TorrentMeta, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, fmt.Errorf("磁力链接无效: %w", err)
+	}
+	if !strings.EqualFold(u.Scheme, "magnet") {
+		return nil, fmt.Errorf("不是磁力链接: %s", uri)
+	}
+
+	query := u.Query()
+	meta := &TorrentMeta{Name: query.Get("dn"), Trackers: query["tr"]}
+	for _, xt := range query["xt"] {
+		if !strings.HasPrefix(strings.ToLower(xt), "urn:btih:") {
+			continue
+		}
+		if meta.InfoHash, err = normalizeInfoHash(xt[len("urn:btih:"):]); err != nil {
+			return nil, err
+		}
+		break
+	}
+	if meta.InfoHash == "" {
+		return nil, errors.New("磁力链接缺少urn:btih")
+	}
+
+	if xl := query.Get("xl"); xl != "" {
+		if size, err := strconv.ParseInt(xl, 10, 64); err == nil && size > 0 {
+			meta.Size = size
+		}
+	}
+	return meta, nil
+}
+
+// BuildMagnet 根据infohash、名称、大小和Tracker生成磁力链接，名称和大小为空时省略
+func BuildMagnet(infoHash, name string, size int64, trackers []string) string {
+	var b strings.Builder
+	b.WriteString("magnet:?xt=urn:btih:")
+	b.WriteString(strings.ToLower(infoHash))
+	if name != "" {
+		b.WriteString("&dn=")
+		b.WriteString(url.QueryEscape(name))
+	}
+	if size > 0 {
+		b.WriteString("&xl=")
+		b.WriteString(strconv.FormatInt(size, 10))
+	}
+	for _, tracker := range trackers {
+		b.WriteString("&tr=")
+		b.WriteString(url.QueryEscape(tracker))
+	}
+	return b.String()
+}
+
+// torrentTrackers 合并种子中的announce和announce-list
+func torrentTrackers(root map[string]interface{}) []string {
+	var trackers []string
+	seen := make(map[string]bool)
+	add := func(value interface{}) {
+		if tracker, ok := value.(string); ok && tracker != "" && !seen[tracker] {
+			seen[tracker] = true
+			trackers = append(trackers, tracker)
+		}
+	}
+
+	add(root["announce"])
+	tiers, _ := root["announce-list"].([]interface{})
+	for _, tier := range tiers {
+		list, _ := tier.([]interface{})
+		for _, tracker := range list {
+			add(tracker)
+		}
+	}
+	return trackers
+}
+
+// normalizeInfoHash 将十六进制或Base32编码的infohash转为小写十六进制
+func normalizeInfoHash(hash string) (string, error) {
+	switch len(hash) {
+	case 40:
+		if _, err := hex.DecodeString(hash); err == nil {
+			return strings.ToLower(hash), nil
+		}
+	case 32:
+		if decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash)); err == nil {
+			return hex.EncodeToString(decoded), nil
+		}
+	}
+	return "", fmt.Errorf("infohash格式无效: %s", hash)
+}
+
+// InfoHashFromURL 从种子URL的文件名中提取infohash，未包含时返回空字符串
+func InfoHashFromURL(torrentURL string) string {
+	if m := torrentURLHashRE.FindStringSubmatch(torrentURL); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return ""
+}
+
+// FetchTorrentMeta 获取种子元数据：磁力链接直接解析，其他URL下载种子文件后解码
+func FetchTorrentMeta(torrentURL string) (*TorrentMeta, error) {
+	return FetchTorrentMetaContext(context.Background(), torrentURL)
+}
+
+// FetchTorrentMetaContext 与FetchTorrentMeta相同，ctx取消时中止下载
+func FetchTorrentMetaContext(ctx context.Context, torrentURL string) (*TorrentMeta, error) {
+	if strings.HasPrefix(strings.ToLower(torrentURL), "magnet:") {
+		return ParseMagnet(torrentURL)
+	}
+
+	resp, err := utils.OutboundGetContext(ctx, torrentURL)
+	if err != nil {
+		return nil, fmt.Errorf("下载种子失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载种子失败: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTorrentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取种子失败: %w", err)
+	}
+	if len(data) > maxTorrentSize {
+		return nil, fmt.Errorf("种子文件超过%dMB", maxTorrentSize>>20)
+	}
+	return ParseTorrent(data)
+}
+
+// utf8Field 读取字典中的字符串字段，优先使用key.utf-8
+func utf8Field(dict map[string]interface{}, key string) string {
+	if s, ok := dict[key+".utf-8"].(string); ok {
+		return s
+	}
+	s, _ := dict[key].(string)
+	return s
+}